@@ -10,31 +10,107 @@ import (
 )
 
 const (
-	cmdCommute              = "commuter"
-	commuteFromParam        = "from"
-	commuteFromUsage        = "The starting point of your commute, either a named location [ex. 'work'] or an address [ex. '123 Main St. Toronto, Canada']."
-	commuteToParam          = "to"
-	commuteToUsage          = "The destination of your commute, either a named location [ex. 'work'] or an address [ex. '123 Main St. Toronto, Canada']."
-	commuteFromCurrentParam = "from-current"
-	commuteFromCurrentUsage = "Sets your current location as the starting point of your commute. This uses Geolocation to attempt to determine your Latitude/Longitude based on IP Address. [Accuracy may vary]"
-	commuteToCurrentParam   = "to-current"
-	commuteToCurrentUsage   = "Sets your current location as the destination of your commute. This uses Geolocation to attempt to determine your Latitude/Longitude based on IP Address. [Accuracy may vary]"
-	commuteDriveParam       = "drive"
-	commuteDriveUsage       = "Adds 'driving' as a transit type [default]"
-	commuteWalkParam        = "walk"
-	commuteWalkUsage        = "Adds 'walking' as a transit type"
-	commuteBikeParam        = "bike"
-	commuteBikeUsage        = "Adds 'biking' as a transit type"
-	commuteTransitParam     = "transit"
-	commuteTransitUsage     = "Adds 'transit' as a transit type"
+	cmdCommute               = "commuter"
+	commuteFromParam         = "from"
+	commuteFromUsage         = "The starting point of your commute, either a named location [ex. 'work'] or an address [ex. '123 Main St. Toronto, Canada']."
+	commuteToParam           = "to"
+	commuteToUsage           = "The destination of your commute, either a named location [ex. 'work'] or an address [ex. '123 Main St. Toronto, Canada']."
+	commuteFromCurrentParam  = "from-current"
+	commuteFromCurrentUsage  = "Sets your current location as the starting point of your commute. This uses Geolocation to attempt to determine your Latitude/Longitude based on IP Address. [Accuracy may vary]"
+	commuteToCurrentParam    = "to-current"
+	commuteToCurrentUsage    = "Sets your current location as the destination of your commute. This uses Geolocation to attempt to determine your Latitude/Longitude based on IP Address. [Accuracy may vary]"
+	commuteDriveParam        = "drive"
+	commuteDriveUsage        = "Adds 'driving' as a transit type [default]"
+	commuteWalkParam         = "walk"
+	commuteWalkUsage         = "Adds 'walking' as a transit type"
+	commuteBikeParam         = "bike"
+	commuteBikeUsage         = "Adds 'biking' as a transit type"
+	commuteTransitParam      = "transit"
+	commuteTransitUsage      = "Adds 'transit' as a transit type"
+	commuteDepartAtParam     = "depart-at"
+	commuteDepartAtUsage     = "The time you will depart, either a time [ex. '08:15'], a day and time [ex. 'tomorrow 08:15', 'Mon 9am'], a date and time [ex. '2017-05-01 08:15'] or a relative time [ex. 'in 30m']. [default: now]"
+	commuteArriveByParam     = "arrive-by"
+	commuteArriveByUsage     = "The time you need to arrive by, in the same formats as -depart-at. Only supported by -transit, which becomes the default transit type."
+	commuteRangeParam        = "range"
+	commuteRangeUsage        = "Shows the optimistic and pessimistic driving durations based on traffic, in addition to the most likely duration."
+	commuteAvoidParam        = "avoid"
+	commuteAvoidUsage        = "A comma separated list of route features to avoid when driving [ex. 'tolls,highways,ferries'], or 'none'. Overrides the named location and default preferences."
+	commuteDistanceParam     = "distance"
+	commuteDistanceUsage     = "Shows the distance and average speed alongside each duration, or hides them with -distance=false when shown by default."
+	commuteUnitsParam        = "units"
+	commuteUnitsUsage        = "The unit system to display distances in, either 'metric' or 'imperial'. Overrides the default preference."
+	commuteTransitViaParam   = "transit-via"
+	commuteTransitViaUsage   = "A comma separated list of preferred modes of transit [ex. 'bus,subway,train,tram,rail'], or 'any'. Only supported by -transit, which becomes the default transit type."
+	commuteTransitPrefParam  = "transit-prefer"
+	commuteTransitPrefUsage  = "The preferred transit route, either 'less_walking', 'fewer_transfers' or 'none'. Only supported by -transit, which becomes the default transit type."
+	commuteAlternativesParam = "alternatives"
+	commuteAlternativesUsage = "Compares the recommended route with any alternative routes, ranked by duration."
+	commuteViaParam          = "via"
+	commuteViaUsage          = "A stop to make along the way, either a named location or an address. May be provided multiple times, and stops are visited in order."
+	commuteOptimizeParam     = "optimize"
+	commuteOptimizeUsage     = "Reorders the -via stops for the shortest total route."
+	commuteElevationParam    = "elevation"
+	commuteElevationUsage    = "Shows the climbing summary and elevation profile of bike and walk routes."
+	commuteOfflineParam      = "offline"
+	commuteOfflineUsage      = "Estimates durations without a network connection, from the straight line distance between named locations or coordinates. Offline estimates are also used when the routing provider can't be reached."
 
 	cmdAdd           = "add"
 	addNameParam     = "name"
 	addNameUsage     = "The name of the location you'd like to add [ex. 'work']. (required)\n"
 	addLocationParam = "location"
-	addLocationUsage = "The location to be added [ex. '123 Main St. Toronto, Canada']. (required)\n"
+	addLocationUsage = "The location to be added [ex. '123 Main St. Toronto, Canada']. If not provided, you'll be prompted to search for one.\n"
+	addAvoidParam    = "avoid"
+	addAvoidUsage    = "A comma separated list of route features to avoid when driving to or from this location [ex. 'tolls,highways,ferries'], or 'none'. Overrides the default preference.\n"
 
 	cmdList = "list"
+
+	cmdSearch       = "search"
+	searchNameParam = "name"
+	searchNameUsage = "The name to add the chosen place as [ex. 'work']. If not provided, the matching places are only listed.\n"
+
+	cmdDirections         = "directions"
+	directionsExportParam = "export"
+	directionsExportUsage = "A .gpx, .geojson or .kml file to write the route to, including waypoints for the origin, destination and any stops.\n"
+
+	cmdWhereAmI = "whereami"
+
+	cmdLeave         = "leave"
+	leaveArriveParam = "arrive"
+	leaveArriveUsage = "The time you need to arrive by, in the time zone of the destination [ex. '09:30', 'tomorrow 9am']. (required)\n"
+	leaveBufferParam = "buffer"
+	leaveBufferUsage = "Extra time to arrive early by [ex. '10m'].\n"
+
+	cmdBest           = "best"
+	bestBetweenParam  = "between"
+	bestBetweenUsage  = "The window of departure times to compare [ex. '07:00-10:00', '7am-9:30am']. (required)\n"
+	bestStepParam     = "step"
+	bestStepUsage     = "The time between each departure compared [ex. '10m', '30m']. Each departure requires a request, so larger steps use fewer.\n"
+	bestReliableParam = "reliable"
+	bestReliableUsage = "Recommends the departure with the shortest pessimistic duration in traffic, rather than the shortest likely duration. Only supported by -drive.\n"
+
+	cmdMatrix       = "matrix"
+	matrixFromParam = "from"
+	matrixFromUsage = "A starting point, either a named location or an address. May be provided multiple times.\n"
+	matrixToParam   = "to"
+	matrixToUsage   = "A destination, either a named location or an address. May be provided multiple times.\n"
+	matrixAllParam  = "all"
+	matrixAllUsage  = "Uses every named location as a starting point and destination, unless -from or -to are provided.\n"
+
+	cmdDefaults              = "defaults"
+	defaultsAvoidParam       = "avoid"
+	defaultsAvoidUsage       = "The default comma separated list of route features to avoid when driving [ex. 'tolls,highways,ferries'], or 'none'.\n"
+	defaultsDistanceParam    = "distance"
+	defaultsDistanceUsage    = "Whether to show the distance and average speed alongside each duration by default [ex. '-distance=false'].\n"
+	defaultsUnitsParam       = "units"
+	defaultsUnitsUsage       = "The default unit system to display distances in, either 'metric' or 'imperial'.\n"
+	defaultsTransitViaParam  = "transit-via"
+	defaultsTransitViaUsage  = "The default comma separated list of preferred modes of transit [ex. 'bus,subway,train,tram,rail'], or 'any'.\n"
+	defaultsTransitPrefParam = "transit-prefer"
+	defaultsTransitPrefUsage = "The default preferred transit route, either 'less_walking', 'fewer_transfers' or 'none'.\n"
+	defaultsProviderParam    = "provider"
+	defaultsProviderUsage    = "The routing provider used to estimate durations, either 'google', 'osrm', 'mapbox', 'osm' or 'offline'.\n"
+	defaultsProvSettingParam = "provider-setting"
+	defaultsProvSettingUsage = "A setting of the routing provider as a key and value [ex. 'url=http://localhost:5000'], or a key with no value to remove it. May be provided multiple times.\n"
 )
 
 // Stdout provides an output mechanism to notify the user via stdout.
@@ -54,6 +130,14 @@ func (s Stdout) Indicate(msg string, args ...interface{}) {
 	fmt.Fprintf(s, "%v\n", fmt.Sprintf(msg, args...))
 }
 
+// Files provides the ability to create files on the local file system.
+type Files struct{}
+
+// Create creates or truncates the named file for writing.
+func (Files) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
 // Stdin provides an input mechanism for the user via the command line.
 type Stdin struct {
 	*bufio.Scanner
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+)
+
+// stringsFlag is a flag.Value that can be provided multiple times,
+// collecting each value in order.
+type stringsFlag []string
+
+// String returns the values of the flag as a comma separated list.
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set appends a value to the flag.
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// boolPtrFlag is a boolean flag.Value that's only set when the flag is provided,
+// so that an explicit false can be told apart from the flag being omitted.
+type boolPtrFlag struct {
+	value **bool
+}
+
+// String returns the value of the flag, which is false when it wasn't provided.
+func (b boolPtrFlag) String() string {
+	if b.value == nil || *b.value == nil {
+		return "false"
+	}
+	return strconv.FormatBool(**b.value)
+}
+
+// Set parses and sets the value of the flag.
+func (b boolPtrFlag) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	*b.value = &v
+	return nil
+}
+
+// IsBoolFlag allows the flag to be provided without a value, meaning true.
+func (b boolPtrFlag) IsBoolFlag() bool {
+	return true
+}
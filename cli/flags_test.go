@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestStringsFlag(t *testing.T) {
+	tests := []struct {
+		args   []string
+		expect []string
+	}{
+		{[]string{}, nil},
+		{[]string{"-via", "daycare"}, []string{"daycare"}},
+		{[]string{"-via", "daycare", "-via", "123 Main St, Toronto"}, []string{"daycare", "123 Main St, Toronto"}},
+	}
+
+	for idx, tt := range tests {
+		var s stringsFlag
+		f := flag.NewFlagSet("test", flag.ContinueOnError)
+		f.Var(&s, "via", "")
+		if err := f.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if !testStringsEq(s, tt.expect) {
+			t.Fatalf("[#%v] Unexpected values, expected=%v, got=%v", idx, tt.expect, s)
+		}
+	}
+}
+
+func TestBoolPtrFlag(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		args   []string
+		expect *bool
+	}{
+		{[]string{}, nil},
+		{[]string{"-distance"}, &on},
+		{[]string{"-distance=true"}, &on},
+		{[]string{"-distance=false"}, &off},
+	}
+
+	for idx, tt := range tests {
+		var b *bool
+		f := flag.NewFlagSet("test", flag.ContinueOnError)
+		f.Var(boolPtrFlag{&b}, "distance", "")
+		if err := f.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if (b == nil) != (tt.expect == nil) || (b != nil && *b != *tt.expect) {
+			t.Fatalf("[#%v] Unexpected value, expected=%v, got=%v", idx, tt.expect, b)
+		}
+	}
+}
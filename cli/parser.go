@@ -1,15 +1,33 @@
 package cli
 
 import (
+	"errors"
 	"flag"
+	"net/http"
+	"strings"
 
 	"github.com/KyleBanks/commuter/cmd"
 	"github.com/KyleBanks/commuter/pkg/geo"
 )
 
+var (
+	// ErrRecordAndReplay is returned when attempting to record and replay a cassette at once.
+	ErrRecordAndReplay = errors.New("cannot record and replay a cassette at the same time")
+	// ErrAPIKeyMissing is returned when the Google Maps Provider is used without an API key.
+	ErrAPIKeyMissing = errors.New("missing Google Maps API key, run 'commuter' to configure one, or use -offline or another provider")
+)
+
 // ArgParser parses input arguments from the command line.
 type ArgParser struct {
 	Args []string
+
+	// Record is the path of a cassette to record each request made to, and
+	// Replay is the path of a cassette to serve responses from instead of
+	// making requests.
+	Record string
+	Replay string
+
+	client *http.Client
 }
 
 // NewArgParser initializes and returns an ArgParser.
@@ -28,12 +46,26 @@ func (a *ArgParser) Parse(conf *cmd.Configuration, s cmd.StorageProvider) (cmd.R
 
 	switch a.Args[0] {
 	case cmdAdd:
-		return a.parseAddCmd(s, a.Args[1:])
+		return a.parseAddCmd(conf, s, a.Args[1:])
 	case cmdList:
 		return a.parseListCmd(s, a.Args[1:])
+	case cmdDefaults:
+		return a.parseDefaultsCmd(s, a.Args[1:])
+	case cmdDirections:
+		return a.parseDirectionsCmd(conf, s, a.Args[1:])
+	case cmdMatrix:
+		return a.parseMatrixCmd(conf, a.Args[1:])
+	case cmdWhereAmI:
+		return a.parseWhereAmICmd(conf, a.Args[1:])
+	case cmdSearch:
+		return a.parseSearchCmd(conf, s, a.Args[1:])
+	case cmdBest:
+		return a.parseBestCmd(conf, s, a.Args[1:])
+	case cmdLeave:
+		return a.parseLeaveCmd(conf, s, a.Args[1:])
 	}
 
-	return a.parseCommuteCmd(conf, a.Args)
+	return a.parseCommuteCmd(conf, s, a.Args)
 }
 
 // parseConfigureCmd parses and returns a ConfigureCmd.
@@ -45,43 +77,381 @@ func (a *ArgParser) parseConfigureCmd(s cmd.StorageProvider) (*cmd.ConfigureCmd,
 }
 
 // parseCommuteCmd parses and returns a CommuteCmd from user supplied flags.
-func (a *ArgParser) parseCommuteCmd(conf *cmd.Configuration, args []string) (*cmd.CommuteCmd, error) {
-	r, err := geo.NewRouter(conf.APIKey)
+func (a *ArgParser) parseCommuteCmd(conf *cmd.Configuration, s cmd.StorageProvider, args []string) (*cmd.CommuteCmd, error) {
+	c, err := a.commuteCmd(conf, s)
 	if err != nil {
 		return nil, err
 	}
 
-	c := cmd.CommuteCmd{Durationer: r, Locator: r}
-
 	f := flag.NewFlagSet(cmdCommute, flag.ExitOnError)
+	a.commuteFlags(f, &c)
+	f.BoolVar(&c.Range, commuteRangeParam, false, commuteRangeUsage)
+	f.BoolVar(&c.Elevation, commuteElevationParam, false, commuteElevationUsage)
+	f.BoolVar(&c.Offline, commuteOfflineParam, false, commuteOfflineUsage)
+	f.Parse(args)
+
+	if c.Durationer == nil && !c.Offline {
+		return nil, ErrAPIKeyMissing
+	}
+	a.defaultMode(&c)
+
+	return &c, nil
+}
+
+// parseDirectionsCmd parses and returns a DirectionsCmd from user supplied flags.
+func (a *ArgParser) parseDirectionsCmd(conf *cmd.Configuration, s cmd.StorageProvider, args []string) (*cmd.DirectionsCmd, error) {
+	cc, err := a.commuteCmd(conf, s)
+	if err != nil {
+		return nil, err
+	} else if cc.Durationer == nil {
+		return nil, ErrAPIKeyMissing
+	}
+
+	c := cmd.DirectionsCmd{
+		CommuteCmd: cc,
+		Files:      Files{},
+	}
+
+	f := flag.NewFlagSet(cmdDirections, flag.ExitOnError)
+	a.commuteFlags(f, &c.CommuteCmd)
+	f.StringVar(&c.Export, directionsExportParam, "", directionsExportUsage)
+	f.Parse(args)
+
+	a.defaultMode(&c.CommuteCmd)
+
+	return &c, nil
+}
+
+// parseBestCmd parses and returns a BestCmd from user supplied flags.
+func (a *ArgParser) parseBestCmd(conf *cmd.Configuration, s cmd.StorageProvider, args []string) (*cmd.BestCmd, error) {
+	cc, err := a.commuteCmd(conf, s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := cmd.BestCmd{CommuteCmd: cc}
+
+	f := flag.NewFlagSet(cmdBest, flag.ExitOnError)
+	a.locationFlags(f, &c.CommuteCmd)
+	a.optionFlags(f, &c.CommuteCmd)
+	f.StringVar(&c.Between, bestBetweenParam, "", bestBetweenUsage)
+	f.StringVar(&c.Step, bestStepParam, cmd.DefaultBestStep, bestStepUsage)
+	f.BoolVar(&c.Reliable, bestReliableParam, false, bestReliableUsage)
+	f.BoolVar(&c.Offline, commuteOfflineParam, false, commuteOfflineUsage)
+	f.Parse(args)
+
+	if c.Durationer == nil && !c.Offline {
+		return nil, ErrAPIKeyMissing
+	}
+	a.defaultMode(&c.CommuteCmd)
+
+	return &c, nil
+}
+
+// parseLeaveCmd parses and returns a LeaveCmd from user supplied flags.
+func (a *ArgParser) parseLeaveCmd(conf *cmd.Configuration, s cmd.StorageProvider, args []string) (*cmd.LeaveCmd, error) {
+	cc, err := a.commuteCmd(conf, s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := cmd.LeaveCmd{CommuteCmd: cc}
+
+	f := flag.NewFlagSet(cmdLeave, flag.ExitOnError)
+	a.locationFlags(f, &c.CommuteCmd)
+	a.optionFlags(f, &c.CommuteCmd)
+	f.StringVar(&c.Arrive, leaveArriveParam, "", leaveArriveUsage)
+	f.StringVar(&c.Buffer, leaveBufferParam, "", leaveBufferUsage)
+	f.BoolVar(&c.Offline, commuteOfflineParam, false, commuteOfflineUsage)
+	f.Parse(args)
+
+	if c.Durationer == nil && !c.Offline {
+		return nil, ErrAPIKeyMissing
+	}
+	a.defaultMode(&c.CommuteCmd)
+
+	return &c, nil
+}
+
+// parseWhereAmICmd parses and returns a WhereAmICmd.
+func (a *ArgParser) parseWhereAmICmd(conf *cmd.Configuration, args []string) (*cmd.WhereAmICmd, error) {
+	if len(conf.APIKey) == 0 {
+		return nil, ErrAPIKeyMissing
+	}
+
+	r, err := a.router(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cmd.WhereAmICmd{Locator: r, ReverseGeocoder: r}, nil
+}
+
+// parseMatrixCmd parses and returns a MatrixCmd from user supplied flags.
+func (a *ArgParser) parseMatrixCmd(conf *cmd.Configuration, args []string) (*cmd.MatrixCmd, error) {
+	cc, err := a.commuteCmd(conf, nil)
+	if err != nil {
+		return nil, err
+	} else if cc.Durationer == nil {
+		return nil, ErrAPIKeyMissing
+	}
+
+	c := cmd.MatrixCmd{
+		CommuteCmd: cmd.CommuteCmd{Durationer: cc.Durationer, Geocoder: cc.Geocoder},
+		Matrixer:   cc.Matrixer,
+	}
+
+	f := flag.NewFlagSet(cmdMatrix, flag.ExitOnError)
+	f.Var((*stringsFlag)(&c.Origins), matrixFromParam, matrixFromUsage)
+	f.Var((*stringsFlag)(&c.Destinations), matrixToParam, matrixToUsage)
+	f.BoolVar(&c.All, matrixAllParam, false, matrixAllUsage)
+	a.optionFlags(f, &c.CommuteCmd)
+	f.Parse(args)
+
+	a.defaultMode(&c.CommuteCmd)
+
+	return &c, nil
+}
+
+// commuteCmd returns a CommuteCmd that estimates durations, and matrices when supported,
+// with the routing Provider selected by the Configuration. The Google Maps Router is used
+// to look up addresses and time zones, and for directions and places when the Provider
+// supports them.
+//
+// The Router is only created when an API key is configured, so that other Providers and
+// offline estimates can be used without one. Without it, the dependencies it provides are
+// left nil, as is the Durationer when the Provider is Google Maps.
+func (a *ArgParser) commuteCmd(conf *cmd.Configuration, s cmd.StorageProvider) (cmd.CommuteCmd, error) {
+	c := cmd.CommuteCmd{
+		Estimator: a.estimator(conf),
+		Store:     s,
+	}
+
+	var r *geo.Router
+	if len(conf.APIKey) > 0 {
+		var err error
+		if r, err = a.router(conf); err != nil {
+			return c, err
+		}
+
+		c.ReverseGeocoder = r
+		c.Geocoder = r
+		c.TimeZoner = r
+	}
+
+	p, l, err := a.provider(conf, r)
+	if err != nil {
+		return c, err
+	}
+	if l != nil {
+		c.Locator = l
+	}
+	if p == nil {
+		return c, nil
+	}
+	c.Durationer = p
+
+	if caps := p.Capabilities(); r != nil {
+		if caps.Directions {
+			c.Director = r
+			c.Elevator = r
+		}
+		if caps.Places {
+			c.NearbySearcher = r
+		}
+	}
+	if m, ok := p.(cmd.Matrixer); ok && p.Capabilities().Matrix {
+		c.Matrixer = m
+	}
+
+	return c, nil
+}
+
+// router returns the Google Maps Router, recording or replaying a cassette
+// when requested.
+func (a *ArgParser) router(conf *cmd.Configuration) (*geo.Router, error) {
+	hc, err := a.httpClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return geo.NewRouterWithClient(conf.APIKey, hc)
+}
+
+// httpClient returns the http.Client that records or replays a cassette, or nil to
+// make requests with the default client. The client is shared so that every request
+// is written to the same cassette.
+func (a *ArgParser) httpClient(conf *cmd.Configuration) (*http.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+
+	switch {
+	case len(a.Record) > 0 && len(a.Replay) > 0:
+		return nil, ErrRecordAndReplay
+	case len(a.Record) > 0:
+		a.client = &http.Client{Transport: geo.NewRecorder(a.Record, nil, conf.APIKey)}
+	case len(a.Replay) > 0:
+		rep, err := geo.NewReplayer(a.Replay)
+		if err != nil {
+			return nil, err
+		}
+		a.client = &http.Client{Transport: rep}
+	}
+
+	return a.client, nil
+}
+
+// provider returns the routing Provider selected by the Configuration, and the Locator
+// to determine the current location with, which is the Google Maps Router when the
+// Provider can't. Transit is estimated from a GTFS feed instead, when one is configured.
+//
+// When the Router is nil because no API key is configured, neither is returned for the
+// Google Maps Provider, and no Locator is returned for a Provider that can't locate.
+func (a *ArgParser) provider(conf *cmd.Configuration, r *geo.Router) (geo.Provider, cmd.Locator, error) {
+	if r == nil && (len(conf.Provider) == 0 || conf.Provider == geo.GoogleProvider) {
+		return nil, nil, nil
+	}
+
+	hc, err := a.httpClient(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := geo.NewProvider(conf.Provider, geo.ProviderConfig{
+		APIKey:   conf.APIKey,
+		Settings: conf.ProviderSettings,
+		Client:   hc,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if feed := conf.ProviderSettings[geo.GTFSFeedSetting]; len(feed) > 0 {
+		g, err := geo.NewGTFS(feed)
+		if err != nil {
+			return nil, nil, err
+		}
+		p = geo.WithTransit(p, g)
+	}
+
+	if !p.Capabilities().Location {
+		if r == nil {
+			return p, nil, nil
+		}
+		return p, r, nil
+	}
+	return p, p, nil
+}
+
+// estimator returns the offline Estimator, configured by the provider settings, or nil
+// when the settings are invalid so that only offline estimates are unavailable.
+func (a *ArgParser) estimator(conf *cmd.Configuration) cmd.Durationer {
+	o, err := geo.NewProvider(geo.OfflineProvider, geo.ProviderConfig{Settings: conf.ProviderSettings})
+	if err != nil {
+		return nil
+	}
+	return o
+}
+
+// commuteFlags registers the flags shared by the commute and directions commands.
+func (a *ArgParser) commuteFlags(f *flag.FlagSet, c *cmd.CommuteCmd) {
+	a.locationFlags(f, c)
+	a.optionFlags(f, c)
+
+	f.Var(boolPtrFlag{&c.Distance}, commuteDistanceParam, commuteDistanceUsage)
+	f.BoolVar(&c.Alternatives, commuteAlternativesParam, false, commuteAlternativesUsage)
+	f.Var((*stringsFlag)(&c.Via), commuteViaParam, commuteViaUsage)
+	f.BoolVar(&c.Optimize, commuteOptimizeParam, false, commuteOptimizeUsage)
+}
+
+// locationFlags registers the origin and destination flags shared by the
+// commute, directions, best and leave commands.
+func (a *ArgParser) locationFlags(f *flag.FlagSet, c *cmd.CommuteCmd) {
 	f.StringVar(&c.From, commuteFromParam, cmd.DefaultLocationAlias, commuteFromUsage)
 	f.BoolVar(&c.FromCurrent, commuteFromCurrentParam, false, commuteFromCurrentUsage)
 	f.StringVar(&c.To, commuteToParam, cmd.DefaultLocationAlias, commuteToUsage)
 	f.BoolVar(&c.ToCurrent, commuteToCurrentParam, false, commuteToCurrentUsage)
+}
 
+// optionFlags registers the commute method and option flags shared by the
+// commute, directions and matrix commands.
+func (a *ArgParser) optionFlags(f *flag.FlagSet, c *cmd.CommuteCmd) {
 	f.BoolVar(&c.Drive, commuteDriveParam, false, commuteDriveUsage)
 	f.BoolVar(&c.Walk, commuteWalkParam, false, commuteWalkUsage)
 	f.BoolVar(&c.Bike, commuteBikeParam, false, commuteBikeUsage)
 	f.BoolVar(&c.Transit, commuteTransitParam, false, commuteTransitUsage)
-	f.Parse(args)
 
-	// Only default drive to true when no other methods of transport are selected.
-	if !c.Walk && !c.Bike && !c.Transit {
-		c.Drive = true
+	f.StringVar(&c.DepartAt, commuteDepartAtParam, "", commuteDepartAtUsage)
+	f.StringVar(&c.ArriveBy, commuteArriveByParam, "", commuteArriveByUsage)
+	f.StringVar(&c.Avoid, commuteAvoidParam, "", commuteAvoidUsage)
+	f.StringVar(&c.Units, commuteUnitsParam, "", commuteUnitsUsage)
+	f.StringVar(&c.TransitVia, commuteTransitViaParam, "", commuteTransitViaUsage)
+	f.StringVar(&c.TransitPrefer, commuteTransitPrefParam, "", commuteTransitPrefUsage)
+}
+
+// defaultMode defaults the method of transport when none are selected, using transit
+// when an arrival time or transit options are provided as it's the only method
+// that supports them.
+func (a *ArgParser) defaultMode(c *cmd.CommuteCmd) {
+	if c.Drive || c.Walk || c.Bike || c.Transit {
+		return
 	}
 
-	return &c, nil
+	if len(c.ArriveBy) > 0 || len(c.TransitVia) > 0 || len(c.TransitPrefer) > 0 {
+		c.Transit = true
+	} else {
+		c.Drive = true
+	}
 }
 
 // parseAddCmd parses and returns an AddCmd from user supplied flags.
-func (a *ArgParser) parseAddCmd(s cmd.StorageProvider, args []string) (*cmd.AddCmd, error) {
-	c := cmd.AddCmd{Store: s}
+//
+// Without an API key, locations can't be looked up, so only "lat,lng" locations can
+// be added.
+func (a *ArgParser) parseAddCmd(conf *cmd.Configuration, s cmd.StorageProvider, args []string) (*cmd.AddCmd, error) {
+	c := cmd.AddCmd{Input: NewStdin(), Store: s}
+	if len(conf.APIKey) > 0 {
+		r, err := a.router(conf)
+		if err != nil {
+			return nil, err
+		}
+		c.Geocoder, c.Searcher = r, r
+	}
 
 	f := flag.NewFlagSet(cmdAdd, flag.ExitOnError)
 	f.StringVar(&c.Name, addNameParam, "", addNameUsage)
 	f.StringVar(&c.Value, addLocationParam, "", addLocationUsage)
+	f.StringVar(&c.Avoid, addAvoidParam, "", addAvoidUsage)
+	f.Parse(args)
+
+	if c.Searcher == nil && len(c.Value) == 0 {
+		return nil, ErrAPIKeyMissing
+	}
+
+	return &c, nil
+}
+
+// parseSearchCmd parses and returns a SearchCmd from user supplied flags, using
+// the remaining arguments as the query.
+func (a *ArgParser) parseSearchCmd(conf *cmd.Configuration, s cmd.StorageProvider, args []string) (*cmd.SearchCmd, error) {
+	if len(conf.APIKey) == 0 {
+		return nil, ErrAPIKeyMissing
+	}
+
+	r, err := a.router(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	c := cmd.SearchCmd{Searcher: r, Input: NewStdin(), Store: s}
+
+	f := flag.NewFlagSet(cmdSearch, flag.ExitOnError)
+	f.StringVar(&c.Name, searchNameParam, "", searchNameUsage)
 	f.Parse(args)
 
+	c.Query = strings.Join(f.Args(), " ")
+
 	return &c, nil
 }
 
@@ -89,3 +459,27 @@ func (a *ArgParser) parseAddCmd(s cmd.StorageProvider, args []string) (*cmd.AddC
 func (a *ArgParser) parseListCmd(s cmd.StorageProvider, args []string) (*cmd.ListCmd, error) {
 	return &cmd.ListCmd{}, nil
 }
+
+// parseDefaultsCmd parses and returns a DefaultsCmd from user supplied flags.
+func (a *ArgParser) parseDefaultsCmd(s cmd.StorageProvider, args []string) (*cmd.DefaultsCmd, error) {
+	c := cmd.DefaultsCmd{Store: s}
+
+	f := flag.NewFlagSet(cmdDefaults, flag.ExitOnError)
+	f.StringVar(&c.Avoid, defaultsAvoidParam, "", defaultsAvoidUsage)
+	distance := f.Bool(defaultsDistanceParam, false, defaultsDistanceUsage)
+	f.StringVar(&c.Units, defaultsUnitsParam, "", defaultsUnitsUsage)
+	f.StringVar(&c.TransitVia, defaultsTransitViaParam, "", defaultsTransitViaUsage)
+	f.StringVar(&c.TransitPrefer, defaultsTransitPrefParam, "", defaultsTransitPrefUsage)
+	f.StringVar(&c.Provider, defaultsProviderParam, "", defaultsProviderUsage)
+	f.Var((*stringsFlag)(&c.ProviderSettings), defaultsProvSettingParam, defaultsProvSettingUsage)
+	f.Parse(args)
+
+	// Only update boolean defaults that were explicitly provided.
+	f.Visit(func(fl *flag.Flag) {
+		if fl.Name == defaultsDistanceParam {
+			c.Distance = distance
+		}
+	})
+
+	return &c, nil
+}
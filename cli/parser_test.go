@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/KyleBanks/commuter/cmd"
+	"github.com/KyleBanks/commuter/pkg/geo"
 )
 
 type MockStorageProvider struct {
@@ -63,10 +64,35 @@ func TestArgParser_Parse(t *testing.T) {
 		{[]string{"add", "-name", "work"}, &conf, &cmd.AddCmd{}},
 		{[]string{"add", "-name", "work", "-location", "123 Sample Lane"}, &conf, &cmd.AddCmd{}},
 
+		// Search command
+		{[]string{"search", "cn", "tower"}, &conf, &cmd.SearchCmd{}},
+		{[]string{"search", "-name", "tower", "cn tower"}, &conf, &cmd.SearchCmd{}},
+
+		// Best command
+		{[]string{"best", "-to", "work", "-between", "07:00-10:00"}, &conf, &cmd.BestCmd{}},
+
+		// Leave command
+		{[]string{"leave", "-to", "client", "-arrive", "09:30"}, &conf, &cmd.LeaveCmd{}},
+
 		// List command
 		{[]string{"list"}, &conf, &cmd.ListCmd{}},
 		{[]string{"list", "-arg"}, &conf, &cmd.ListCmd{}},
 
+		// Defaults command
+		{[]string{"defaults"}, &conf, &cmd.DefaultsCmd{}},
+		{[]string{"defaults", "-avoid", "none"}, &conf, &cmd.DefaultsCmd{}},
+
+		// WhereAmI command
+		{[]string{"whereami"}, &conf, &cmd.WhereAmICmd{}},
+
+		// Matrix command
+		{[]string{"matrix", "-all"}, &conf, &cmd.MatrixCmd{}},
+		{[]string{"matrix", "-from", "home", "-to", "work", "-to", "gym"}, &conf, &cmd.MatrixCmd{}},
+
+		// Directions command
+		{[]string{"directions"}, &conf, &cmd.DirectionsCmd{}},
+		{[]string{"directions", "-to", "work", "-transit"}, &conf, &cmd.DirectionsCmd{}},
+
 		// Empty args should prompt a ConfigureCommand
 		{[]string{}, &conf, &cmd.ConfigureCmd{}},
 
@@ -112,7 +138,7 @@ func TestArgParser_parseCommuteCmd(t *testing.T) {
 	var a ArgParser
 
 	// No API key should return an error
-	_, err := a.parseCommuteCmd(&conf, []string{"-to", "work"})
+	_, err := a.parseCommuteCmd(&conf, nil, []string{"-to", "work"})
 	if err == nil {
 		t.Fatalf("Expected error for empty API key")
 	}
@@ -136,7 +162,7 @@ func TestArgParser_parseCommuteCmd(t *testing.T) {
 	}
 
 	for idx, tt := range tests {
-		r, err := a.parseCommuteCmd(&conf, tt.args)
+		r, err := a.parseCommuteCmd(&conf, nil, tt.args)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -151,8 +177,18 @@ func TestArgParser_parseCommuteCmd(t *testing.T) {
 			t.Fatalf("[%v] Unexpected 'ToCurrent' parsed, expected=%v, got=%v", idx, tt.expected.ToCurrent, r.ToCurrent)
 		} else if r.Durationer == nil {
 			t.Fatalf("[%v] Unexpected nil Durationer", idx)
+		} else if r.Director == nil {
+			t.Fatalf("[%v] Unexpected nil Director", idx)
+		} else if r.ReverseGeocoder == nil {
+			t.Fatalf("[%v] Unexpected nil ReverseGeocoder", idx)
 		} else if r.Locator == nil {
 			t.Fatalf("[%v] Unexpected nil Locator", idx)
+		} else if r.Geocoder == nil {
+			t.Fatalf("[%v] Unexpected nil Geocoder", idx)
+		} else if r.TimeZoner == nil {
+			t.Fatalf("[%v] Unexpected nil TimeZoner", idx)
+		} else if r.NearbySearcher == nil || r.Matrixer == nil {
+			t.Fatalf("[%v] Unexpected nil NearbySearcher or Matrixer", idx)
 		} else if r.Drive == false {
 			t.Fatalf("[%v] Unexpected Drive, expected=true, got=false", idx)
 		} else if r.Bike == true || r.Walk == true || r.Transit == true {
@@ -172,10 +208,18 @@ func TestArgParser_parseCommuteCmd(t *testing.T) {
 		{[]string{"-transit"}, cmd.CommuteCmd{Transit: true}},
 		{[]string{"-drive", "-walk"}, cmd.CommuteCmd{Drive: true, Walk: true}},
 		{[]string{"-drive", "-walk", "-bike", "-transit"}, cmd.CommuteCmd{Drive: true, Walk: true, Bike: true, Transit: true}},
+		{[]string{"-depart-at", "08:15"}, cmd.CommuteCmd{Drive: true}},
+		{[]string{"-arrive-by", "08:15"}, cmd.CommuteCmd{Transit: true}},
+		{[]string{"-arrive-by", "08:15", "-drive"}, cmd.CommuteCmd{Drive: true}},
+		{[]string{"-range"}, cmd.CommuteCmd{Drive: true}},
+		{[]string{"-transit-via", "rail"}, cmd.CommuteCmd{Transit: true}},
+		{[]string{"-transit-prefer", "less_walking"}, cmd.CommuteCmd{Transit: true}},
+		{[]string{"-transit-via", "rail", "-walk"}, cmd.CommuteCmd{Walk: true}},
+		{[]string{"-bike", "-elevation"}, cmd.CommuteCmd{Bike: true, Elevation: true}},
 	}
 
 	for idx, tt := range mTests {
-		r, err := a.parseCommuteCmd(&conf, tt.args)
+		r, err := a.parseCommuteCmd(&conf, nil, tt.args)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -188,6 +232,181 @@ func TestArgParser_parseCommuteCmd(t *testing.T) {
 			t.Fatalf("[%v] Unexpected 'Bike' parsed, expected=%v, got=%v", idx, tt.expected.Bike, r.Bike)
 		} else if tt.expected.Transit != r.Transit {
 			t.Fatalf("[%v] Unexpected 'Transit' parsed, expected=%v, got=%v", idx, tt.expected.Transit, r.Transit)
+		} else if tt.expected.Elevation != r.Elevation {
+			t.Fatalf("[%v] Unexpected 'Elevation' parsed, expected=%v, got=%v", idx, tt.expected.Elevation, r.Elevation)
+		} else if r.Elevator == nil {
+			t.Fatalf("[%v] Unexpected nil Elevator", idx)
+		}
+	}
+}
+
+func TestArgParser_parseCommuteCmd_schedule(t *testing.T) {
+	conf := cmd.Configuration{APIKey: "example"}
+	var a ArgParser
+
+	tests := []struct {
+		args     []string
+		departAt string
+		arriveBy string
+		rng      bool
+		avoid    string
+		distance bool
+		units    string
+	}{
+		{[]string{"-to", "work"}, "", "", false, "", false, ""},
+		{[]string{"-to", "work", "-depart-at", "tomorrow 08:15"}, "tomorrow 08:15", "", false, "", false, ""},
+		{[]string{"-to", "work", "-arrive-by", "Mon 09:00"}, "", "Mon 09:00", false, "", false, ""},
+		{[]string{"-depart-at", "in 30m", "-arrive-by", "9am"}, "in 30m", "9am", false, "", false, ""},
+		{[]string{"-depart-at", "in 30m", "-range"}, "in 30m", "", true, "", false, ""},
+		{[]string{"-avoid", "tolls,highways"}, "", "", false, "tolls,highways", false, ""},
+		{[]string{"-distance"}, "", "", false, "", true, ""},
+		{[]string{"-distance", "-units", "imperial"}, "", "", false, "", true, "imperial"},
+	}
+
+	for idx, tt := range tests {
+		r, err := a.parseCommuteCmd(&conf, nil, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.DepartAt != tt.departAt {
+			t.Fatalf("[%v] Unexpected 'DepartAt' parsed, expected=%v, got=%v", idx, tt.departAt, r.DepartAt)
+		} else if r.ArriveBy != tt.arriveBy {
+			t.Fatalf("[%v] Unexpected 'ArriveBy' parsed, expected=%v, got=%v", idx, tt.arriveBy, r.ArriveBy)
+		} else if r.Range != tt.rng {
+			t.Fatalf("[%v] Unexpected 'Range' parsed, expected=%v, got=%v", idx, tt.rng, r.Range)
+		} else if r.Avoid != tt.avoid {
+			t.Fatalf("[%v] Unexpected 'Avoid' parsed, expected=%v, got=%v", idx, tt.avoid, r.Avoid)
+		} else if (r.Distance != nil && *r.Distance) != tt.distance {
+			t.Fatalf("[%v] Unexpected 'Distance' parsed, expected=%v, got=%v", idx, tt.distance, r.Distance)
+		} else if r.Units != tt.units {
+			t.Fatalf("[%v] Unexpected 'Units' parsed, expected=%v, got=%v", idx, tt.units, r.Units)
+		}
+	}
+}
+
+func TestArgParser_parseDirectionsCmd(t *testing.T) {
+	var conf cmd.Configuration
+	var a ArgParser
+
+	// No API key should return an error
+	if _, err := a.parseDirectionsCmd(&conf, nil, []string{"-to", "work"}); err == nil {
+		t.Fatalf("Expected error for empty API key")
+	}
+
+	conf.APIKey = "example"
+
+	tests := []struct {
+		args     []string
+		expected cmd.CommuteCmd
+	}{
+		{[]string{}, cmd.CommuteCmd{From: "default", To: "default", Drive: true}},
+		{[]string{"-from", "home", "-to", "work"}, cmd.CommuteCmd{From: "home", To: "work", Drive: true}},
+		{[]string{"-from-current", "-to", "work", "-walk"}, cmd.CommuteCmd{From: "default", FromCurrent: true, To: "work", Walk: true}},
+		{[]string{"-to", "work", "-arrive-by", "9am"}, cmd.CommuteCmd{From: "default", To: "work", ArriveBy: "9am", Transit: true}},
+		{[]string{"-to", "work", "-avoid", "highways", "-units", "imperial"}, cmd.CommuteCmd{From: "default", To: "work", Avoid: "highways", Units: "imperial", Drive: true}},
+		{[]string{"-to", "work", "-alternatives"}, cmd.CommuteCmd{From: "default", To: "work", Alternatives: true, Drive: true}},
+		{[]string{"-to", "work", "-via", "daycare", "-via", "gym", "-optimize"}, cmd.CommuteCmd{From: "default", To: "work", Via: []string{"daycare", "gym"}, Optimize: true, Drive: true}},
+	}
+
+	for idx, tt := range tests {
+		r, err := a.parseDirectionsCmd(&conf, nil, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Director == nil {
+			t.Fatalf("[%v] Unexpected nil Director", idx)
+		} else if r.Locator == nil {
+			t.Fatalf("[%v] Unexpected nil Locator", idx)
+		}
+
+		if r.ReverseGeocoder == nil {
+			t.Fatalf("[%v] Unexpected nil ReverseGeocoder", idx)
+		} else if r.Geocoder == nil {
+			t.Fatalf("[%v] Unexpected nil Geocoder", idx)
+		} else if r.TimeZoner == nil {
+			t.Fatalf("[%v] Unexpected nil TimeZoner", idx)
+		} else if r.NearbySearcher == nil || r.Matrixer == nil {
+			t.Fatalf("[%v] Unexpected nil NearbySearcher or Matrixer", idx)
+		}
+
+		if r.Files == nil {
+			t.Fatalf("[%v] Unexpected nil Files", idx)
+		}
+
+		r.Director, r.Locator, r.ReverseGeocoder, r.Geocoder, r.TimeZoner = nil, nil, nil, nil, nil
+		r.NearbySearcher, r.Matrixer, r.Durationer, r.Estimator, r.Elevator = nil, nil, nil, nil, nil
+		if !reflect.DeepEqual(r.CommuteCmd, tt.expected) {
+			t.Fatalf("[%v] Unexpected CommuteCmd parsed, expected=%+v, got=%+v", idx, tt.expected, r.CommuteCmd)
+		}
+	}
+
+	// Export
+	r, err := a.parseDirectionsCmd(&conf, nil, []string{"-to", "work", "-export", "route.gpx"})
+	if err != nil {
+		t.Fatal(err)
+	} else if r.Export != "route.gpx" {
+		t.Fatalf("Unexpected Export parsed, expected=%v, got=%v", "route.gpx", r.Export)
+	}
+}
+
+func TestArgParser_parseWhereAmICmd(t *testing.T) {
+	var conf cmd.Configuration
+	var a ArgParser
+
+	// No API key should return an error
+	if _, err := a.parseWhereAmICmd(&conf, nil); err != ErrAPIKeyMissing {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrAPIKeyMissing, err)
+	}
+
+	conf.APIKey = "example"
+	r, err := a.parseWhereAmICmd(&conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Locator == nil {
+		t.Fatalf("Unexpected nil Locator")
+	} else if r.ReverseGeocoder == nil {
+		t.Fatalf("Unexpected nil ReverseGeocoder")
+	}
+}
+
+func TestArgParser_parseMatrixCmd(t *testing.T) {
+	var conf cmd.Configuration
+	var a ArgParser
+
+	// No API key should return an error
+	if _, err := a.parseMatrixCmd(&conf, []string{"-all"}); err == nil {
+		t.Fatalf("Expected error for empty API key")
+	}
+
+	conf.APIKey = "example"
+
+	tests := []struct {
+		args     []string
+		expected cmd.MatrixCmd
+	}{
+		{[]string{}, cmd.MatrixCmd{CommuteCmd: cmd.CommuteCmd{Drive: true}}},
+		{[]string{"-all", "-walk"}, cmd.MatrixCmd{CommuteCmd: cmd.CommuteCmd{Walk: true}, All: true}},
+		{[]string{"-from", "home", "-from", "gym", "-to", "work"}, cmd.MatrixCmd{CommuteCmd: cmd.CommuteCmd{Drive: true}, Origins: []string{"home", "gym"}, Destinations: []string{"work"}}},
+		{[]string{"-all", "-arrive-by", "9am", "-transit-via", "rail"}, cmd.MatrixCmd{CommuteCmd: cmd.CommuteCmd{Transit: true, ArriveBy: "9am", TransitVia: "rail"}, All: true}},
+	}
+
+	for idx, tt := range tests {
+		r, err := a.parseMatrixCmd(&conf, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Matrixer == nil {
+			t.Fatalf("[%v] Unexpected nil Matrixer", idx)
+		}
+
+		r.Matrixer, r.Durationer, r.Geocoder = nil, nil, nil
+		if !reflect.DeepEqual(*r, tt.expected) {
+			t.Fatalf("[%v] Unexpected MatrixCmd parsed, expected=%+v, got=%+v", idx, tt.expected, *r)
 		}
 	}
 }
@@ -195,6 +414,20 @@ func TestArgParser_parseCommuteCmd(t *testing.T) {
 func TestArgParser_parseAddCmd(t *testing.T) {
 	var a ArgParser
 	var s MockStorageProvider
+	var conf cmd.Configuration
+
+	// No API key should return an error, unless a location is provided, as
+	// coordinates can be added without one.
+	if _, err := a.parseAddCmd(&conf, &s, []string{"-name", "home"}); err != ErrAPIKeyMissing {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrAPIKeyMissing, err)
+	}
+	if r, err := a.parseAddCmd(&conf, &s, []string{"-name", "home", "-location", "43.6,-79.3"}); err != nil {
+		t.Fatal(err)
+	} else if r.Geocoder != nil || r.Searcher != nil {
+		t.Fatalf("Unexpected Geocoder or Searcher without an API key, got=%v, %v", r.Geocoder, r.Searcher)
+	}
+
+	conf.APIKey = "example"
 
 	tests := []struct {
 		args     []string
@@ -204,10 +437,11 @@ func TestArgParser_parseAddCmd(t *testing.T) {
 		{[]string{"-name", "home"}, cmd.AddCmd{Name: "home"}},
 		{[]string{"-location", "123 Main St."}, cmd.AddCmd{Value: "123 Main St."}},
 		{[]string{"-name", "home", "-location", "123 Main St."}, cmd.AddCmd{Name: "home", Value: "123 Main St."}},
+		{[]string{"-name", "home", "-location", "123 Main St.", "-avoid", "highways"}, cmd.AddCmd{Name: "home", Value: "123 Main St.", Avoid: "highways"}},
 	}
 
 	for idx, tt := range tests {
-		r, err := a.parseAddCmd(&s, tt.args)
+		r, err := a.parseAddCmd(&conf, &s, tt.args)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -216,12 +450,352 @@ func TestArgParser_parseAddCmd(t *testing.T) {
 			t.Fatalf("[%v] Unexpected 'Name' parsed, expected=%v, got=%v", idx, tt.expected.Name, r.Name)
 		} else if tt.expected.Value != r.Value {
 			t.Fatalf("[%v] Unexpected 'Value' parsed, expected=%v, got=%v", idx, tt.expected.Value, r.Value)
+		} else if tt.expected.Avoid != r.Avoid {
+			t.Fatalf("[%v] Unexpected 'Avoid' parsed, expected=%v, got=%v", idx, tt.expected.Avoid, r.Avoid)
+		} else if r.Geocoder == nil {
+			t.Fatalf("[%v] Unexpected nil Geocoder", idx)
+		} else if r.Searcher == nil {
+			t.Fatalf("[%v] Unexpected nil Searcher", idx)
+		} else if r.Input == nil {
+			t.Fatalf("[%v] Unexpected nil Input", idx)
+		} else if r.Store != &s {
+			t.Fatalf("[%v] Unexpected Store, expected=%v, got=%v", idx, s, r.Store)
+		}
+	}
+}
+
+func TestArgParser_parseSearchCmd(t *testing.T) {
+	var a ArgParser
+	var s MockStorageProvider
+	var conf cmd.Configuration
+
+	// No API key should return an error
+	if _, err := a.parseSearchCmd(&conf, &s, []string{"cn tower"}); err != ErrAPIKeyMissing {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrAPIKeyMissing, err)
+	}
+
+	conf.APIKey = "example"
+
+	tests := []struct {
+		args     []string
+		expected cmd.SearchCmd
+	}{
+		{[]string{}, cmd.SearchCmd{}},
+		{[]string{"cn tower"}, cmd.SearchCmd{Query: "cn tower"}},
+		{[]string{"cn", "tower"}, cmd.SearchCmd{Query: "cn tower"}},
+		{[]string{"-name", "tower", "cn tower"}, cmd.SearchCmd{Query: "cn tower", Name: "tower"}},
+	}
+
+	for idx, tt := range tests {
+		r, err := a.parseSearchCmd(&conf, &s, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.expected.Query != r.Query {
+			t.Fatalf("[%v] Unexpected 'Query' parsed, expected=%v, got=%v", idx, tt.expected.Query, r.Query)
+		} else if tt.expected.Name != r.Name {
+			t.Fatalf("[%v] Unexpected 'Name' parsed, expected=%v, got=%v", idx, tt.expected.Name, r.Name)
+		} else if r.Searcher == nil {
+			t.Fatalf("[%v] Unexpected nil Searcher", idx)
+		} else if r.Input == nil {
+			t.Fatalf("[%v] Unexpected nil Input", idx)
 		} else if r.Store != &s {
 			t.Fatalf("[%v] Unexpected Store, expected=%v, got=%v", idx, s, r.Store)
 		}
 	}
 }
 
+func TestArgParser_parseBestCmd(t *testing.T) {
+	var a ArgParser
+	var s MockStorageProvider
+	var conf cmd.Configuration
+
+	// No API key should return an error
+	if _, err := a.parseBestCmd(&conf, &s, []string{"-between", "07:00-10:00"}); err == nil {
+		t.Fatalf("Expected error for empty API key")
+	}
+
+	conf.APIKey = "example"
+
+	tests := []struct {
+		args     []string
+		expected cmd.BestCmd
+	}{
+		{[]string{}, cmd.BestCmd{CommuteCmd: cmd.CommuteCmd{From: cmd.DefaultLocationAlias, To: cmd.DefaultLocationAlias, Drive: true}, Step: cmd.DefaultBestStep}},
+		{[]string{"-to", "work", "-between", "07:00-10:00"}, cmd.BestCmd{CommuteCmd: cmd.CommuteCmd{From: cmd.DefaultLocationAlias, To: "work", Drive: true}, Between: "07:00-10:00", Step: cmd.DefaultBestStep}},
+		{[]string{"-to", "work", "-between", "7am-9am", "-step", "30m", "-reliable"}, cmd.BestCmd{CommuteCmd: cmd.CommuteCmd{From: cmd.DefaultLocationAlias, To: "work", Drive: true}, Between: "7am-9am", Step: "30m", Reliable: true}},
+		{[]string{"-from", "home", "-to", "work", "-transit", "-between", "07:00-08:00"}, cmd.BestCmd{CommuteCmd: cmd.CommuteCmd{From: "home", To: "work", Transit: true}, Between: "07:00-08:00", Step: cmd.DefaultBestStep}},
+	}
+
+	for idx, tt := range tests {
+		r, err := a.parseBestCmd(&conf, &s, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.expected.From != r.From {
+			t.Fatalf("[%v] Unexpected 'From' parsed, expected=%v, got=%v", idx, tt.expected.From, r.From)
+		} else if tt.expected.To != r.To {
+			t.Fatalf("[%v] Unexpected 'To' parsed, expected=%v, got=%v", idx, tt.expected.To, r.To)
+		} else if tt.expected.Drive != r.Drive || tt.expected.Transit != r.Transit {
+			t.Fatalf("[%v] Unexpected commute method parsed, expected=%+v, got=%+v", idx, tt.expected.CommuteCmd, r.CommuteCmd)
+		} else if tt.expected.Between != r.Between {
+			t.Fatalf("[%v] Unexpected 'Between' parsed, expected=%v, got=%v", idx, tt.expected.Between, r.Between)
+		} else if tt.expected.Step != r.Step {
+			t.Fatalf("[%v] Unexpected 'Step' parsed, expected=%v, got=%v", idx, tt.expected.Step, r.Step)
+		} else if tt.expected.Reliable != r.Reliable {
+			t.Fatalf("[%v] Unexpected 'Reliable' parsed, expected=%v, got=%v", idx, tt.expected.Reliable, r.Reliable)
+		} else if r.Durationer == nil || r.TimeZoner == nil {
+			t.Fatalf("[%v] Unexpected nil Durationer or TimeZoner", idx)
+		} else if r.Store != &s {
+			t.Fatalf("[%v] Unexpected Store, expected=%v, got=%v", idx, s, r.Store)
+		}
+	}
+}
+
+func TestArgParser_parseLeaveCmd(t *testing.T) {
+	var a ArgParser
+	var s MockStorageProvider
+	var conf cmd.Configuration
+
+	// No API key should return an error
+	if _, err := a.parseLeaveCmd(&conf, &s, []string{"-arrive", "09:30"}); err == nil {
+		t.Fatalf("Expected error for empty API key")
+	}
+
+	conf.APIKey = "example"
+
+	tests := []struct {
+		args     []string
+		expected cmd.LeaveCmd
+	}{
+		{[]string{}, cmd.LeaveCmd{CommuteCmd: cmd.CommuteCmd{From: cmd.DefaultLocationAlias, To: cmd.DefaultLocationAlias, Drive: true}}},
+		{[]string{"-to", "client", "-arrive", "09:30"}, cmd.LeaveCmd{CommuteCmd: cmd.CommuteCmd{From: cmd.DefaultLocationAlias, To: "client", Drive: true}, Arrive: "09:30"}},
+		{[]string{"-to", "client", "-arrive", "09:30", "-buffer", "10m"}, cmd.LeaveCmd{CommuteCmd: cmd.CommuteCmd{From: cmd.DefaultLocationAlias, To: "client", Drive: true}, Arrive: "09:30", Buffer: "10m"}},
+		{[]string{"-from", "home", "-to", "client", "-drive", "-transit", "-arrive", "9:30am"}, cmd.LeaveCmd{CommuteCmd: cmd.CommuteCmd{From: "home", To: "client", Drive: true, Transit: true}, Arrive: "9:30am"}},
+	}
+
+	for idx, tt := range tests {
+		r, err := a.parseLeaveCmd(&conf, &s, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.expected.From != r.From {
+			t.Fatalf("[%v] Unexpected 'From' parsed, expected=%v, got=%v", idx, tt.expected.From, r.From)
+		} else if tt.expected.To != r.To {
+			t.Fatalf("[%v] Unexpected 'To' parsed, expected=%v, got=%v", idx, tt.expected.To, r.To)
+		} else if tt.expected.Drive != r.Drive || tt.expected.Transit != r.Transit {
+			t.Fatalf("[%v] Unexpected commute method parsed, expected=%+v, got=%+v", idx, tt.expected.CommuteCmd, r.CommuteCmd)
+		} else if tt.expected.Arrive != r.Arrive {
+			t.Fatalf("[%v] Unexpected 'Arrive' parsed, expected=%v, got=%v", idx, tt.expected.Arrive, r.Arrive)
+		} else if tt.expected.Buffer != r.Buffer {
+			t.Fatalf("[%v] Unexpected 'Buffer' parsed, expected=%v, got=%v", idx, tt.expected.Buffer, r.Buffer)
+		} else if r.Durationer == nil || r.Director == nil || r.TimeZoner == nil {
+			t.Fatalf("[%v] Unexpected nil Durationer, Director or TimeZoner", idx)
+		} else if r.Store != &s {
+			t.Fatalf("[%v] Unexpected Store, expected=%v, got=%v", idx, s, r.Store)
+		}
+	}
+}
+
+func TestArgParser_parseDefaultsCmd(t *testing.T) {
+	var a ArgParser
+	var s MockStorageProvider
+
+	on, off := true, false
+	tests := []struct {
+		args     []string
+		expected cmd.DefaultsCmd
+	}{
+		{[]string{}, cmd.DefaultsCmd{}},
+		{[]string{"-avoid", "none"}, cmd.DefaultsCmd{Avoid: "none"}},
+		{[]string{"-avoid", "tolls,ferries"}, cmd.DefaultsCmd{Avoid: "tolls,ferries"}},
+		{[]string{"-distance"}, cmd.DefaultsCmd{Distance: &on}},
+		{[]string{"-distance=false", "-units", "imperial"}, cmd.DefaultsCmd{Distance: &off, Units: "imperial"}},
+		{[]string{"-transit-via", "subway,train", "-transit-prefer", "fewer_transfers"}, cmd.DefaultsCmd{TransitVia: "subway,train", TransitPrefer: "fewer_transfers"}},
+		{[]string{"-provider", "google"}, cmd.DefaultsCmd{Provider: "google"}},
+		{[]string{"-provider", "osrm", "-provider-setting", "url=http://osrm", "-provider-setting", "drive-profile=car"}, cmd.DefaultsCmd{Provider: "osrm", ProviderSettings: []string{"url=http://osrm", "drive-profile=car"}}},
+	}
+
+	for idx, tt := range tests {
+		r, err := a.parseDefaultsCmd(&s, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.expected.Avoid != r.Avoid {
+			t.Fatalf("[%v] Unexpected 'Avoid' parsed, expected=%v, got=%v", idx, tt.expected.Avoid, r.Avoid)
+		} else if (tt.expected.Distance == nil) != (r.Distance == nil) || (r.Distance != nil && *tt.expected.Distance != *r.Distance) {
+			t.Fatalf("[%v] Unexpected 'Distance' parsed, expected=%v, got=%v", idx, tt.expected.Distance, r.Distance)
+		} else if tt.expected.Units != r.Units {
+			t.Fatalf("[%v] Unexpected 'Units' parsed, expected=%v, got=%v", idx, tt.expected.Units, r.Units)
+		} else if tt.expected.TransitVia != r.TransitVia {
+			t.Fatalf("[%v] Unexpected 'TransitVia' parsed, expected=%v, got=%v", idx, tt.expected.TransitVia, r.TransitVia)
+		} else if tt.expected.TransitPrefer != r.TransitPrefer {
+			t.Fatalf("[%v] Unexpected 'TransitPrefer' parsed, expected=%v, got=%v", idx, tt.expected.TransitPrefer, r.TransitPrefer)
+		} else if tt.expected.Provider != r.Provider {
+			t.Fatalf("[%v] Unexpected 'Provider' parsed, expected=%v, got=%v", idx, tt.expected.Provider, r.Provider)
+		} else if !testStringsEq(tt.expected.ProviderSettings, r.ProviderSettings) {
+			t.Fatalf("[%v] Unexpected 'ProviderSettings' parsed, expected=%v, got=%v", idx, tt.expected.ProviderSettings, r.ProviderSettings)
+		} else if r.Store != &s {
+			t.Fatalf("[%v] Unexpected Store, expected=%v, got=%v", idx, s, r.Store)
+		}
+	}
+}
+
+func TestArgParser_provider(t *testing.T) {
+	var a ArgParser
+	conf := cmd.Configuration{APIKey: "example"}
+
+	r, err := geo.NewRouter(conf.APIKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, l, err := a.provider(&conf, r)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, ok := p.(*geo.Router); !ok {
+		t.Fatalf("Unexpected Provider, expected=*geo.Router, got=%T", p)
+	} else if l != p {
+		t.Fatalf("Unexpected Locator, expected=%v, got=%v", p, l)
+	}
+
+	conf.ProviderSettings = map[string]string{geo.GTFSFeedSetting: "missing-feed.zip"}
+	if _, _, err := a.provider(&conf, r); err == nil {
+		t.Fatal("Expected error for a missing GTFS feed")
+	}
+
+	conf.ProviderSettings = nil
+	conf.Provider = "unknown"
+	if _, _, err := a.provider(&conf, r); err != geo.ErrUnknownProvider {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", geo.ErrUnknownProvider, err)
+	}
+	if _, err := a.parseCommuteCmd(&conf, nil, []string{"-to", "work"}); err != geo.ErrUnknownProvider {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", geo.ErrUnknownProvider, err)
+	}
+}
+
+func TestArgParser_httpClient(t *testing.T) {
+	conf := cmd.Configuration{APIKey: "example"}
+
+	tests := []struct {
+		a         ArgParser
+		transport interface{}
+		err       bool
+	}{
+		{ArgParser{}, nil, false},
+		{ArgParser{Record: "cassette.json"}, &geo.Recorder{}, false},
+		{ArgParser{Replay: "missing-cassette.json"}, nil, true},
+		{ArgParser{Record: "cassette.json", Replay: "cassette.json"}, nil, true},
+	}
+
+	for idx, tt := range tests {
+		hc, err := tt.a.httpClient(&conf)
+		if tt.err != (err != nil) {
+			t.Fatalf("[%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if tt.transport == nil && hc != nil {
+			t.Fatalf("[%v] Unexpected http.Client, expected=nil, got=%v", idx, hc)
+		} else if tt.transport != nil && reflect.TypeOf(hc.Transport) != reflect.TypeOf(tt.transport) {
+			t.Fatalf("[%v] Unexpected Transport, expected=%T, got=%T", idx, tt.transport, hc.Transport)
+		}
+
+		if hc != nil {
+			if again, _ := tt.a.httpClient(&conf); again != hc {
+				t.Fatalf("[%v] Expected http.Client to be shared, expected=%v, got=%v", idx, hc, again)
+			}
+		}
+	}
+}
+
+func TestArgParser_estimator(t *testing.T) {
+	var a ArgParser
+	conf := cmd.Configuration{APIKey: "example"}
+
+	c, err := a.parseCommuteCmd(&conf, nil, []string{"-to", "work", "-offline"})
+	if err != nil {
+		t.Fatal(err)
+	} else if !c.Offline {
+		t.Fatal("Expected Offline to be parsed")
+	} else if _, ok := c.Estimator.(*geo.Offline); !ok {
+		t.Fatalf("Unexpected Estimator, expected=*geo.Offline, got=%T", c.Estimator)
+	}
+
+	b, err := a.parseBestCmd(&conf, nil, []string{"-to", "work", "-offline"})
+	if err != nil {
+		t.Fatal(err)
+	} else if !b.Offline || b.Estimator == nil {
+		t.Fatalf("Unexpected Offline or Estimator, got=%v/%v", b.Offline, b.Estimator)
+	}
+
+	l, err := a.parseLeaveCmd(&conf, nil, []string{"-to", "work", "-offline"})
+	if err != nil {
+		t.Fatal(err)
+	} else if !l.Offline || l.Estimator == nil {
+		t.Fatalf("Unexpected Offline or Estimator, got=%v/%v", l.Offline, l.Estimator)
+	}
+
+	// Without an API key, only offline estimates and other providers are available.
+	conf.APIKey = ""
+	if _, err := a.parseCommuteCmd(&conf, nil, []string{"-to", "work"}); err != ErrAPIKeyMissing {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrAPIKeyMissing, err)
+	}
+
+	c, err = a.parseCommuteCmd(&conf, nil, []string{"-to", "work", "-offline"})
+	if err != nil {
+		t.Fatal(err)
+	} else if c.Durationer != nil || c.Director != nil || c.Locator != nil || c.Geocoder != nil || c.TimeZoner != nil {
+		t.Fatalf("Unexpected Google Maps dependencies without an API key, got=%+v", c)
+	} else if c.Estimator == nil {
+		t.Fatal("Unexpected nil Estimator")
+	}
+
+	conf.Provider = geo.OfflineProvider
+	c, err = a.parseCommuteCmd(&conf, nil, []string{"-to", "work"})
+	if err != nil {
+		t.Fatal(err)
+	} else if _, ok := c.Durationer.(*geo.Offline); !ok {
+		t.Fatalf("Unexpected Durationer, expected=*geo.Offline, got=%T", c.Durationer)
+	} else if c.Director != nil || c.Locator != nil {
+		t.Fatalf("Unexpected Google Maps dependencies without an API key, got=%+v", c)
+	}
+	conf.Provider = ""
+
+	// Directions and places are only used when the provider supports them.
+	conf.APIKey = "example"
+	conf.Provider = geo.OfflineProvider
+	c, err = a.parseCommuteCmd(&conf, nil, []string{"-to", "work"})
+	if err != nil {
+		t.Fatal(err)
+	} else if c.Director != nil || c.Elevator != nil || c.NearbySearcher != nil || c.Matrixer != nil {
+		t.Fatalf("Unexpected directions or places for the offline provider, got=%+v", c)
+	} else if c.Geocoder == nil || c.TimeZoner == nil {
+		t.Fatalf("Unexpected nil Geocoder or TimeZoner, got=%+v", c)
+	}
+
+	// Matrices are estimated by the provider, without an API key when it supports them.
+	conf.APIKey = ""
+	conf.Provider = geo.OSRMProvider
+	conf.ProviderSettings = map[string]string{geo.OSRMURLSetting: "http://localhost:5000"}
+	m, err := a.parseMatrixCmd(&conf, []string{"-all"})
+	if err != nil {
+		t.Fatal(err)
+	} else if _, ok := m.Matrixer.(*geo.OSRM); !ok {
+		t.Fatalf("Unexpected Matrixer, expected=*geo.OSRM, got=%T", m.Matrixer)
+	}
+	conf.APIKey = "example"
+	conf.ProviderSettings = nil
+	conf.Provider = ""
+
+	// Invalid settings only make offline estimates unavailable.
+	conf.ProviderSettings = map[string]string{geo.OfflineDetourSetting: "lots"}
+	if e := a.estimator(&conf); e != nil {
+		t.Fatalf("Unexpected Estimator, expected=nil, got=%v", e)
+	}
+}
+
 func testStringsEq(a, b []string) bool {
 	if a == nil && b == nil {
 		return true
@@ -3,6 +3,10 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
 )
 
 var (
@@ -10,35 +14,141 @@ var (
 	ErrAddNameMissing = errors.New("missing -name parameter")
 	// ErrAddLocationMissing is returned when running the add command the the -location arugment is missing.
 	ErrAddLocationMissing = errors.New("missing -location parameter")
+	// ErrInvalidChoice is returned when the user chooses a location that wasn't offered.
+	ErrInvalidChoice = errors.New("invalid choice, expected one of the listed locations")
+	// ErrAddCoordinatesRequired is returned when adding an address without a Geocoder to look it up.
+	ErrAddCoordinatesRequired = errors.New("an API key is required to look up addresses, use a 'lat,lng' -location instead")
+)
+
+const (
+	// MsgChooseLocationPrompt is used to prompt the user to choose between multiple matching locations.
+	MsgChooseLocationPrompt = promptPrefix + "Choose a location: [1-%v]"
+	// MsgSearchLocationPrompt is used to prompt the user to search for a location to add.
+	MsgSearchLocationPrompt = promptPrefix + "Search for a location: (ex. a business, landmark or partial address)"
 )
 
 // AddCmd represents a command to add a named location.
+//
+// The location is geocoded before it's added, and the user is prompted
+// to choose between multiple matching locations using the Input. When no
+// location is provided, the user is prompted to search for one instead.
+//
+// Without a Geocoder, the location must be "lat,lng" coordinates, which are
+// added as they are.
 type AddCmd struct {
 	Name  string
 	Value string
+	Avoid string
 
-	Store StorageProvider
+	Geocoder Geocoder
+	Searcher Searcher
+	Input    Scanner
+	Store    StorageProvider
 }
 
-// Run adds the named location, overwriting the existing value if necessary.
+// Run geocodes and adds the named location, overwriting the existing value and
+// route features to avoid if necessary.
 func (a *AddCmd) Run(conf *Configuration, i Indicator) error {
-	conf.Locations[a.Name] = a.Value
-	return a.Store.Save(conf)
+	places, err := a.find(i)
+	if err != nil {
+		return err
+	}
+
+	place := places[0]
+	if len(places) > 1 {
+		place, err = choosePlace(a.Input, i, places)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := saveLocation(conf, a.Store, a.Name, a.Avoid, place); err != nil {
+		return err
+	}
+
+	i.Indicate("%v: %v", a.Name, place)
+	return nil
+}
+
+// find returns the places matching the location provided, or prompts the user
+// to search for places when no location was provided.
+func (a *AddCmd) find(i Indicator) ([]geo.Place, error) {
+	if len(a.Value) > 0 && a.Geocoder == nil {
+		lat, lng, err := parseLatLng(a.Value)
+		if err != nil {
+			return nil, ErrAddCoordinatesRequired
+		}
+		p := geo.Position{Lat: lat, Lng: lng}
+		return []geo.Place{{Address: p.String(), Lat: lat, Lng: lng}}, nil
+	} else if len(a.Value) > 0 {
+		return a.Geocoder.Geocode(a.Value)
+	}
+
+	query := promptForString(a.Input, i, MsgSearchLocationPrompt)
+	return a.Searcher.Search(query)
+}
+
+// choosePlace lists the places provided and prompts the user to choose one.
+func choosePlace(s Scanner, i Indicator, places []geo.Place) (geo.Place, error) {
+	for n, p := range places {
+		i.Indicate("%v. %v", n+1, p)
+	}
+
+	in := promptForString(s, i, fmt.Sprintf(MsgChooseLocationPrompt, len(places)))
+	choice, err := strconv.Atoi(strings.TrimSpace(in))
+	if err != nil || choice < 1 || choice > len(places) {
+		return geo.Place{}, ErrInvalidChoice
+	}
+
+	return places[choice-1], nil
+}
+
+// saveLocation stores a Place as a named location, along with the route features to
+// avoid for commutes to or from it, and saves the Configuration.
+func saveLocation(conf *Configuration, s StorageProvider, name, avoid string, place geo.Place) error {
+	if conf.Locations == nil {
+		conf.Locations = make(map[string]Location)
+	}
+	conf.Locations[name] = Location{Address: place.Address, Lat: place.Lat, Lng: place.Lng}
+
+	if len(avoid) > 0 {
+		if conf.LocationAvoid == nil {
+			conf.LocationAvoid = make(map[string]string)
+		}
+		conf.LocationAvoid[name] = avoid
+	} else {
+		delete(conf.LocationAvoid, name)
+	}
+
+	return s.Save(conf)
 }
 
 // Validate validates the AddCmd is properly initialized and ready to be Run.
+//
+// A location is only required when there is no Searcher to search for one.
 func (a *AddCmd) Validate(conf *Configuration) error {
 	if len(a.Name) == 0 {
 		return ErrAddNameMissing
 	}
-	if len(a.Value) == 0 {
+	if len(a.Value) == 0 && a.Searcher == nil {
 		return ErrAddLocationMissing
 	}
 
+	if len(a.Avoid) > 0 {
+		avoid, err := geo.ParseAvoid(a.Avoid)
+		if err != nil {
+			return err
+		}
+		a.Avoid = geo.FormatAvoid(avoid)
+	}
+
 	return nil
 }
 
 // String returns a string representation of the AddCmd.
 func (a *AddCmd) String() string {
+	if len(a.Value) == 0 {
+		return fmt.Sprintf("Adding named location '%v'", a.Name)
+	}
 	return fmt.Sprintf("Adding named location '%v' with value '%v'", a.Name, a.Value)
 }
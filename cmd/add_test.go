@@ -2,18 +2,30 @@ package cmd
 
 import (
 	"errors"
+	"reflect"
 	"testing"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
 )
 
 func TestAddCmd_Run(t *testing.T) {
+	geocoder := func(address string) *mockGeocoder {
+		return &mockGeocoder{
+			geocodeFn: func(value string) ([]geo.Place, error) {
+				return []geo.Place{{Address: address, Lat: 43.6, Lng: -79.3}}, nil
+			},
+		}
+	}
+
 	tests := []struct {
 		conf *Configuration
 
 		name  string
 		value string
 	}{
-		{&Configuration{Locations: make(map[string]string)}, "name", "value"},
-		{&Configuration{Locations: map[string]string{"name": "value1"}}, "name", "value2"},
+		{&Configuration{Locations: make(map[string]Location)}, "name", "value"},
+		{&Configuration{Locations: map[string]Location{"name": {Address: "value1"}}}, "name", "value2"},
+		{&Configuration{}, "name", "value"},
 	}
 
 	for idx, tt := range tests {
@@ -25,14 +37,97 @@ func TestAddCmd_Run(t *testing.T) {
 				return nil
 			},
 		}
-		a := AddCmd{Name: tt.name, Value: tt.value, Store: &m}
+		a := AddCmd{Name: tt.name, Value: tt.value, Geocoder: geocoder(tt.value + " formatted"), Store: &m}
 
-		if err := a.Run(tt.conf, nil); err != nil {
+		var i mockIndicator
+		if err := a.Run(tt.conf, &i); err != nil {
 			t.Fatal(err)
 		}
 
-		if tt.conf.Locations[tt.name] != tt.value {
-			t.Fatalf("[#%v] Unexpected value stored, expected=%v, got=%v", idx, tt.value, tt.conf.Locations[tt.name])
+		expect := Location{Address: tt.value + " formatted", Lat: 43.6, Lng: -79.3}
+		if tt.conf.Locations[tt.name] != expect {
+			t.Fatalf("[#%v] Unexpected value stored, expected=%v, got=%v", idx, expect, tt.conf.Locations[tt.name])
+		} else if len(i.out) != 1 || i.out[0] != tt.name+": "+expect.Address {
+			t.Fatalf("[#%v] Unexpected output, got=%v", idx, i.out)
+		}
+	}
+
+	// Avoid
+	{
+		aTests := []struct {
+			conf   *Configuration
+			avoid  string
+			expect map[string]string
+		}{
+			{&Configuration{Locations: make(map[string]Location)}, "highways", map[string]string{"name": "highways"}},
+			{&Configuration{Locations: make(map[string]Location), LocationAvoid: map[string]string{"other": "none"}}, "tolls", map[string]string{"name": "tolls", "other": "none"}},
+			{&Configuration{Locations: make(map[string]Location), LocationAvoid: map[string]string{"name": "tolls"}}, "", map[string]string{}},
+		}
+
+		for idx, tt := range aTests {
+			m := mockStorageProvider{
+				saveFn: func(i interface{}) error {
+					return nil
+				},
+			}
+			a := AddCmd{Name: "name", Value: "value", Avoid: tt.avoid, Geocoder: geocoder("value"), Store: &m}
+
+			if err := a.Run(tt.conf, &mockIndicator{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(tt.conf.LocationAvoid, tt.expect) {
+				t.Fatalf("[#%v] Unexpected LocationAvoid, expected=%v, got=%v", idx, tt.expect, tt.conf.LocationAvoid)
+			}
+		}
+	}
+
+	// Multiple matches
+	{
+		places := []geo.Place{
+			{Address: "123 Main St, Toronto, ON, Canada", Lat: 43.6, Lng: -79.3},
+			{Address: "123 Main St, Buffalo, NY, USA", Lat: 42.8, Lng: -78.8},
+		}
+		g := mockGeocoder{
+			geocodeFn: func(value string) ([]geo.Place, error) {
+				return places, nil
+			},
+		}
+		m := mockStorageProvider{
+			saveFn: func(i interface{}) error {
+				return nil
+			},
+		}
+
+		cTests := []struct {
+			input  []string
+			expect Location
+			err    error
+		}{
+			{[]string{"2"}, Location{Address: "123 Main St, Buffalo, NY, USA", Lat: 42.8, Lng: -78.8}, nil},
+			{[]string{"", " 1 "}, Location{Address: "123 Main St, Toronto, ON, Canada", Lat: 43.6, Lng: -79.3}, nil},
+			{[]string{"3"}, Location{}, ErrInvalidChoice},
+			{[]string{"Toronto"}, Location{}, ErrInvalidChoice},
+			{nil, Location{}, ErrInvalidChoice},
+		}
+
+		for idx, tt := range cTests {
+			conf := Configuration{}
+			a := AddCmd{Name: "home", Value: "123 Main St", Geocoder: &g, Input: &mockScanner{lines: tt.input}, Store: &m}
+
+			var i mockIndicator
+			if err := a.Run(&conf, &i); err != tt.err {
+				t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+			}
+
+			if conf.Locations["home"] != tt.expect {
+				t.Fatalf("[#%v] Unexpected value stored, expected=%v, got=%v", idx, tt.expect, conf.Locations["home"])
+			}
+
+			expectOut := []string{"1. " + places[0].Address, "2. " + places[1].Address, "> Choose a location: [1-2]"}
+			if !reflect.DeepEqual(i.out[:3], expectOut) {
+				t.Fatalf("[#%v] Unexpected output, expected=%q, got=%q", idx, expectOut, i.out)
+			}
 		}
 	}
 
@@ -44,11 +139,101 @@ func TestAddCmd_Run(t *testing.T) {
 			return testErr
 		}
 
-		conf := Configuration{Locations: make(map[string]string)}
-		a := AddCmd{Name: "name", Value: "value", Store: &m}
-		if err := a.Run(&conf, nil); err != testErr {
+		conf := Configuration{Locations: make(map[string]Location)}
+		a := AddCmd{Name: "name", Value: "value", Geocoder: geocoder("value"), Store: &m}
+		if err := a.Run(&conf, &mockIndicator{}); err != testErr {
 			t.Fatalf("Unexpected error, expected=%v, got=%v", testErr, err)
 		}
+
+		g := mockGeocoder{
+			geocodeFn: func(value string) ([]geo.Place, error) {
+				return nil, geo.ErrBadLocation
+			},
+		}
+		conf = Configuration{Locations: make(map[string]Location)}
+		a = AddCmd{Name: "name", Value: "value", Geocoder: &g, Store: &m}
+		if err := a.Run(&conf, &mockIndicator{}); err != geo.ErrBadLocation {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", geo.ErrBadLocation, err)
+		} else if _, ok := conf.Locations["name"]; ok {
+			t.Fatal("Unexpected location stored after failing to geocode")
+		}
+	}
+}
+
+func TestAddCmd_Run_coordinates(t *testing.T) {
+	m := mockStorageProvider{
+		saveFn: func(i interface{}) error {
+			return nil
+		},
+	}
+
+	// Without a Geocoder, coordinates are added as they are.
+	conf := Configuration{}
+	a := AddCmd{Name: "home", Value: "43.6, -79.3", Store: &m}
+
+	var i mockIndicator
+	if err := a.Run(&conf, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := Location{Address: "43.6,-79.3", Lat: 43.6, Lng: -79.3}
+	if conf.Locations["home"] != expect {
+		t.Fatalf("Unexpected value stored, expected=%v, got=%v", expect, conf.Locations["home"])
+	} else if len(i.out) != 1 || i.out[0] != "home: 43.6,-79.3" {
+		t.Fatalf("Unexpected output, expected=%v, got=%v", "home: 43.6,-79.3", i.out)
+	}
+
+	// Addresses can't be looked up.
+	conf = Configuration{}
+	a = AddCmd{Name: "work", Value: "123 Main St", Store: &m}
+	if err := a.Run(&conf, &i); err != ErrAddCoordinatesRequired {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrAddCoordinatesRequired, err)
+	} else if _, ok := conf.Locations["work"]; ok {
+		t.Fatal("Unexpected location stored without a Geocoder")
+	}
+}
+
+func TestAddCmd_Run_search(t *testing.T) {
+	places := []geo.Place{
+		{Name: "CN Tower", Address: "290 Bremner Blvd, Toronto, ON", Lat: 43.64, Lng: -79.38},
+		{Name: "CN Tower Parking", Address: "Bremner Blvd, Toronto, ON", Lat: 43.64, Lng: -79.39},
+	}
+	s := mockSearcher{
+		searchFn: func(query string) ([]geo.Place, error) {
+			if query != "cn tower" {
+				t.Fatalf("Unexpected query, expected=%v, got=%v", "cn tower", query)
+			}
+			return places, nil
+		},
+	}
+	m := mockStorageProvider{
+		saveFn: func(i interface{}) error {
+			return nil
+		},
+	}
+
+	var conf Configuration
+	a := AddCmd{Name: "tower", Searcher: &s, Input: &mockScanner{lines: []string{"cn tower", "1"}}, Store: &m}
+
+	var i mockIndicator
+	if err := a.Run(&conf, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := Location{Address: "290 Bremner Blvd, Toronto, ON", Lat: 43.64, Lng: -79.38}
+	if conf.Locations["tower"] != expect {
+		t.Fatalf("Unexpected value stored, expected=%v, got=%v", expect, conf.Locations["tower"])
+	}
+
+	expectOut := []string{
+		MsgSearchLocationPrompt,
+		"1. CN Tower, 290 Bremner Blvd, Toronto, ON",
+		"2. CN Tower Parking, Bremner Blvd, Toronto, ON",
+		"> Choose a location: [1-2]",
+		"tower: CN Tower, 290 Bremner Blvd, Toronto, ON",
+	}
+	if !reflect.DeepEqual(i.out, expectOut) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expectOut, i.out)
 	}
 }
 
@@ -56,18 +241,32 @@ func TestAddCmd_Validate(t *testing.T) {
 	tests := []struct {
 		name  string
 		value string
+		avoid string
 		err   error
+
+		expectAvoid string
 	}{
-		{"name", "value", nil},
-		{"", "value", ErrAddNameMissing},
-		{"name", "", ErrAddLocationMissing},
+		{"name", "value", "", nil, ""},
+		{"name", "value", "Highways, Tolls", nil, "highways,tolls"},
+		{"name", "value", "none", nil, "none"},
+		{"", "value", "", ErrAddNameMissing, ""},
+		{"name", "", "", ErrAddLocationMissing, ""},
+		{"name", "value", "bridges", geo.ErrInvalidAvoid, ""},
 	}
 
 	for idx, tt := range tests {
-		a := AddCmd{Name: tt.name, Value: tt.value}
+		a := AddCmd{Name: tt.name, Value: tt.value, Avoid: tt.avoid}
 
 		if err := a.Validate(nil); err != tt.err {
 			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err == nil && a.Avoid != tt.expectAvoid {
+			t.Fatalf("[#%v] Unexpected Avoid, expected=%v, got=%v", idx, tt.expectAvoid, a.Avoid)
 		}
 	}
+
+	// The location is searched for interactively when a Searcher is available.
+	a := AddCmd{Name: "name", Searcher: &mockSearcher{}}
+	if err := a.Validate(nil); err != nil {
+		t.Fatalf("Unexpected error, expected=nil, got=%v", err)
+	}
 }
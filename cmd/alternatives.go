@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+const (
+	fastestRouteMarker = "*"
+	unavailableColumn  = "-"
+)
+
+// alternatives retrieves the recommended and alternative routes for a TravelMode and outputs
+// them as a table ranked by duration, highlighting the fastest.
+//
+// The ranked routes are returned, fastest first.
+func (c *CommuteCmd) alternatives(tm geo.TravelMode, i Indicator) ([]geo.Route, error) {
+	routes, err := c.Director.Directions(c.From, c.To, tm, c.opts)
+	if err != nil {
+		return nil, err
+	} else if len(routes) == 0 {
+		return nil, geo.ErrUnavailable
+	}
+
+	ranked := make(routesByDuration, len(routes))
+	copy(ranked, routes)
+	sort.Stable(ranked)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\t#\tRoute\tDistance\tDuration\tIn Traffic")
+	for n, r := range ranked {
+		marker := ""
+		if n == 0 {
+			marker = fastestRouteMarker
+		}
+
+		summary := fmt.Sprintf("Route %v", n+1)
+		if len(r.Summary) > 0 {
+			summary = fmt.Sprintf("via %v", r.Summary)
+		}
+
+		traffic := unavailableColumn
+		if r.Traffic {
+			traffic = c.formatDuration(r.Duration)
+		}
+
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", marker, n+1, summary, c.formatDistance(r.Distance), c.formatDuration(r.TypicalDuration()), traffic)
+	}
+	w.Flush()
+
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		i.Indicate("%v", strings.TrimRight(line, " "))
+	}
+
+	return ranked, nil
+}
+
+// routesByDuration sorts routes by their most likely duration, shortest first.
+type routesByDuration []geo.Route
+
+func (r routesByDuration) Len() int           { return len(r) }
+func (r routesByDuration) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r routesByDuration) Less(i, j int) bool { return r[i].Duration < r[j].Duration }
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestCommuteCmd_alternatives(t *testing.T) {
+	routes := []geo.Route{
+		{
+			Estimate: geo.Estimate{Duration: time.Minute * 38, Distance: 24100, Traffic: true},
+			Summary:  "Hwy 401",
+			Legs:     []geo.Leg{{Duration: time.Minute * 28}},
+		},
+		{
+			Estimate: geo.Estimate{Duration: time.Minute * 35, Distance: 22300, Traffic: true},
+			Summary:  "Gardiner Expy",
+			Legs:     []geo.Leg{{Duration: time.Minute * 30}},
+		},
+		{
+			Estimate: geo.Estimate{Duration: time.Minute * 62, Distance: 31000},
+			Legs:     []geo.Leg{{Duration: time.Minute * 62}},
+		},
+	}
+
+	m := mockDirector{
+		directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			if from != "home" {
+				t.Fatalf("Unexpected From, expected=%v, got=%v", "home", from)
+			} else if to != "work" {
+				t.Fatalf("Unexpected To, expected=%v, got=%v", "work", to)
+			} else if tm != geo.Drive {
+				t.Fatalf("Unexpected TravelMode, expected=%v, got=%v", geo.Drive, tm)
+			} else if !o.Alternatives {
+				t.Fatal("Expected Alternatives to be requested")
+			}
+
+			return routes, nil
+		},
+	}
+
+	c := CommuteCmd{From: "home", To: "work", Drive: true, Alternatives: true, Director: &m}
+	c.opts.Alternatives = true
+
+	var i mockIndicator
+	ranked, err := c.alternatives(geo.Drive, &i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectRanked := []geo.Route{routes[1], routes[0], routes[2]}
+	if !reflect.DeepEqual(ranked, expectRanked) {
+		t.Fatalf("Unexpected ranked Routes, expected=%+v, got=%+v", expectRanked, ranked)
+	}
+
+	expect := []string{
+		"   #  Route              Distance  Duration          In Traffic",
+		"*  1  via Gardiner Expy  22.3 km   30 Minutes        35 Minutes",
+		"   2  via Hwy 401        24.1 km   28 Minutes        38 Minutes",
+		"   3  Route 3            31.0 km   1 Hour 2 Minutes  -",
+	}
+	if !reflect.DeepEqual(i.out, expect) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+	}
+
+	// Error from Director
+	{
+		testErr := errors.New("mock err")
+		m.directionsFn = func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			return nil, testErr
+		}
+
+		if _, err := c.alternatives(geo.Drive, &mockIndicator{}); err != testErr {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", testErr, err)
+		}
+	}
+}
+
+func TestCommuteCmd_Run_alternatives(t *testing.T) {
+	m := mockDirector{
+		directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			return []geo.Route{{Estimate: geo.Estimate{Duration: time.Minute}, Summary: tm.String()}}, nil
+		},
+	}
+
+	c := CommuteCmd{From: "home", To: "work", Drive: true, Walk: true, Alternatives: true, Director: &m}
+	var i mockIndicator
+	if err := c.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(i.out) != 6 {
+		t.Fatalf("Unexpected number of output lines, expected=%v, got=%q", 6, i.out)
+	} else if i.out[0] != "Drive:" {
+		t.Fatalf("Unexpected output, expected=%v, got=%v", "Drive:", i.out[0])
+	} else if i.out[3] != "Walk:" {
+		t.Fatalf("Unexpected output, expected=%v, got=%v", "Walk:", i.out[3])
+	}
+}
+
+func TestCommuteCmd_Validate_alternatives(t *testing.T) {
+	tests := []struct {
+		cmd CommuteCmd
+		err error
+	}{
+		{CommuteCmd{Drive: true, Alternatives: true}, nil},
+		{CommuteCmd{Transit: true, Alternatives: true}, nil},
+		{CommuteCmd{Drive: true, Range: true, Alternatives: true}, ErrRangeAndAlternativesProvided},
+	}
+
+	for idx, tt := range tests {
+		c := tt.cmd
+		c.From, c.To = "home", "work"
+
+		if err := c.Validate(&Configuration{}); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if c.opts.Alternatives != tt.cmd.Alternatives {
+			t.Fatalf("[#%v] Unexpected Alternatives option, expected=%v, got=%v", idx, tt.cmd.Alternatives, c.opts.Alternatives)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+const (
+	// DefaultBestStep is the time between each departure compared when no step is provided.
+	DefaultBestStep = "15m"
+
+	// maxBestDepartures is the maximum number of departure times compared, each of which
+	// requires a request to the Durationer.
+	maxBestDepartures = 24
+
+	// maxBestRequests is the maximum number of departure times estimated at once.
+	maxBestRequests = 4
+
+	bestDepartureMarker = "*"
+)
+
+var (
+	// ErrBestBetweenMissing is returned when running the best command without the -between argument.
+	ErrBestBetweenMissing = errors.New("missing -between parameter [ex. '07:00-10:00']")
+	// ErrInvalidBetween is returned when the -between argument is not a valid window of time.
+	ErrInvalidBetween = errors.New("invalid -between window, expected a start and end time [ex. '07:00-10:00', '7am-9:30am']")
+	// ErrInvalidStep is returned when the -step argument is not a positive duration.
+	ErrInvalidStep = errors.New("invalid -step, expected a positive duration [ex. '15m', '1h']")
+	// ErrTooManyDepartures is returned when the -between window and -step would compare too many departure times.
+	ErrTooManyDepartures = fmt.Errorf("too many departure times to compare, use a larger -step or a shorter -between window [max %v]", maxBestDepartures)
+	// ErrBestSingleMode is returned when the best departure time is requested for more than one commute method.
+	ErrBestSingleMode = errors.New("the best departure time can only be found for one commute method at a time")
+	// ErrBestScheduleProvided is returned when the -depart-at or -arrive-by arguments are used with the best command.
+	ErrBestScheduleProvided = errors.New("cannot use -depart-at or -arrive-by arguments with best, use -between instead")
+	// ErrReliableRequiresDrive is returned when the -reliable argument is used without the drive commute method.
+	ErrReliableRequiresDrive = errors.New("-reliable can only be used with the -drive commute method")
+)
+
+// BestCmd represents a command to find the best time to depart within a window of time,
+// by comparing the commute at regular intervals.
+//
+// The best departure is the one with the shortest likely duration, or when Reliable is
+// set, the one with the shortest pessimistic duration in traffic.
+type BestCmd struct {
+	CommuteCmd
+
+	Between  string
+	Step     string
+	Reliable bool
+
+	departures []time.Time
+}
+
+// Run estimates the commute for each departure time, and outputs them as a table
+// followed by the recommended departure.
+func (b *BestCmd) Run(conf *Configuration, i Indicator) error {
+	if len(b.departures) == 0 {
+		return nil
+	}
+
+	estimates, err := b.estimates(b.modes()[0])
+	if err != nil {
+		return err
+	}
+
+	best := b.best(estimates)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	header := "\tDepart\tDuration"
+	if b.Reliable {
+		header += "\tRange"
+	}
+	fmt.Fprintln(w, header)
+
+	durations := make([]float64, len(estimates))
+	for n, e := range estimates {
+		durations[n] = e.Duration.Minutes()
+
+		marker := ""
+		if n == best {
+			marker = bestDepartureMarker
+		}
+
+		row := fmt.Sprintf("%v\t%v\t%v", marker, b.departures[n].Format(stepTimeLayout), b.formatDuration(e.Duration))
+		if b.Reliable {
+			row += fmt.Sprintf("\t%v – %v", b.formatDuration(e.Optimistic), b.formatDuration(e.Pessimistic))
+		}
+		fmt.Fprintln(w, row)
+	}
+	w.Flush()
+
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		i.Indicate("%v", strings.TrimRight(line, " "))
+	}
+
+	i.Indicate("")
+	i.Indicate("Trend: %v %v %v", b.departures[0].Format(stepTimeLayout), sparkline(durations), b.departures[len(b.departures)-1].Format(stepTimeLayout))
+	i.Indicate("Best time to leave: %v (%v)", describeTime(b.departures[best], b.fromZone, nil, ""), b.format(estimates[best]))
+
+	return nil
+}
+
+// estimates returns the Estimate of the commute for each departure time.
+//
+// The Distance Matrix only accepts a single departure time per request, so they
+// can't be batched into fewer requests. Instead, up to maxBestRequests are made
+// at once to keep the sweep quick.
+func (b *BestCmd) estimates(m geo.TravelMode) ([]*geo.Estimate, error) {
+	estimates := make([]*geo.Estimate, len(b.departures))
+	errs := make([]error, len(b.departures))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxBestRequests)
+	for n, d := range b.departures {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(n int, d time.Time) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			opts := b.opts
+			opts.DepartAt = d
+			estimates[n], errs[n] = b.duration(m, opts)
+		}(n, d)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return estimates, nil
+}
+
+// best returns the index of the best estimate, preferring the earliest departure
+// when estimates are equal.
+func (b *BestCmd) best(estimates []*geo.Estimate) int {
+	score := func(e *geo.Estimate) time.Duration {
+		if b.Reliable {
+			return e.Pessimistic
+		}
+		return e.Duration
+	}
+
+	best := 0
+	for n, e := range estimates {
+		if score(e) < score(estimates[best]) {
+			best = n
+		}
+	}
+
+	return best
+}
+
+// Validate validates the BestCmd is properly initialized and ready to be Run.
+func (b *BestCmd) Validate(conf *Configuration) error {
+	if len(b.DepartAt) > 0 || len(b.ArriveBy) > 0 {
+		return ErrBestScheduleProvided
+	}
+
+	b.zoned = true
+	if err := b.CommuteCmd.Validate(conf); err != nil {
+		return err
+	}
+
+	if len(b.modes()) > 1 {
+		return ErrBestSingleMode
+	}
+	if b.Reliable && !b.Drive {
+		return ErrReliableRequiresDrive
+	}
+	if b.Reliable && !b.capabilities().TrafficRange {
+		return ErrTrafficRangeUnsupported
+	}
+	b.opts.TrafficRange = b.Reliable
+
+	var err error
+	b.departures, err = b.parseWindow()
+	return err
+}
+
+// parseWindow parses the Between window and Step into the departure times to compare,
+// in the time zone of the origin.
+func (b *BestCmd) parseWindow() ([]time.Time, error) {
+	if len(b.Between) == 0 {
+		return nil, ErrBestBetweenMissing
+	}
+
+	step := b.Step
+	if len(step) == 0 {
+		step = DefaultBestStep
+	}
+	interval, err := time.ParseDuration(step)
+	if err != nil || interval <= 0 {
+		return nil, ErrInvalidStep
+	}
+
+	bounds := strings.Split(b.Between, "-")
+	if len(bounds) != 2 {
+		return nil, ErrInvalidBetween
+	}
+
+	start, err := parseTime(bounds[0], now().In(b.originZone()))
+	if err != nil {
+		return nil, err
+	}
+
+	// The end of the window is the next occurrence after the start, so that
+	// windows starting later today and ending tomorrow are supported.
+	end, err := parseTime(bounds[1], start)
+	if err != nil {
+		return nil, err
+	}
+
+	if int(end.Sub(start)/interval)+1 > maxBestDepartures {
+		return nil, ErrTooManyDepartures
+	}
+
+	var departures []time.Time
+	for d := start; !d.After(end); d = d.Add(interval) {
+		departures = append(departures, d)
+	}
+
+	return departures, nil
+}
+
+// String returns a string representation of the BestCmd.
+func (b *BestCmd) String() string {
+	return fmt.Sprintf("Best departure from '%v' to '%v' between %v", b.From, b.To, b.Between)
+}
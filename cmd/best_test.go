@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestBestCmd_Run(t *testing.T) {
+	start := time.Date(2017, time.May, 1, 7, 0, 0, 0, time.UTC)
+	durations := map[time.Time]time.Duration{
+		start:                       time.Minute * 40,
+		start.Add(time.Minute * 30): time.Minute * 25,
+		start.Add(time.Minute * 60): time.Minute * 30,
+	}
+
+	b := BestCmd{
+		CommuteCmd: CommuteCmd{
+			From:  "home",
+			To:    "work",
+			Drive: true,
+			Durationer: &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+				if from != "home" || to != "work" {
+					t.Fatalf("Unexpected locations, expected=home/work, got=%v/%v", from, to)
+				} else if tm != geo.Drive {
+					t.Fatalf("Unexpected TravelMode, expected=%v, got=%v", geo.Drive, tm)
+				}
+				return &geo.Estimate{Duration: durations[o.DepartAt]}, nil
+			}},
+		},
+		departures: []time.Time{start, start.Add(time.Minute * 30), start.Add(time.Minute * 60)},
+	}
+
+	var i mockIndicator
+	if err := b.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"   Depart  Duration",
+		"   07:00   40 Minutes",
+		"*  07:30   25 Minutes",
+		"   08:00   30 Minutes",
+		"",
+		"Trend: 07:00 ^_. 08:00",
+		"Best time to leave: Mon May 1 07:30 (25 Minutes)",
+	}
+	if !reflect.DeepEqual(i.out, expect) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+	}
+
+	// Durationer errors are returned.
+	expectErr := errors.New("durationer error")
+	b.Durationer = &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+		return nil, expectErr
+	}}
+	if err := b.Run(&Configuration{}, &i); err != expectErr {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", expectErr, err)
+	}
+
+	// Nothing is estimated without departures, such as when validation failed.
+	b.departures = nil
+	i = mockIndicator{}
+	if err := b.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	} else if len(i.out) > 0 {
+		t.Fatalf("Unexpected output, expected=none, got=%q", i.out)
+	}
+}
+
+func TestBestCmd_best(t *testing.T) {
+	tests := []struct {
+		reliable  bool
+		estimates []*geo.Estimate
+		expected  int
+	}{
+		{false, []*geo.Estimate{{Duration: 3}}, 0},
+		{false, []*geo.Estimate{{Duration: 3}, {Duration: 2}, {Duration: 4}}, 1},
+		{false, []*geo.Estimate{{Duration: 2}, {Duration: 3}, {Duration: 2}}, 0},
+		{false, []*geo.Estimate{{Duration: 3, Pessimistic: 4}, {Duration: 2, Pessimistic: 9}}, 1},
+		{true, []*geo.Estimate{{Duration: 3, Pessimistic: 4}, {Duration: 2, Pessimistic: 9}}, 0},
+	}
+
+	for idx, tt := range tests {
+		b := BestCmd{Reliable: tt.reliable}
+		if best := b.best(tt.estimates); best != tt.expected {
+			t.Fatalf("[#%v] Unexpected best, expected=%v, got=%v", idx, tt.expected, best)
+		}
+	}
+}
+
+func TestBestCmd_Validate(t *testing.T) {
+	defer func(fn func() time.Time) { now = fn }(now)
+	at := time.Date(2017, time.May, 1, 6, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+
+	conf := Configuration{Locations: map[string]Location{
+		"home": {Address: "123 Main St", Zone: "UTC"},
+		"work": {Address: "321 Maple Ave", Zone: "UTC"},
+	}}
+	tz := &mockTimeZoner{timezoneFn: func(lat, lng float64, when time.Time) (*time.Location, error) {
+		t.Fatal("Unexpected time zone lookup")
+		return nil, nil
+	}}
+
+	tests := []struct {
+		b         BestCmd
+		expectErr error
+		expected  []time.Time
+	}{
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "07:00-08:00", Step: "30m"}, nil, []time.Time{
+			time.Date(2017, time.May, 1, 7, 0, 0, 0, time.UTC),
+			time.Date(2017, time.May, 1, 7, 30, 0, 0, time.UTC),
+			time.Date(2017, time.May, 1, 8, 0, 0, 0, time.UTC),
+		}},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "7am-8am", Step: "25m"}, nil, []time.Time{
+			time.Date(2017, time.May, 1, 7, 0, 0, 0, time.UTC),
+			time.Date(2017, time.May, 1, 7, 25, 0, 0, time.UTC),
+			time.Date(2017, time.May, 1, 7, 50, 0, 0, time.UTC),
+		}},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "23:30-00:30", Step: "30m"}, nil, []time.Time{
+			time.Date(2017, time.May, 1, 23, 30, 0, 0, time.UTC),
+			time.Date(2017, time.May, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2017, time.May, 2, 0, 30, 0, 0, time.UTC),
+		}},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "05:00-05:30"}, nil, []time.Time{
+			time.Date(2017, time.May, 2, 5, 0, 0, 0, time.UTC),
+			time.Date(2017, time.May, 2, 5, 15, 0, 0, time.UTC),
+			time.Date(2017, time.May, 2, 5, 30, 0, 0, time.UTC),
+		}},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "07:00-10:00", Step: "5m"}, ErrTooManyDepartures, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}}, ErrBestBetweenMissing, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "07:00"}, ErrInvalidBetween, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "07:00-08:00", Step: "0m"}, ErrInvalidStep, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true}, Between: "07:00-08:00", Step: "often"}, ErrInvalidStep, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true, Walk: true}, Between: "07:00-08:00"}, ErrBestSingleMode, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Walk: true}, Between: "07:00-08:00", Reliable: true}, ErrReliableRequiresDrive, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Drive: true, DepartAt: "08:00"}, Between: "07:00-08:00"}, ErrBestScheduleProvided, nil},
+		{BestCmd{CommuteCmd: CommuteCmd{Transit: true, ArriveBy: "08:00"}, Between: "07:00-08:00"}, ErrBestScheduleProvided, nil},
+	}
+
+	for idx, tt := range tests {
+		tt.b.From = "home"
+		tt.b.To = "work"
+		tt.b.TimeZoner = tz
+
+		err := tt.b.Validate(&conf)
+		if err != tt.expectErr {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.expectErr, err)
+		} else if !reflect.DeepEqual(tt.b.departures, tt.expected) {
+			t.Fatalf("[#%v] Unexpected departures, expected=%v, got=%v", idx, tt.expected, tt.b.departures)
+		}
+	}
+
+	// Reliable requests the traffic range.
+	b := BestCmd{CommuteCmd: CommuteCmd{From: "home", To: "work", Drive: true, TimeZoner: tz}, Between: "07:00-08:00", Reliable: true}
+	if err := b.Validate(&conf); err != nil {
+		t.Fatal(err)
+	} else if !b.opts.TrafficRange {
+		t.Fatal("Expected TrafficRange to be requested")
+	}
+}
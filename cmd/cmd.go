@@ -2,16 +2,38 @@
 package cmd
 
 import (
+	"io"
 	"time"
 
 	"github.com/KyleBanks/commuter/pkg/geo"
 )
 
 // Configuration represents a Commuter configuration, including
-// the Google Maps API Key and location map.
+// the Google Maps API Key, location map and default commute options.
 type Configuration struct {
 	APIKey    string
-	Locations map[string]string
+	Locations map[string]Location
+
+	// Avoid is the default list of route features to avoid when driving, and
+	// LocationAvoid overrides it for commutes to or from a named location.
+	Avoid         string
+	LocationAvoid map[string]string
+
+	// Distance indicates if the distance should be displayed alongside
+	// durations by default, using the configured Units.
+	Distance bool
+	Units    string
+
+	// TransitVia and TransitPrefer are the default transit modes and
+	// routing preference used when commuting by transit.
+	TransitVia    string
+	TransitPrefer string
+
+	// Provider is the name of the routing provider used to estimate durations,
+	// and ProviderSettings are its provider specific settings. Google Maps is
+	// used when no Provider is set.
+	Provider         string
+	ProviderSettings map[string]string `json:",omitempty"`
 }
 
 // NewConfiguration attempts to retrieve a Configuration from a storage Provider.
@@ -57,20 +79,76 @@ type Scanner interface {
 	Text() string
 }
 
+// FileCreator provides the ability to create a file to write to.
+type FileCreator interface {
+	Create(string) (io.WriteCloser, error)
+}
+
 // StorageProvider defines a type that can be used for storage.
 type StorageProvider interface {
 	Load(interface{}) error
 	Save(interface{}) error
 }
 
-// Durationer provides the ability to retrieve the duration between
-// two locations.
+// Durationer provides the ability to retrieve the estimated duration
+// between two locations, departing or arriving at a particular time.
 type Durationer interface {
-	Duration(string, string, geo.TravelMode) (*time.Duration, error)
+	Duration(string, string, geo.TravelMode, geo.Options) (*geo.Estimate, error)
+}
+
+// Geocoder provides the ability to find the places matching an address.
+type Geocoder interface {
+	Geocode(string) ([]geo.Place, error)
+}
+
+// Searcher provides the ability to find the places matching a free text query.
+type Searcher interface {
+	Search(string) ([]geo.Place, error)
+}
+
+// NearbySearcher provides the ability to find the places matching a keyword
+// nearest to a Latitude and Longitude.
+type NearbySearcher interface {
+	Nearby(float64, float64, string) ([]geo.Place, error)
+}
+
+// Capable provides the ability to describe the Capabilities of a routing provider,
+// so that options it doesn't support can be rejected.
+type Capable interface {
+	Capabilities() geo.Capabilities
 }
 
 // Locator provides the ability to retrieve the current location as
-// a Latitude and Longitude.
+// a Position.
 type Locator interface {
-	CurrentLocation() (float64, float64, error)
+	CurrentLocation() (*geo.Position, error)
+}
+
+// ReverseGeocoder provides the ability to find the place at a Latitude
+// and Longitude.
+type ReverseGeocoder interface {
+	ReverseGeocode(float64, float64) (*geo.Place, error)
+}
+
+// TimeZoner provides the ability to find the time zone at a Latitude and
+// Longitude, as of a particular time.
+type TimeZoner interface {
+	Timezone(float64, float64, time.Time) (*time.Location, error)
+}
+
+// Elevator provides the ability to retrieve the elevation profile of a route.
+type Elevator interface {
+	Elevation(geo.Route) (*geo.Profile, error)
+}
+
+// Director provides the ability to retrieve the step by step directions
+// between two locations, departing or arriving at a particular time.
+type Director interface {
+	Directions(string, string, geo.TravelMode, geo.Options) ([]geo.Route, error)
+}
+
+// Matrixer provides the ability to retrieve the estimated durations between
+// each of a set of origins and destinations.
+type Matrixer interface {
+	Matrix([]string, []string, geo.TravelMode, geo.Options) ([][]*geo.Estimate, error)
 }
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/KyleBanks/commuter/pkg/geo"
@@ -20,11 +22,11 @@ func (m *mockIndicator) Indicate(msg string, args ...interface{}) {
 // mock Durationer
 
 type mockDurationer struct {
-	durationFn func(string, string, geo.TravelMode) (*time.Duration, error)
+	durationFn func(string, string, geo.TravelMode, geo.Options) (*geo.Estimate, error)
 }
 
-func (m *mockDurationer) Duration(from, to string, tm geo.TravelMode) (*time.Duration, error) {
-	return m.durationFn(from, to, tm)
+func (m *mockDurationer) Duration(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+	return m.durationFn(from, to, tm, o)
 }
 
 // mock StorageProvider
@@ -45,9 +47,127 @@ func (m *mockStorageProvider) Save(i interface{}) error {
 // mock Locator
 
 type mockLocator struct {
-	locateFn func() (float64, float64, error)
+	locateFn func() (*geo.Position, error)
 }
 
-func (m *mockLocator) CurrentLocation() (float64, float64, error) {
+func (m *mockLocator) CurrentLocation() (*geo.Position, error) {
 	return m.locateFn()
 }
+
+// mock Director
+
+type mockDirector struct {
+	directionsFn func(string, string, geo.TravelMode, geo.Options) ([]geo.Route, error)
+}
+
+func (m *mockDirector) Directions(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+	return m.directionsFn(from, to, tm, o)
+}
+
+// mock Elevator
+
+type mockElevator struct {
+	elevationFn func(geo.Route) (*geo.Profile, error)
+}
+
+func (m *mockElevator) Elevation(r geo.Route) (*geo.Profile, error) {
+	return m.elevationFn(r)
+}
+
+// mock FileCreator
+
+type mockFile struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (m *mockFile) Close() error {
+	m.closed = true
+	return nil
+}
+
+type mockFileCreator struct {
+	createFn func(string) (io.WriteCloser, error)
+}
+
+func (m *mockFileCreator) Create(name string) (io.WriteCloser, error) {
+	return m.createFn(name)
+}
+
+// mock Matrixer
+
+type mockMatrixer struct {
+	matrixFn func([]string, []string, geo.TravelMode, geo.Options) ([][]*geo.Estimate, error)
+}
+
+func (m *mockMatrixer) Matrix(from, to []string, tm geo.TravelMode, o geo.Options) ([][]*geo.Estimate, error) {
+	return m.matrixFn(from, to, tm, o)
+}
+
+// mock Geocoder
+
+type mockGeocoder struct {
+	geocodeFn func(string) ([]geo.Place, error)
+}
+
+func (m *mockGeocoder) Geocode(address string) ([]geo.Place, error) {
+	return m.geocodeFn(address)
+}
+
+// mock Searcher
+
+type mockSearcher struct {
+	searchFn func(string) ([]geo.Place, error)
+}
+
+func (m *mockSearcher) Search(query string) ([]geo.Place, error) {
+	return m.searchFn(query)
+}
+
+// mock NearbySearcher
+
+type mockNearbySearcher struct {
+	nearbyFn func(float64, float64, string) ([]geo.Place, error)
+}
+
+func (m *mockNearbySearcher) Nearby(lat, lng float64, keyword string) ([]geo.Place, error) {
+	return m.nearbyFn(lat, lng, keyword)
+}
+
+// mock Scanner
+
+type mockScanner struct {
+	lines []string
+	text  string
+}
+
+func (m *mockScanner) Scan() bool {
+	if len(m.lines) == 0 {
+		return false
+	}
+
+	m.text, m.lines = m.lines[0], m.lines[1:]
+	return true
+}
+
+func (m *mockScanner) Text() string {
+	return m.text
+}
+
+// mock ReverseGeocoder
+
+type mockReverseGeocoder struct {
+	reverseFn func(float64, float64) (*geo.Place, error)
+}
+
+func (m *mockReverseGeocoder) ReverseGeocode(lat, lng float64) (*geo.Place, error) {
+	return m.reverseFn(lat, lng)
+}
+
+type mockTimeZoner struct {
+	timezoneFn func(float64, float64, time.Time) (*time.Location, error)
+}
+
+func (m *mockTimeZoner) Timezone(lat, lng float64, at time.Time) (*time.Location, error) {
+	return m.timezoneFn(lat, lng, at)
+}
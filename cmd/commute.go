@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +14,17 @@ const (
 	// DefaultLocationAlias is the name of the default alias
 	// used for 'From' addresses when one is not provided.
 	DefaultLocationAlias = "default"
+
+	// DefaultAvoid is the list of route features avoided when driving
+	// if no other preference is provided.
+	DefaultAvoid = "tolls"
+
+	// DefaultUnits is the unit system used to display distances
+	// if no other preference is provided.
+	DefaultUnits = "metric"
+
+	metersPerKilometer = 1000
+	metersPerMile      = 1609.344
 )
 
 var (
@@ -27,10 +39,38 @@ var (
 	ErrFromAndFromCurrentProvided = errors.New("cannot use -from and -from-current arguments")
 	// ErrToAndToCurrentProvided is returned when the -to and -to-current arguments are both supplied.
 	ErrToAndToCurrentProvided = errors.New("cannot use -to and -to-current arguments")
+	// ErrLocationUnavailable is returned when the -from-current or -to-current arguments are used without a Locator.
+	ErrLocationUnavailable = errors.New("the current location is unavailable, configure a Google Maps API key to use -from-current and -to-current")
+
+	// ErrDepartAtAndArriveByProvided is returned when the -depart-at and -arrive-by arguments are both supplied.
+	ErrDepartAtAndArriveByProvided = errors.New("cannot use -depart-at and -arrive-by arguments")
+	// ErrArriveByRequiresTransit is returned when the -arrive-by argument is used with a commute method other than transit.
+	ErrArriveByRequiresTransit = errors.New("-arrive-by can only be used with the -transit commute method")
+	// ErrRangeRequiresDrive is returned when the -range argument is used without the drive commute method.
+	ErrRangeRequiresDrive = errors.New("-range can only be used with the -drive commute method")
+	// ErrAvoidRequiresDrive is returned when the -avoid argument is used without the drive commute method.
+	ErrAvoidRequiresDrive = errors.New("-avoid can only be used with the -drive commute method")
+	// ErrTransitOptionsRequireTransit is returned when the -transit-via or -transit-prefer arguments are used without the transit commute method.
+	ErrTransitOptionsRequireTransit = errors.New("-transit-via and -transit-prefer can only be used with the -transit commute method")
+	// ErrRangeAndAlternativesProvided is returned when the -range and -alternatives arguments are both supplied.
+	ErrRangeAndAlternativesProvided = errors.New("cannot use -range and -alternatives arguments")
+	// ErrViaAndRangeProvided is returned when the -via and -range arguments are both supplied.
+	ErrViaAndRangeProvided = errors.New("cannot use -via and -range arguments")
+	// ErrViaAndAlternativesProvided is returned when the -via and -alternatives arguments are both supplied.
+	ErrViaAndAlternativesProvided = errors.New("cannot use -via and -alternatives arguments")
+	// ErrViaNotSupportedByTransit is returned when the -via argument is used with the transit commute method.
+	ErrViaNotSupportedByTransit = errors.New("-via cannot be used with the -transit commute method")
+	// ErrOptimizeRequiresVia is returned when the -optimize argument is used without the -via argument.
+	ErrOptimizeRequiresVia = errors.New("-optimize can only be used with the -via argument")
+	// ErrElevationRequiresBikeOrWalk is returned when the -elevation argument is used without the bike or walk commute methods.
+	ErrElevationRequiresBikeOrWalk = errors.New("-elevation can only be used with the -bike or -walk commute methods")
 )
 
 // CommuteCmd represents the standard command to
 // retrieve the commute time between two locations.
+//
+// Distance is a pointer so that it can be explicitly disabled, overriding the
+// configured default, and is nil when the default should be used.
 type CommuteCmd struct {
 	From        string
 	FromCurrent bool
@@ -43,8 +83,60 @@ type CommuteCmd struct {
 	Bike    bool
 	Transit bool
 
-	Durationer Durationer
-	Locator    Locator
+	DepartAt string
+	ArriveBy string
+	Range    bool
+	Avoid    string
+	Distance *bool
+	Units    string
+
+	TransitVia    string
+	TransitPrefer string
+
+	Alternatives bool
+
+	Via      []string
+	Optimize bool
+
+	Elevation bool
+
+	// Offline uses the Estimator in place of the Durationer, which is also used
+	// when the Durationer fails.
+	Offline   bool
+	Estimator Durationer
+
+	Durationer      Durationer
+	Director        Director
+	Locator         Locator
+	ReverseGeocoder ReverseGeocoder
+	Geocoder        Geocoder
+	TimeZoner       TimeZoner
+	Elevator        Elevator
+	NearbySearcher  NearbySearcher
+	Matrixer        Matrixer
+	Store           StorageProvider
+
+	opts geo.Options
+	// distance indicates the distance should be displayed, from the Distance
+	// argument or the configured default.
+	distance bool
+	// here is the description of the current location, and position its
+	// coordinates, when it's used.
+	here     string
+	position *geo.Position
+	// nearby is the description of the place a nearest: location resolved to.
+	nearby string
+	// fromZone and toZone are the time zones of the From and To locations,
+	// which are only determined when a departure or arrival time is provided.
+	fromZone *time.Location
+	toZone   *time.Location
+	// zoned indicates the time zones should be determined even without a
+	// departure or arrival time.
+	zoned bool
+	// fromPosition and toPosition are the known coordinates of the From and To
+	// locations, used by the Estimator when the Durationer fails.
+	fromPosition *geo.Position
+	toPosition   *geo.Position
 }
 
 // Run calculates the distance between the From and To locations,
@@ -53,24 +145,163 @@ func (c *CommuteCmd) Run(conf *Configuration, i Indicator) error {
 	modes := c.modes()
 	multiMode := len(modes) > 1
 
+	if len(c.here) > 0 {
+		i.Indicate("Current location: %v", c.here)
+	}
+	if len(c.nearby) > 0 {
+		i.Indicate("%v", c.nearby)
+	}
+	if s := c.describeSchedule(); len(s) > 0 {
+		i.Indicate("%v", s)
+	}
+
 	for _, m := range modes {
-		d, err := c.Durationer.Duration(c.From, c.To, m)
-		if err != nil {
+		if err := c.commute(m, multiMode, i); err != nil {
 			return err
 		}
 
-		var method string
+		if c.Elevation && (m == geo.Bike || m == geo.Walk) {
+			if err := c.profile(m, i); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// commute outputs the commute for a single TravelMode, as legs when stopping
+// along the way, as a comparison of alternative routes, or as a single duration.
+func (c *CommuteCmd) commute(m geo.TravelMode, multiMode bool, i Indicator) error {
+	if len(c.Via) > 0 {
+		if multiMode {
+			i.Indicate("%v:", m)
+		}
+		return c.legs(m, i)
+	}
+
+	if c.Alternatives {
 		if multiMode {
-			method = fmt.Sprintf("%v: ", m)
+			i.Indicate("%v:", m)
+		}
+		_, err := c.alternatives(m, i)
+		return err
+	}
+
+	e, err := c.duration(m, c.opts)
+	if err != nil {
+		return err
+	}
+
+	var method string
+	if multiMode {
+		method = fmt.Sprintf("%v: ", m)
+	}
+	i.Indicate("%v%v", method, c.format(e))
+
+	width := len(fmt.Sprintf("%v", len(e.Steps)))
+	for n, step := range e.Steps {
+		i.Indicate("  %*d. %v (%v)", width, n+1, step.Instructions, c.formatStep(step))
+
+		if t := step.Transit; t != nil {
+			indent := strings.Repeat(" ", width+4)
+			for _, line := range c.describeTransit(t) {
+				i.Indicate("%v%v", indent, line)
+			}
 		}
-		i.Indicate("%v%v", method, c.format(*d))
 	}
 
 	return nil
 }
 
-// format takes a duration and returns a formatter representation.
-func (c *CommuteCmd) format(d time.Duration) string {
+// format takes an Estimate and returns a formatted representation, such as
+// "32 Minutes" or "28–41 Minutes (likely 33)" for traffic ranges.
+func (c *CommuteCmd) format(e *geo.Estimate) string {
+	var out string
+	var notes []string
+
+	switch {
+	case e.IsRange() && e.Pessimistic < time.Hour:
+		out = fmt.Sprintf("%v–%v Minutes", int(e.Optimistic.Minutes()), int(e.Pessimistic.Minutes()))
+		notes = append(notes, fmt.Sprintf("likely %v", int(e.Duration.Minutes())))
+	case e.IsRange():
+		out = fmt.Sprintf("%v – %v", c.formatDuration(e.Optimistic), c.formatDuration(e.Pessimistic))
+		notes = append(notes, fmt.Sprintf("likely %v", c.formatDuration(e.Duration)))
+	default:
+		out = c.formatDuration(e.Duration)
+		if e.Traffic {
+			notes = append(notes, "in traffic")
+		}
+	}
+
+	if e.Offline {
+		notes = append(notes, "estimated offline")
+	}
+
+	if len(e.Steps) > 0 {
+		switch e.Transfers {
+		case 0:
+			notes = append(notes, "no transfers")
+		case 1:
+			notes = append(notes, "1 transfer")
+		default:
+			notes = append(notes, fmt.Sprintf("%v transfers", e.Transfers))
+		}
+	}
+
+	if c.distance && e.Distance > 0 {
+		notes = append(notes, c.formatDistance(e.Distance))
+		if e.Duration > 0 {
+			notes = append(notes, c.formatSpeed(e.Distance, e.Duration))
+		}
+	}
+
+	if len(e.Avoided) > 0 {
+		avoided := make([]string, len(e.Avoided))
+		for i, a := range e.Avoided {
+			avoided[i] = string(a)
+		}
+		notes = append(notes, fmt.Sprintf("avoiding %v", joinAnd(avoided)))
+	}
+
+	if len(notes) > 0 {
+		out = fmt.Sprintf("%v (%v)", out, strings.Join(notes, ", "))
+	}
+
+	return out
+}
+
+// formatDistance takes a distance in meters and returns a formatted representation
+// in the requested Units, such as "24.1 km" or "15.0 mi".
+func (c *CommuteCmd) formatDistance(meters int) string {
+	return formatDistance(meters, c.opts.Units)
+}
+
+// formatDistance takes a distance in meters and returns a formatted representation
+// in the Units provided.
+func formatDistance(meters int, u geo.Units) string {
+	if u == geo.Imperial {
+		return fmt.Sprintf("%.1f mi", float64(meters)/metersPerMile)
+	}
+
+	if meters < metersPerKilometer {
+		return fmt.Sprintf("%v m", meters)
+	}
+	return fmt.Sprintf("%.1f km", float64(meters)/metersPerKilometer)
+}
+
+// formatSpeed takes a distance in meters and the duration it takes to travel, and returns
+// a formatted representation of the average speed in the requested Units, such as "45 km/h avg".
+func (c *CommuteCmd) formatSpeed(meters int, d time.Duration) string {
+	if c.opts.Units == geo.Imperial {
+		return fmt.Sprintf("%.0f mph avg", float64(meters)/metersPerMile/d.Hours())
+	}
+
+	return fmt.Sprintf("%.0f km/h avg", float64(meters)/metersPerKilometer/d.Hours())
+}
+
+// formatDuration takes a duration and returns a formatted representation.
+func (c *CommuteCmd) formatDuration(d time.Duration) string {
 	pluralize := func(s string, i int) string {
 		if i != 1 {
 			s += "s"
@@ -92,6 +323,22 @@ func (c *CommuteCmd) format(d time.Duration) string {
 	return strings.Join(out, " ")
 }
 
+// describeSchedule returns a description of the departure or arrival time
+// the commute is being calculated for, or an empty string when departing now.
+//
+// Departure times are shown in the origin's time zone and arrival times in the
+// destination's, along with the other when they differ.
+func (c *CommuteCmd) describeSchedule() string {
+	switch {
+	case !c.opts.DepartAt.IsZero():
+		return fmt.Sprintf("Departing %v", describeTime(c.opts.DepartAt, c.fromZone, c.toZone, "destination"))
+	case !c.opts.ArriveBy.IsZero():
+		return fmt.Sprintf("Arriving by %v", describeTime(c.opts.ArriveBy, c.toZone, c.fromZone, "origin"))
+	}
+
+	return ""
+}
+
 // modes returns a slice of TravelModes based on the commands Drive, Walk, Bike and Transit properties.
 func (c *CommuteCmd) modes() []geo.TravelMode {
 	var modes []geo.TravelMode
@@ -113,11 +360,64 @@ func (c *CommuteCmd) modes() []geo.TravelMode {
 
 // Validate validates the CommuteCmd is properly initialized and ready to be Run.
 func (c *CommuteCmd) Validate(conf *Configuration) (err error) {
+	fromName, toName := c.From, c.To
+
 	// Must provide at least one method of transport
 	if !c.Drive && !c.Walk && !c.Bike && !c.Transit {
 		return ErrNoCommuteMethod
 	}
 
+	if err = c.offline(); err != nil {
+		return
+	}
+	if err = c.capable(); err != nil {
+		return
+	}
+
+	c.opts, err = c.parseSchedule()
+	if err != nil {
+		return
+	}
+
+	if c.Range && !c.Drive {
+		return ErrRangeRequiresDrive
+	}
+	c.opts.TrafficRange = c.Range
+
+	if c.Range && c.Alternatives {
+		return ErrRangeAndAlternativesProvided
+	}
+	c.opts.Alternatives = c.Alternatives
+
+	c.opts.Waypoints, err = c.stops(conf)
+	if err != nil {
+		return
+	}
+	c.opts.Optimize = c.Optimize
+
+	if c.Elevation && !c.Bike && !c.Walk {
+		return ErrElevationRequiresBikeOrWalk
+	}
+
+	c.opts.Avoid, c.opts.DefaultAvoid, err = c.avoid(conf)
+	if err != nil {
+		return
+	}
+
+	c.distance = conf.Distance
+	if c.Distance != nil {
+		c.distance = *c.Distance
+	}
+	c.opts.Units, err = c.units(conf)
+	if err != nil {
+		return
+	}
+
+	c.opts.TransitModes, c.opts.TransitPreference, err = c.transit(conf)
+	if err != nil {
+		return
+	}
+
 	c.From, err = c.setLocation(conf, c.From, c.FromCurrent, ErrFromAndFromCurrentProvided, ErrDefaultFromMissing)
 	if err != nil {
 		return
@@ -128,13 +428,127 @@ func (c *CommuteCmd) Validate(conf *Configuration) (err error) {
 		return
 	}
 
+	if err = c.resolveNearest(conf, fromName, toName); err != nil {
+		return
+	}
+
+	if err = c.localize(conf, fromName, toName); err != nil {
+		return
+	}
+
+	if err = c.supported(conf, fromName, toName); err != nil {
+		return
+	}
+
+	c.fallback(conf, fromName, toName)
+	return nil
+}
+
+// parseSchedule validates and parses the DepartAt and ArriveBy times into Options.
+//
+// Arrival times are only supported by the transit commute method, and times are checked
+// to be in the future by localize instead when a TimeZoner is available.
+func (c *CommuteCmd) parseSchedule() (o geo.Options, err error) {
+	if len(c.DepartAt) > 0 && len(c.ArriveBy) > 0 {
+		return o, ErrDepartAtAndArriveByProvided
+	}
+
+	if len(c.DepartAt) > 0 {
+		o.DepartAt, err = parseTime(c.DepartAt, now())
+	}
+
+	if len(c.ArriveBy) > 0 {
+		if c.Drive || c.Walk || c.Bike {
+			return o, ErrArriveByRequiresTransit
+		}
+
+		o.ArriveBy, err = parseTime(c.ArriveBy, now())
+	}
+
+	// Times are only in the past in the time zone of their location, which is
+	// determined later when there's a TimeZoner.
+	if err == ErrTimeInPast && c.TimeZoner != nil {
+		err = nil
+	}
+
+	return
+}
+
+// avoid determines the route features to avoid, preferring the Avoid argument, followed by
+// overrides for the named To and From locations, the configured default, and finally the
+// DefaultAvoid, in which case fallback is true.
+func (c *CommuteCmd) avoid(conf *Configuration) (avoid []geo.Avoid, fallback bool, err error) {
+	if len(c.Avoid) > 0 && !c.Drive {
+		return nil, false, ErrAvoidRequiresDrive
+	}
+
+	value := c.Avoid
+	if len(value) == 0 && !c.ToCurrent {
+		value = conf.LocationAvoid[c.To]
+	}
+	if len(value) == 0 && !c.FromCurrent {
+		value = conf.LocationAvoid[c.From]
+	}
+	if len(value) == 0 {
+		value = conf.Avoid
+	}
+	if len(value) == 0 {
+		value = DefaultAvoid
+		fallback = true
+	}
+
+	avoid, err = geo.ParseAvoid(value)
+	return avoid, fallback, err
+}
+
+// units determines the unit system to display distances in, preferring the Units argument
+// followed by the configured default.
+func (c *CommuteCmd) units(conf *Configuration) (geo.Units, error) {
+	value := c.Units
+	if len(value) == 0 {
+		value = conf.Units
+	}
+	if len(value) == 0 {
+		value = DefaultUnits
+	}
+
+	return geo.ParseUnits(value)
+}
+
+// transit determines the transit modes and routing preference, preferring the TransitVia and
+// TransitPrefer arguments followed by the configured defaults.
+func (c *CommuteCmd) transit(conf *Configuration) (modes []geo.TransitMode, pref geo.TransitPreference, err error) {
+	if (len(c.TransitVia) > 0 || len(c.TransitPrefer) > 0) && !c.Transit {
+		return nil, "", ErrTransitOptionsRequireTransit
+	}
+
+	via := c.TransitVia
+	if len(via) == 0 {
+		via = conf.TransitVia
+	}
+	if len(via) > 0 {
+		modes, err = geo.ParseTransitModes(via)
+		if err != nil {
+			return
+		}
+	}
+
+	prefer := c.TransitPrefer
+	if len(prefer) == 0 {
+		prefer = conf.TransitPrefer
+	}
+	if len(prefer) > 0 {
+		pref, err = geo.ParseTransitPreference(prefer)
+	}
+
 	return
 }
 
 // setLocation validates and determines a location based on the provided value and the `useCurrent` flag.
 //
 // If the useCurrent flag is true, setLocation will attempt to use geolocation to determine the current location. Otherwise,
-// it will check if the value is an alias, or use the actual value provided.
+// it will check if the value is an alias, or use the actual value provided. A nearest: location is returned as provided,
+// and resolved by resolveNearest once the location it's relative to is known.
 func (c *CommuteCmd) setLocation(conf *Configuration, value string, useCurrent bool, bothProvided error, missing error) (string, error) {
 	if useCurrent && len(value) > 0 && value != DefaultLocationAlias {
 		return "", bothProvided
@@ -166,20 +580,113 @@ func (c *CommuteCmd) alias(conf *Configuration, value string) string {
 		return value
 	}
 
-	return val
+	return val.Address
 }
 
-// locate attempts to return a latitude/longitude string for the user's current location.
+// locate attempts to return a latitude/longitude string for the user's current location,
+// and describes it as a readable address for display.
 func (c *CommuteCmd) locate(conf *Configuration) (string, error) {
-	lat, long, err := c.Locator.CurrentLocation()
+	if c.Locator == nil {
+		return "", ErrLocationUnavailable
+	}
+
+	pos, err := c.Locator.CurrentLocation()
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%v,%v", lat, long), nil
+	c.here = describePosition(c.ReverseGeocoder, pos)
+	c.position = pos
+	return pos.String(), nil
+}
+
+// coordinates returns the Latitude and Longitude of a location, using the known coordinates
+// when available, and otherwise geocoding the address.
+//
+// The name is the value provided by the user, before any aliases were resolved.
+func (c *CommuteCmd) coordinates(conf *Configuration, name, address string, current bool) (lat, lng float64, ok bool) {
+	if lat, lng, ok = c.knownCoordinates(conf, name, address, current); ok || c.Geocoder == nil {
+		return
+	}
+
+	places, err := c.Geocoder.Geocode(address)
+	if err != nil || len(places) == 0 {
+		return
+	}
+
+	return places[0].Lat, places[0].Lng, true
+}
+
+// knownCoordinates returns the Latitude and Longitude of a location without a network request,
+// using the current location or the coordinates stored for a named location, or parsing a
+// "lat,lng" address.
+func (c *CommuteCmd) knownCoordinates(conf *Configuration, name, address string, current bool) (lat, lng float64, ok bool) {
+	if current && c.position != nil {
+		return c.position.Lat, c.position.Lng, true
+	}
+	if loc, named := conf.Locations[name]; named && !current && (loc.Lat != 0 || loc.Lng != 0) {
+		return loc.Lat, loc.Lng, true
+	}
+	if lat, lng, err := parseLatLng(address); err == nil {
+		return lat, lng, true
+	}
+
+	return
+}
+
+// parseLatLng parses a "lat,lng" string, such as the current location, into
+// a Latitude and Longitude.
+func parseLatLng(s string) (lat, lng float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, geo.ErrBadLocation
+	}
+
+	if lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil {
+		return
+	}
+	lng, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	return
+}
+
+// describePosition returns a readable description of a Position, such as
+// "123 Main St, Toronto (within 40 m)", falling back to its coordinates when
+// it can't be reverse geocoded.
+func describePosition(r ReverseGeocoder, p *geo.Position) string {
+	desc := p.String()
+	if r != nil {
+		if place, err := r.ReverseGeocode(p.Lat, p.Lng); err == nil {
+			desc = place.Address
+		}
+	}
+
+	if p.Accuracy > 0 {
+		desc = fmt.Sprintf("%v (within %.0f m)", desc, p.Accuracy)
+	}
+
+	return desc
+}
+
+// joinAnd joins a list of strings in a readable sentence form, such as "a, b and c".
+func joinAnd(s []string) string {
+	if len(s) < 2 {
+		return strings.Join(s, "")
+	}
+
+	return strings.Join(s[:len(s)-1], ", ") + " and " + s[len(s)-1]
 }
 
 // String returns a string representation of the CommuteCmd.
+//
+// The current location is described by its address rather than its coordinates.
 func (c *CommuteCmd) String() string {
-	return fmt.Sprintf("From '%v' to '%v'", c.From, c.To)
+	from, to := c.From, c.To
+	if c.FromCurrent && len(c.here) > 0 {
+		from = c.here
+	}
+	if c.ToCurrent && len(c.here) > 0 {
+		to = c.here
+	}
+
+	return fmt.Sprintf("From '%v' to '%v'", from, to)
 }
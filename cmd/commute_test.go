@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -22,9 +23,9 @@ func TestCommuteCmd_Run(t *testing.T) {
 	}
 
 	for idx, tt := range tests {
-		d := time.Minute * 3
+		d := geo.Estimate{Duration: time.Minute * 3}
 		m := mockDurationer{
-			durationFn: func(from, to string, tm geo.TravelMode) (*time.Duration, error) {
+			durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
 				if from != tt.from {
 					t.Fatalf("[#%v] Unexpected From, expected=%v, got=%v", idx, tt.from, from)
 				} else if to != tt.to {
@@ -44,8 +45,8 @@ func TestCommuteCmd_Run(t *testing.T) {
 
 		if len(i.out) != 1 {
 			t.Fatalf("[#%v] Unexpected number of output lines, expected=%v, got=%v", idx, 1, i.out)
-		} else if i.out[0] != c.format(d) {
-			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, c.format(d), i.out[0])
+		} else if i.out[0] != c.format(&d) {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, c.format(&d), i.out[0])
 		}
 	}
 
@@ -82,7 +83,7 @@ func TestCommuteCmd_Run(t *testing.T) {
 		}
 
 		m := mockDurationer{
-			durationFn: func(from, to string, tm geo.TravelMode) (*time.Duration, error) {
+			durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
 				if tm == geo.Drive && !tt.drive {
 					t.Fatalf("[#%v] Unexpected Mode, Drive", idx)
 				} else if tm == geo.Walk && !tt.walk {
@@ -93,8 +94,7 @@ func TestCommuteCmd_Run(t *testing.T) {
 					t.Fatalf("[#%v] Unexpected Mode, Transit", idx)
 				}
 
-				d := time.Minute * 3
-				return &d, nil
+				return &geo.Estimate{Duration: time.Minute * 3}, nil
 			},
 		}
 
@@ -110,11 +110,101 @@ func TestCommuteCmd_Run(t *testing.T) {
 		}
 	}
 
+	// Positive, Schedule
+	{
+		at := time.Date(2017, time.May, 1, 8, 15, 0, 0, time.UTC)
+		sTests := []struct {
+			opts   geo.Options
+			header string
+		}{
+			{geo.Options{}, ""},
+			{geo.Options{DepartAt: at}, "Departing Mon May 1 08:15"},
+			{geo.Options{ArriveBy: at}, "Arriving by Mon May 1 08:15"},
+		}
+
+		for idx, tt := range sTests {
+			m := mockDurationer{
+				durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+					if !reflect.DeepEqual(o, tt.opts) {
+						t.Fatalf("[#%v] Unexpected Options, expected=%v, got=%v", idx, tt.opts, o)
+					}
+
+					return &geo.Estimate{Duration: time.Minute * 3}, nil
+				},
+			}
+
+			c := CommuteCmd{From: "from", To: "to", Transit: true, Durationer: &m, opts: tt.opts}
+			var conf Configuration
+			var i mockIndicator
+			if err := c.Run(&conf, &i); err != nil {
+				t.Fatal(err)
+			}
+
+			expect := []string{c.format(&geo.Estimate{Duration: time.Minute * 3})}
+			if len(tt.header) > 0 {
+				expect = append([]string{tt.header}, expect...)
+			}
+
+			if len(i.out) != len(expect) {
+				t.Fatalf("[#%v] Unexpected number of output lines, expected=%v, got=%v", idx, expect, i.out)
+			}
+			for line := range expect {
+				if i.out[line] != expect[line] {
+					t.Fatalf("[#%v] [Line %v] Unexpected output, expected=%v, got=%v", idx, line, expect[line], i.out[line])
+				}
+			}
+		}
+	}
+
+	// Positive, Transit Steps
+	{
+		at := time.Date(2017, time.May, 1, 8, 0, 0, 0, time.UTC)
+		e := geo.Estimate{
+			Duration:  time.Minute * 22,
+			Distance:  3876,
+			Transfers: 1,
+			Steps: []geo.Step{
+				{Instructions: "Walk to King St West at Bay St", Distance: 18, Duration: time.Second * 13},
+				{Instructions: "Tram towards Broadview Station", Distance: 1609, Duration: time.Minute * 10, Transit: &geo.TransitDetails{
+					Line: "504 King", Vehicle: "Tram", Headsign: "Broadview Station",
+					DepartureStop: "King St West at Bay St", DepartureTime: at,
+					ArrivalStop: "King St East at Parliament St", ArrivalTime: at.Add(time.Minute * 10), NumStops: 2,
+				}},
+				{Instructions: "Walk to destination", Distance: 2249, Duration: time.Minute * 11},
+			},
+		}
+		m := mockDurationer{
+			durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+				return &e, nil
+			},
+		}
+
+		c := CommuteCmd{From: "from", To: "to", Transit: true, Durationer: &m}
+		var conf Configuration
+		var i mockIndicator
+		if err := c.Run(&conf, &i); err != nil {
+			t.Fatal(err)
+		}
+
+		expect := []string{
+			"22 Minutes (1 transfer)",
+			"  1. Walk to King St West at Bay St (18 m, < 1 Minute)",
+			"  2. Tram towards Broadview Station (1.6 km, 10 Minutes)",
+			"     Tram 504 King towards Broadview Station",
+			"     Depart King St West at Bay St at 08:00",
+			"     Arrive King St East at Parliament St at 08:10 (2 stops)",
+			"  3. Walk to destination (2.2 km, 11 Minutes)",
+		}
+		if !reflect.DeepEqual(i.out, expect) {
+			t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+		}
+	}
+
 	// Negative
 	{
 		testErr := errors.New("mock error")
 		m := mockDurationer{
-			durationFn: func(from, to string, tm geo.TravelMode) (*time.Duration, error) {
+			durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
 				return nil, testErr
 			},
 		}
@@ -129,6 +219,53 @@ func TestCommuteCmd_Run(t *testing.T) {
 }
 
 func TestCommuteCmd_format(t *testing.T) {
+	tests := []struct {
+		e        geo.Estimate
+		expected string
+	}{
+		{geo.Estimate{Duration: time.Minute * 32}, "32 Minutes"},
+		{geo.Estimate{Duration: time.Minute * 32, Traffic: true}, "32 Minutes (in traffic)"},
+		{geo.Estimate{Duration: time.Minute * 33, Optimistic: time.Minute * 28, Pessimistic: time.Minute * 41, Traffic: true}, "28–41 Minutes (likely 33)"},
+		{geo.Estimate{Duration: time.Minute * 62, Optimistic: time.Minute * 50, Pessimistic: time.Minute * 70, Traffic: true}, "50 Minutes – 1 Hour 10 Minutes (likely 1 Hour 2 Minutes)"},
+		{geo.Estimate{Duration: time.Minute * 32, Avoided: []geo.Avoid{geo.AvoidTolls}}, "32 Minutes (avoiding tolls)"},
+		{geo.Estimate{Duration: time.Minute * 32, Traffic: true, Avoided: []geo.Avoid{geo.AvoidTolls, geo.AvoidHighways, geo.AvoidFerries}}, "32 Minutes (in traffic, avoiding tolls, highways and ferries)"},
+		{geo.Estimate{Duration: time.Minute * 33, Optimistic: time.Minute * 28, Pessimistic: time.Minute * 41, Traffic: true, Avoided: []geo.Avoid{geo.AvoidHighways}}, "28–41 Minutes (likely 33, avoiding highways)"},
+		{geo.Estimate{Duration: time.Minute * 32, Steps: []geo.Step{{}}}, "32 Minutes (no transfers)"},
+		{geo.Estimate{Duration: time.Minute * 32, Transfers: 2, Steps: []geo.Step{{}}}, "32 Minutes (2 transfers)"},
+	}
+
+	var c CommuteCmd
+	for idx, tt := range tests {
+		out := c.format(&tt.e)
+		if out != tt.expected {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expected, out)
+		}
+	}
+
+	// Distance
+	dTests := []struct {
+		e        geo.Estimate
+		units    geo.Units
+		expected string
+	}{
+		{geo.Estimate{Duration: time.Minute * 32}, geo.Metric, "32 Minutes"},
+		{geo.Estimate{Duration: time.Minute * 32, Distance: 24100}, geo.Metric, "32 Minutes (24.1 km, 45 km/h avg)"},
+		{geo.Estimate{Duration: time.Minute * 10, Distance: 850}, geo.Metric, "10 Minutes (850 m, 5 km/h avg)"},
+		{geo.Estimate{Duration: time.Minute * 32, Distance: 24100}, geo.Imperial, "32 Minutes (15.0 mi, 28 mph avg)"},
+		{geo.Estimate{Distance: 24100}, geo.Metric, "0 Minutes (24.1 km)"},
+		{geo.Estimate{Duration: time.Minute * 33, Optimistic: time.Minute * 28, Pessimistic: time.Minute * 41, Distance: 24100, Traffic: true, Avoided: []geo.Avoid{geo.AvoidTolls}}, geo.Metric, "28–41 Minutes (likely 33, 24.1 km, 44 km/h avg, avoiding tolls)"},
+	}
+
+	for idx, tt := range dTests {
+		c := CommuteCmd{distance: true, opts: geo.Options{Units: tt.units}}
+		out := c.format(&tt.e)
+		if out != tt.expected {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expected, out)
+		}
+	}
+}
+
+func TestCommuteCmd_formatDuration(t *testing.T) {
 	tests := []struct {
 		hours   int
 		minutes int
@@ -145,7 +282,7 @@ func TestCommuteCmd_format(t *testing.T) {
 	for _, tt := range tests {
 		d := (time.Hour * time.Duration(tt.hours)) + (time.Minute * time.Duration(tt.minutes))
 
-		out := c.format(d)
+		out := c.formatDuration(d)
 		if out != tt.expected {
 			t.Fatalf("Unexpected output, expected=%v, got=%v", tt.expected, out)
 		}
@@ -155,7 +292,7 @@ func TestCommuteCmd_format(t *testing.T) {
 func TestCommuteCmd_Validate(t *testing.T) {
 	// From/To
 	tests := []struct {
-		locs        map[string]string
+		locs        map[string]Location
 		from        string
 		fromCurrent bool
 		to          string
@@ -166,27 +303,27 @@ func TestCommuteCmd_Validate(t *testing.T) {
 		expectTo   string
 	}{
 		// Positive
-		{map[string]string{}, "from", false, "to", false, nil, "from", "to"},
-		{map[string]string{"from": "fromvalue"}, "from", false, "to", false, nil, "fromvalue", "to"},
-		{map[string]string{"to": "tovalue"}, "from", false, "to", false, nil, "from", "tovalue"},
-		{map[string]string{"from": "fromvalue", "to": "tovalue"}, "from", false, "to", false, nil, "fromvalue", "tovalue"},
-		{map[string]string{}, "", true, "to", false, nil, "", "to"},
-		{map[string]string{}, "from", false, "", true, nil, "from", ""},
-		{map[string]string{}, "", true, "", true, nil, "", ""},
+		{map[string]Location{}, "from", false, "to", false, nil, "from", "to"},
+		{map[string]Location{"from": {Address: "fromvalue"}}, "from", false, "to", false, nil, "fromvalue", "to"},
+		{map[string]Location{"to": {Address: "tovalue"}}, "from", false, "to", false, nil, "from", "tovalue"},
+		{map[string]Location{"from": {Address: "fromvalue"}, "to": {Address: "tovalue"}}, "from", false, "to", false, nil, "fromvalue", "tovalue"},
+		{map[string]Location{}, "", true, "to", false, nil, "", "to"},
+		{map[string]Location{}, "from", false, "", true, nil, "from", ""},
+		{map[string]Location{}, "", true, "", true, nil, "", ""},
 
 		// Negative
-		{map[string]string{}, "", false, "to", false, ErrDefaultFromMissing, "", ""},
-		{map[string]string{}, "from", false, "", false, ErrDefaultToMissing, "", ""},
-		{map[string]string{}, "from", true, "", false, ErrFromAndFromCurrentProvided, "", ""},
-		{map[string]string{}, "from", false, "to", true, ErrToAndToCurrentProvided, "", ""},
-		{map[string]string{"from": ""}, "from", false, "to", false, ErrDefaultFromMissing, "", ""},
-		{map[string]string{"to": ""}, "from", false, "to", false, ErrDefaultToMissing, "", ""},
+		{map[string]Location{}, "", false, "to", false, ErrDefaultFromMissing, "", ""},
+		{map[string]Location{}, "from", false, "", false, ErrDefaultToMissing, "", ""},
+		{map[string]Location{}, "from", true, "", false, ErrFromAndFromCurrentProvided, "", ""},
+		{map[string]Location{}, "from", false, "to", true, ErrToAndToCurrentProvided, "", ""},
+		{map[string]Location{"from": {Address: ""}}, "from", false, "to", false, ErrDefaultFromMissing, "", ""},
+		{map[string]Location{"to": {Address: ""}}, "from", false, "to", false, ErrDefaultToMissing, "", ""},
 	}
 
 	for idx, tt := range tests {
 		conf := Configuration{Locations: tt.locs}
 		m := mockLocator{
-			locateFn: func() (float64, float64, error) {
+			locateFn: func() (*geo.Position, error) {
 				if !tt.fromCurrent && !tt.toCurrent {
 					t.Fatalf("[#%v] Should not have called Locator", idx)
 				}
@@ -198,7 +335,7 @@ func TestCommuteCmd_Validate(t *testing.T) {
 					tt.expectTo = fmt.Sprintf("%v,%v", idx, idx)
 				}
 
-				return float64(idx), float64(idx), nil
+				return &geo.Position{Lat: float64(idx), Lng: float64(idx)}, nil
 			},
 		}
 		c := CommuteCmd{From: tt.from, FromCurrent: tt.fromCurrent, To: tt.to, ToCurrent: tt.toCurrent, Locator: &m, Drive: true}
@@ -238,7 +375,7 @@ func TestCommuteCmd_Validate(t *testing.T) {
 	}
 
 	for idx, tt := range cTests {
-		conf := Configuration{Locations: make(map[string]string)}
+		conf := Configuration{Locations: make(map[string]Location)}
 
 		c := CommuteCmd{From: "default", To: "default", Drive: tt.drive, Walk: tt.walk, Bike: tt.bike, Transit: tt.transit}
 		if err := c.Validate(&conf); err != tt.err {
@@ -246,3 +383,287 @@ func TestCommuteCmd_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestCommuteCmd_parseSchedule(t *testing.T) {
+	defer func(fn func() time.Time) { now = fn }(now)
+	at := time.Date(2017, time.May, 1, 7, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+
+	tests := []struct {
+		departAt string
+		arriveBy string
+		drive    bool
+		transit  bool
+
+		expect geo.Options
+		err    error
+	}{
+		// Positive
+		{"", "", true, false, geo.Options{}, nil},
+		{"08:15", "", true, false, geo.Options{DepartAt: at.Add(time.Minute * 75)}, nil},
+		{"in 30m", "", true, true, geo.Options{DepartAt: at.Add(time.Minute * 30)}, nil},
+		{"", "tomorrow 09:00", false, true, geo.Options{ArriveBy: at.Add(time.Hour * 26)}, nil},
+
+		// Negative
+		{"08:15", "09:00", false, true, geo.Options{}, ErrDepartAtAndArriveByProvided},
+		{"", "09:00", true, true, geo.Options{}, ErrArriveByRequiresTransit},
+		{"", "09:00", true, false, geo.Options{}, ErrArriveByRequiresTransit},
+		{"whenever", "", true, false, geo.Options{}, ErrInvalidTime},
+		{"", "2017-04-30 09:00", false, true, geo.Options{}, ErrTimeInPast},
+	}
+
+	for idx, tt := range tests {
+		c := CommuteCmd{DepartAt: tt.departAt, ArriveBy: tt.arriveBy, Drive: tt.drive, Transit: tt.transit}
+
+		o, err := c.parseSchedule()
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err == nil && !reflect.DeepEqual(o, tt.expect) {
+			t.Fatalf("[#%v] Unexpected Options, expected=%v, got=%v", idx, tt.expect, o)
+		}
+	}
+}
+
+func TestCommuteCmd_Validate_range(t *testing.T) {
+	tests := []struct {
+		drive   bool
+		transit bool
+		rng     bool
+
+		err error
+	}{
+		{true, false, false, nil},
+		{true, false, true, nil},
+		{true, true, true, nil},
+		{false, true, false, nil},
+		{false, true, true, ErrRangeRequiresDrive},
+	}
+
+	for idx, tt := range tests {
+		conf := Configuration{Locations: make(map[string]Location)}
+
+		c := CommuteCmd{From: "default", To: "default", Drive: tt.drive, Transit: tt.transit, Range: tt.rng}
+		if err := c.Validate(&conf); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err == nil && c.opts.TrafficRange != tt.rng {
+			t.Fatalf("[#%v] Unexpected TrafficRange, expected=%v, got=%v", idx, tt.rng, c.opts.TrafficRange)
+		}
+	}
+}
+
+func TestCommuteCmd_Validate_avoid(t *testing.T) {
+	tests := []struct {
+		conf        Configuration
+		from        string
+		fromCurrent bool
+		to          string
+		toCurrent   bool
+		drive       bool
+		avoid       string
+
+		expect []geo.Avoid
+		err    error
+	}{
+		// Default
+		{Configuration{}, "home", false, "work", false, true, "", []geo.Avoid{geo.AvoidTolls}, nil},
+		{Configuration{}, "home", false, "work", false, false, "", []geo.Avoid{geo.AvoidTolls}, nil},
+
+		// Configuration
+		{Configuration{Avoid: "highways"}, "home", false, "work", false, true, "", []geo.Avoid{geo.AvoidHighways}, nil},
+		{Configuration{Avoid: "none"}, "home", false, "work", false, true, "", nil, nil},
+
+		// Location overrides
+		{Configuration{Avoid: "highways", LocationAvoid: map[string]string{"home": "ferries"}}, "home", false, "work", false, true, "", []geo.Avoid{geo.AvoidFerries}, nil},
+		{Configuration{Avoid: "highways", LocationAvoid: map[string]string{"home": "ferries", "work": "none"}}, "home", false, "work", false, true, "", nil, nil},
+		{Configuration{Avoid: "highways", LocationAvoid: map[string]string{"default": "ferries"}}, "home", false, "default", true, true, "", []geo.Avoid{geo.AvoidHighways}, nil},
+		{Configuration{Avoid: "highways", LocationAvoid: map[string]string{"default": "ferries"}}, "default", true, "work", false, true, "", []geo.Avoid{geo.AvoidHighways}, nil},
+
+		// Argument
+		{Configuration{Avoid: "highways", LocationAvoid: map[string]string{"work": "ferries"}}, "home", false, "work", false, true, "tolls,ferries", []geo.Avoid{geo.AvoidTolls, geo.AvoidFerries}, nil},
+		{Configuration{}, "home", false, "work", false, true, "none", nil, nil},
+
+		// Negative
+		{Configuration{}, "home", false, "work", false, true, "bridges", nil, geo.ErrInvalidAvoid},
+		{Configuration{Avoid: "bridges"}, "home", false, "work", false, true, "", nil, geo.ErrInvalidAvoid},
+		{Configuration{}, "home", false, "work", false, false, "tolls", nil, ErrAvoidRequiresDrive},
+	}
+
+	for idx, tt := range tests {
+		m := mockLocator{
+			locateFn: func() (*geo.Position, error) {
+				return &geo.Position{Lat: 1, Lng: 1}, nil
+			},
+		}
+		c := CommuteCmd{From: tt.from, FromCurrent: tt.fromCurrent, To: tt.to, ToCurrent: tt.toCurrent, Drive: tt.drive, Walk: !tt.drive, Avoid: tt.avoid, Locator: &m}
+
+		if err := c.Validate(&tt.conf); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err == nil && !reflect.DeepEqual(c.opts.Avoid, tt.expect) {
+			t.Fatalf("[#%v] Unexpected Avoid, expected=%v, got=%v", idx, tt.expect, c.opts.Avoid)
+		}
+	}
+
+	// Only the DefaultAvoid is marked as a default, so that providers may ignore it.
+	for idx, tt := range []struct {
+		conf     Configuration
+		avoid    string
+		fallback bool
+	}{
+		{Configuration{}, "", true},
+		{Configuration{}, "tolls", false},
+		{Configuration{Avoid: "tolls"}, "", false},
+	} {
+		c := CommuteCmd{From: "home", To: "work", Drive: true, Avoid: tt.avoid}
+		if err := c.Validate(&tt.conf); err != nil {
+			t.Fatalf("[#%v] %v", idx, err)
+		} else if c.opts.DefaultAvoid != tt.fallback {
+			t.Fatalf("[#%v] Unexpected DefaultAvoid, expected=%v, got=%v", idx, tt.fallback, c.opts.DefaultAvoid)
+		}
+	}
+}
+
+func TestJoinAnd(t *testing.T) {
+	tests := []struct {
+		in     []string
+		expect string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b"}, "a and b"},
+		{[]string{"a", "b", "c"}, "a, b and c"},
+	}
+
+	for idx, tt := range tests {
+		if out := joinAnd(tt.in); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		}
+	}
+}
+
+func TestCommuteCmd_Validate_distance(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		conf     Configuration
+		distance *bool
+		units    string
+
+		expectDistance bool
+		expectUnits    geo.Units
+		err            error
+	}{
+		{Configuration{}, nil, "", false, geo.Metric, nil},
+		{Configuration{}, &on, "", true, geo.Metric, nil},
+		{Configuration{Distance: true}, nil, "", true, geo.Metric, nil},
+		{Configuration{Distance: true}, &off, "", false, geo.Metric, nil},
+		{Configuration{Units: "imperial"}, nil, "", false, geo.Imperial, nil},
+		{Configuration{Units: "imperial"}, &on, "metric", true, geo.Metric, nil},
+		{Configuration{}, &on, "Imperial", true, geo.Imperial, nil},
+
+		{Configuration{}, &on, "furlongs", true, "", geo.ErrInvalidUnits},
+		{Configuration{Units: "furlongs"}, &on, "", true, "", geo.ErrInvalidUnits},
+	}
+
+	for idx, tt := range tests {
+		c := CommuteCmd{From: "home", To: "work", Drive: true, Distance: tt.distance, Units: tt.units}
+
+		if err := c.Validate(&tt.conf); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if c.distance != tt.expectDistance {
+			t.Fatalf("[#%v] Unexpected Distance, expected=%v, got=%v", idx, tt.expectDistance, c.distance)
+		} else if c.opts.Units != tt.expectUnits {
+			t.Fatalf("[#%v] Unexpected Units, expected=%v, got=%v", idx, tt.expectUnits, c.opts.Units)
+		}
+	}
+}
+
+func TestCommuteCmd_Validate_transit(t *testing.T) {
+	tests := []struct {
+		conf    Configuration
+		drive   bool
+		transit bool
+		via     string
+		prefer  string
+
+		expectModes []geo.TransitMode
+		expectPref  geo.TransitPreference
+		err         error
+	}{
+		// Positive
+		{Configuration{}, false, true, "", "", nil, "", nil},
+		{Configuration{}, false, true, "subway,train", "less_walking", []geo.TransitMode{geo.TransitSubway, geo.TransitTrain}, geo.LessWalking, nil},
+		{Configuration{TransitVia: "bus", TransitPrefer: "fewer_transfers"}, false, true, "", "", []geo.TransitMode{geo.TransitBus}, geo.FewerTransfers, nil},
+		{Configuration{TransitVia: "bus", TransitPrefer: "fewer_transfers"}, false, true, "any", "none", nil, "", nil},
+		{Configuration{TransitVia: "bus"}, true, false, "", "", []geo.TransitMode{geo.TransitBus}, "", nil},
+		{Configuration{}, true, true, "rail", "", []geo.TransitMode{geo.TransitRail}, "", nil},
+
+		// Negative
+		{Configuration{}, true, false, "rail", "", nil, "", ErrTransitOptionsRequireTransit},
+		{Configuration{}, true, false, "", "less_walking", nil, "", ErrTransitOptionsRequireTransit},
+		{Configuration{}, false, true, "ferry", "", nil, "", geo.ErrInvalidTransitMode},
+		{Configuration{}, false, true, "", "fastest", nil, "", geo.ErrInvalidTransitPreference},
+		{Configuration{TransitVia: "ferry"}, false, true, "", "", nil, "", geo.ErrInvalidTransitMode},
+	}
+
+	for idx, tt := range tests {
+		c := CommuteCmd{From: "home", To: "work", Drive: tt.drive, Transit: tt.transit, TransitVia: tt.via, TransitPrefer: tt.prefer}
+
+		if err := c.Validate(&tt.conf); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(c.opts.TransitModes, tt.expectModes) {
+			t.Fatalf("[#%v] Unexpected TransitModes, expected=%v, got=%v", idx, tt.expectModes, c.opts.TransitModes)
+		} else if c.opts.TransitPreference != tt.expectPref {
+			t.Fatalf("[#%v] Unexpected TransitPreference, expected=%v, got=%v", idx, tt.expectPref, c.opts.TransitPreference)
+		}
+	}
+}
+
+func TestCommuteCmd_Run_current(t *testing.T) {
+	locator := mockLocator{
+		locateFn: func() (*geo.Position, error) {
+			return &geo.Position{Lat: 43.6, Lng: -79.3, Accuracy: 25}, nil
+		},
+	}
+	reverse := mockReverseGeocoder{
+		reverseFn: func(lat, lng float64) (*geo.Place, error) {
+			return &geo.Place{Address: "100 Queen St W"}, nil
+		},
+	}
+	durationer := mockDurationer{
+		durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+			if from != "43.6,-79.3" {
+				t.Fatalf("Unexpected From, expected=%v, got=%v", "43.6,-79.3", from)
+			}
+			return &geo.Estimate{}, nil
+		},
+	}
+
+	c := CommuteCmd{FromCurrent: true, To: "work", Drive: true, Durationer: &durationer, Locator: &locator, ReverseGeocoder: &reverse}
+	if err := c.Validate(&Configuration{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var i mockIndicator
+	if err := c.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "Current location: 100 Queen St W (within 25 m)"; i.out[0] != expect {
+		t.Fatalf("Unexpected output, expected=%v, got=%v", expect, i.out[0])
+	} else if expect := "From '100 Queen St W (within 25 m)' to 'work'"; c.String() != expect {
+		t.Fatalf("Unexpected String, expected=%v, got=%v", expect, c.String())
+	}
+
+	// Without a Locator, such as when no API key is configured
+	c = CommuteCmd{FromCurrent: true, To: "work", Drive: true, Durationer: &durationer}
+	if err := c.Validate(&Configuration{}); err != ErrLocationUnavailable {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrLocationUnavailable, err)
+	}
+}
@@ -21,8 +21,8 @@ func (c *ConfigureCmd) Run(conf *Configuration, i Indicator) error {
 	conf = &Configuration{
 		APIKey: c.promptForString(i, MsgGoogleMapsAPIKeyPrompt),
 
-		Locations: map[string]string{
-			DefaultLocationAlias: c.promptForString(i, MsgDefaultLocationPrompt),
+		Locations: map[string]Location{
+			DefaultLocationAlias: {Address: c.promptForString(i, MsgDefaultLocationPrompt)},
 		},
 	}
 
@@ -36,11 +36,21 @@ func (c *ConfigureCmd) Validate(conf *Configuration) error {
 
 // promptForString prompts the user for a string input.
 func (c *ConfigureCmd) promptForString(i Indicator, msg string) string {
-	i.Indicate(msg)
+	return promptForString(c.Input, i, msg)
+}
+
+// String returns a string representation of the ConfigureCmd.
+func (c *ConfigureCmd) String() string {
+	return "Configure"
+}
+
+// promptForString prompts the user for a string input, skipping empty lines.
+func promptForString(s Scanner, i Indicator, msg string) string {
+	i.Indicate("%v", msg)
 
 	var in string
-	for c.Input.Scan() {
-		in = c.Input.Text()
+	for s.Scan() {
+		in = s.Text()
 		if len(in) == 0 {
 			continue
 		}
@@ -50,8 +60,3 @@ func (c *ConfigureCmd) promptForString(i Indicator, msg string) string {
 
 	return in
 }
-
-// String returns a string representation of the ConfigureCmd.
-func (c *ConfigureCmd) String() string {
-	return "Configure"
-}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+var (
+	// ErrInvalidProviderSetting is returned when a provider setting isn't a key and value.
+	ErrInvalidProviderSetting = errors.New("invalid provider setting, expected a key and value [ex. 'url=http://localhost:5000'], or a key to remove")
+)
+
+// DefaultsCmd represents a command to view and update the default
+// commute options.
+//
+// Distance is a pointer so that it can be explicitly disabled, and is nil
+// when it should be left unchanged. ProviderSettings are "key=value" pairs,
+// and a setting with an empty value, or only a key, is removed.
+type DefaultsCmd struct {
+	Avoid    string
+	Distance *bool
+	Units    string
+
+	TransitVia    string
+	TransitPrefer string
+
+	Provider         string
+	ProviderSettings []string
+
+	Store StorageProvider
+}
+
+// Run updates any of the provided defaults and outputs the current defaults.
+func (d *DefaultsCmd) Run(conf *Configuration, i Indicator) error {
+	var changed bool
+	if len(d.Avoid) > 0 {
+		conf.Avoid = d.Avoid
+		changed = true
+	}
+	if d.Distance != nil {
+		conf.Distance = *d.Distance
+		changed = true
+	}
+	if len(d.Units) > 0 {
+		conf.Units = d.Units
+		changed = true
+	}
+	if len(d.TransitVia) > 0 {
+		conf.TransitVia = d.TransitVia
+		changed = true
+	}
+	if len(d.TransitPrefer) > 0 {
+		conf.TransitPrefer = d.TransitPrefer
+		changed = true
+	}
+	if len(d.Provider) > 0 {
+		conf.Provider = d.Provider
+		changed = true
+	}
+	for _, setting := range d.ProviderSettings {
+		key, value, ok := parseProviderSetting(setting)
+		if !ok {
+			continue
+		}
+
+		if conf.ProviderSettings == nil {
+			conf.ProviderSettings = make(map[string]string)
+		}
+		if len(value) == 0 {
+			delete(conf.ProviderSettings, key)
+		} else {
+			conf.ProviderSettings[key] = value
+		}
+		changed = true
+	}
+
+	if changed {
+		if err := d.Store.Save(conf); err != nil {
+			return err
+		}
+	}
+
+	avoid := conf.Avoid
+	if len(avoid) == 0 {
+		avoid = DefaultAvoid
+	}
+	units := conf.Units
+	if len(units) == 0 {
+		units = DefaultUnits
+	}
+	via := conf.TransitVia
+	if len(via) == 0 {
+		via = geo.TransitModeAny
+	}
+	prefer := conf.TransitPrefer
+	if len(prefer) == 0 {
+		prefer = geo.TransitPreferenceNone
+	}
+	provider := conf.Provider
+	if len(provider) == 0 {
+		provider = geo.GoogleProvider
+	}
+	if len(conf.ProviderSettings) > 0 {
+		var settings []string
+		for k, v := range conf.ProviderSettings {
+			settings = append(settings, fmt.Sprintf("%v=%v", k, v))
+		}
+		sort.Strings(settings)
+		provider = fmt.Sprintf("%v (%v)", provider, strings.Join(settings, ", "))
+	}
+
+	defaults := [][2]string{
+		{"avoid", avoid},
+		{"distance", fmt.Sprintf("%v", conf.Distance)},
+		{"units", units},
+		{"transit-via", via},
+		{"transit-prefer", prefer},
+		{"provider", provider},
+	}
+
+	var maxLen int
+	for _, def := range defaults {
+		if len(def[0]) > maxLen {
+			maxLen = len(def[0])
+		}
+	}
+
+	for _, def := range defaults {
+		i.Indicate("%*s: %v", maxLen, def[0], def[1])
+	}
+
+	return nil
+}
+
+// Validate validates the DefaultsCmd is properly initialized and ready to be Run.
+func (d *DefaultsCmd) Validate(conf *Configuration) error {
+	if len(d.Avoid) > 0 {
+		avoid, err := geo.ParseAvoid(d.Avoid)
+		if err != nil {
+			return err
+		}
+		d.Avoid = geo.FormatAvoid(avoid)
+	}
+
+	if len(d.Units) > 0 {
+		units, err := geo.ParseUnits(d.Units)
+		if err != nil {
+			return err
+		}
+		d.Units = string(units)
+	}
+
+	if len(d.TransitVia) > 0 {
+		modes, err := geo.ParseTransitModes(d.TransitVia)
+		if err != nil {
+			return err
+		}
+		d.TransitVia = geo.FormatTransitModes(modes)
+	}
+
+	if len(d.TransitPrefer) > 0 {
+		pref, err := geo.ParseTransitPreference(d.TransitPrefer)
+		if err != nil {
+			return err
+		}
+
+		d.TransitPrefer = string(pref)
+		if len(pref) == 0 {
+			d.TransitPrefer = geo.TransitPreferenceNone
+		}
+	}
+
+	if len(d.Provider) > 0 && !isProvider(d.Provider) {
+		return geo.ErrUnknownProvider
+	}
+	for _, setting := range d.ProviderSettings {
+		if _, _, ok := parseProviderSetting(setting); !ok {
+			return ErrInvalidProviderSetting
+		}
+	}
+
+	return nil
+}
+
+// parseProviderSetting parses a "key=value" provider setting into its key and value,
+// where a setting without a value is only a key. The setting is invalid when the key
+// is empty.
+func parseProviderSetting(setting string) (key, value string, ok bool) {
+	kv := strings.SplitN(setting, "=", 2)
+	key = strings.TrimSpace(kv[0])
+	if len(kv) == 2 {
+		value = kv[1]
+	}
+
+	return key, value, len(key) > 0
+}
+
+// isProvider returns true if a routing provider is registered with the name provided.
+func isProvider(name string) bool {
+	for _, p := range geo.Providers() {
+		if p == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns a string representation of the DefaultsCmd.
+func (d *DefaultsCmd) String() string {
+	return "Defaults"
+}
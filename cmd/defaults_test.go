@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestDefaultsCmd_Run(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		conf     Configuration
+		avoid    string
+		distance *bool
+		units    string
+
+		expectSave bool
+		expect     []string
+	}{
+		{Configuration{}, "", nil, "", false, []string{"avoid: tolls", "distance: false", "units: metric", "transit-via: any", "transit-prefer: none", "provider: google"}},
+		{Configuration{Avoid: "highways", Distance: true, Units: "imperial"}, "", nil, "", false, []string{"avoid: highways", "distance: true", "units: imperial", "transit-via: any", "transit-prefer: none", "provider: google"}},
+		{Configuration{}, "none", nil, "", true, []string{"avoid: none", "distance: false", "units: metric", "transit-via: any", "transit-prefer: none", "provider: google"}},
+		{Configuration{Avoid: "highways"}, "tolls,ferries", nil, "", true, []string{"avoid: tolls,ferries", "distance: false", "units: metric", "transit-via: any", "transit-prefer: none", "provider: google"}},
+		{Configuration{}, "", &on, "", true, []string{"avoid: tolls", "distance: true", "units: metric", "transit-via: any", "transit-prefer: none", "provider: google"}},
+		{Configuration{Distance: true}, "", &off, "imperial", true, []string{"avoid: tolls", "distance: false", "units: imperial", "transit-via: any", "transit-prefer: none", "provider: google"}},
+		{Configuration{TransitVia: "subway,train", TransitPrefer: "less_walking"}, "", nil, "", false, []string{"avoid: tolls", "distance: false", "units: metric", "transit-via: subway,train", "transit-prefer: less_walking", "provider: google"}},
+	}
+
+	for idx, tt := range tests {
+		var saved bool
+		m := mockStorageProvider{
+			saveFn: func(i interface{}) error {
+				if i != &tt.conf {
+					t.Fatalf("[#%v] Unexpected parameter to Save, got=%v", idx, i)
+				}
+				saved = true
+				return nil
+			},
+		}
+		d := DefaultsCmd{Avoid: tt.avoid, Distance: tt.distance, Units: tt.units, Store: &m}
+
+		var i mockIndicator
+		if err := d.Run(&tt.conf, &i); err != nil {
+			t.Fatal(err)
+		}
+
+		if saved != tt.expectSave {
+			t.Fatalf("[#%v] Unexpected save, expected=%v, got=%v", idx, tt.expectSave, saved)
+		}
+
+		if len(i.out) != len(tt.expect) {
+			t.Fatalf("[#%v] Unexpected number of output lines, expected=%v, got=%v", idx, tt.expect, i.out)
+		}
+		for line := range tt.expect {
+			if out := strings.TrimSpace(i.out[line]); out != tt.expect[line] {
+				t.Fatalf("[#%v] [Line %v] Unexpected output, expected=%v, got=%v", idx, line, tt.expect[line], out)
+			}
+		}
+	}
+
+	// Negative
+	{
+		testErr := errors.New("mock err")
+		m := mockStorageProvider{
+			saveFn: func(i interface{}) error {
+				return testErr
+			},
+		}
+
+		d := DefaultsCmd{Avoid: "tolls", Store: &m}
+		if err := d.Run(&Configuration{}, &mockIndicator{}); err != testErr {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", testErr, err)
+		}
+	}
+}
+
+func TestDefaultsCmd_Validate(t *testing.T) {
+	tests := []struct {
+		avoid string
+		units string
+		err   error
+
+		expectAvoid string
+		expectUnits string
+	}{
+		{"", "", nil, "", ""},
+		{"none", "", nil, "none", ""},
+		{"Tolls, Highways", "", nil, "tolls,highways", ""},
+		{"", "Imperial", nil, "", "imperial"},
+		{"bridges", "", geo.ErrInvalidAvoid, "", ""},
+		{"", "furlongs", geo.ErrInvalidUnits, "", ""},
+	}
+
+	for idx, tt := range tests {
+		d := DefaultsCmd{Avoid: tt.avoid, Units: tt.units}
+
+		if err := d.Validate(nil); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if d.Avoid != tt.expectAvoid {
+			t.Fatalf("[#%v] Unexpected Avoid, expected=%v, got=%v", idx, tt.expectAvoid, d.Avoid)
+		} else if d.Units != tt.expectUnits {
+			t.Fatalf("[#%v] Unexpected Units, expected=%v, got=%v", idx, tt.expectUnits, d.Units)
+		}
+	}
+}
+
+func TestDefaultsCmd_Run_transit(t *testing.T) {
+	m := mockStorageProvider{
+		saveFn: func(i interface{}) error {
+			return nil
+		},
+	}
+	conf := Configuration{TransitVia: "bus", TransitPrefer: "less_walking"}
+	d := DefaultsCmd{TransitVia: "rail", TransitPrefer: "fewer_transfers", Store: &m}
+
+	if err := d.Run(&conf, &mockIndicator{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.TransitVia != "rail" {
+		t.Fatalf("Unexpected TransitVia, expected=%v, got=%v", "rail", conf.TransitVia)
+	} else if conf.TransitPrefer != "fewer_transfers" {
+		t.Fatalf("Unexpected TransitPrefer, expected=%v, got=%v", "fewer_transfers", conf.TransitPrefer)
+	}
+}
+
+func TestDefaultsCmd_Validate_transit(t *testing.T) {
+	tests := []struct {
+		via    string
+		prefer string
+		err    error
+
+		expectVia    string
+		expectPrefer string
+	}{
+		{"Subway, Train", "", nil, "subway,train", ""},
+		{"any", "", nil, "any", ""},
+		{"", "Less_Walking", nil, "", "less_walking"},
+		{"", "none", nil, "", "none"},
+		{"ferry", "", geo.ErrInvalidTransitMode, "", ""},
+		{"", "fastest", geo.ErrInvalidTransitPreference, "", ""},
+	}
+
+	for idx, tt := range tests {
+		d := DefaultsCmd{TransitVia: tt.via, TransitPrefer: tt.prefer}
+
+		if err := d.Validate(nil); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if d.TransitVia != tt.expectVia {
+			t.Fatalf("[#%v] Unexpected TransitVia, expected=%v, got=%v", idx, tt.expectVia, d.TransitVia)
+		} else if d.TransitPrefer != tt.expectPrefer {
+			t.Fatalf("[#%v] Unexpected TransitPrefer, expected=%v, got=%v", idx, tt.expectPrefer, d.TransitPrefer)
+		}
+	}
+}
+
+func TestDefaultsCmd_provider(t *testing.T) {
+	var saved bool
+	m := mockStorageProvider{
+		saveFn: func(i interface{}) error {
+			saved = true
+			return nil
+		},
+	}
+
+	d := DefaultsCmd{Provider: "unknown", Store: &m}
+	if err := d.Validate(nil); err != geo.ErrUnknownProvider {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", geo.ErrUnknownProvider, err)
+	}
+
+	d.Provider = geo.GoogleProvider
+	if err := d.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var conf Configuration
+	var i mockIndicator
+	if err := d.Run(&conf, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Provider != geo.GoogleProvider {
+		t.Fatalf("Unexpected Provider, expected=%v, got=%v", geo.GoogleProvider, conf.Provider)
+	} else if !saved {
+		t.Fatal("Expected Configuration to be saved")
+	}
+
+	// Settings are validated, added and removed.
+	for _, setting := range []string{"", " ", "=http://localhost:5000"} {
+		d := DefaultsCmd{ProviderSettings: []string{setting}, Store: &m}
+		if err := d.Validate(nil); err != ErrInvalidProviderSetting {
+			t.Fatalf("Unexpected error for '%v', expected=%v, got=%v", setting, ErrInvalidProviderSetting, err)
+		}
+
+		// Invalid settings are ignored when run regardless.
+		conf = Configuration{}
+		if err := d.Run(&conf, &mockIndicator{}); err != nil {
+			t.Fatal(err)
+		} else if len(conf.ProviderSettings) > 0 {
+			t.Fatalf("Unexpected ProviderSettings for '%v', expected=none, got=%v", setting, conf.ProviderSettings)
+		}
+	}
+
+	conf = Configuration{Provider: geo.OSRMProvider, ProviderSettings: map[string]string{"walk-profile": "foot", "bike-profile": "bicycle"}}
+	d = DefaultsCmd{ProviderSettings: []string{"url=http://localhost:5000/?a=b", "drive-profile=car", "walk-profile=", "bike-profile"}, Store: &m}
+	if err := d.Validate(nil); err != nil {
+		t.Fatal(err)
+	}
+	i = mockIndicator{}
+	if err := d.Run(&conf, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]string{"url": "http://localhost:5000/?a=b", "drive-profile": "car"}
+	if !reflect.DeepEqual(conf.ProviderSettings, expect) {
+		t.Fatalf("Unexpected ProviderSettings, expected=%v, got=%v", expect, conf.ProviderSettings)
+	}
+
+	expectLine := "provider: osrm (drive-profile=car, url=http://localhost:5000/?a=b)"
+	if line := strings.TrimSpace(i.out[len(i.out)-1]); line != expectLine {
+		t.Fatalf("Unexpected output, expected=%v, got=%v", expectLine, line)
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+const (
+	stepTimeLayout = "15:04"
+)
+
+var (
+	// ErrDirectionsSingleMode is returned when directions are requested for more than one commute method.
+	ErrDirectionsSingleMode = errors.New("directions can only be retrieved for one commute method at a time")
+)
+
+// DirectionsCmd represents a command to retrieve the step by step
+// directions between two locations.
+//
+// DirectionsCmd resolves its locations and options, and retrieves
+// directions using the Director of the CommuteCmd it embeds.
+type DirectionsCmd struct {
+	CommuteCmd
+
+	// Export is the name of a .gpx, .geojson or .kml file to write the route to.
+	Export string
+	Files  FileCreator
+}
+
+// Run retrieves the directions between the From and To locations,
+// and outputs each step of the route.
+//
+// When Alternatives are requested, the routes are compared and the
+// steps of the fastest are output. When an Export file is provided, the
+// route is also written to it.
+func (d *DirectionsCmd) Run(conf *Configuration, i Indicator) error {
+	if s := d.describeSchedule(); len(s) > 0 {
+		i.Indicate("%v", s)
+	}
+
+	var routes []geo.Route
+	var err error
+	if d.Alternatives {
+		routes, err = d.alternatives(d.modes()[0], i)
+		if err == nil {
+			i.Indicate("")
+		}
+	} else {
+		routes, err = d.Director.Directions(d.From, d.To, d.modes()[0], d.opts)
+	}
+
+	if err != nil {
+		return err
+	} else if len(routes) == 0 {
+		return geo.ErrUnavailable
+	}
+	route := routes[0]
+
+	header := d.format(&route.Estimate)
+	if len(route.Summary) > 0 {
+		header = fmt.Sprintf("Via %v: %v", route.Summary, header)
+	}
+	i.Indicate("%v", header)
+
+	if s := d.describeOrder(route); len(s) > 0 {
+		i.Indicate("%v", s)
+	}
+
+	for _, leg := range route.Legs {
+		i.Indicate("")
+		i.Indicate("%v → %v", leg.StartAddress, leg.EndAddress)
+
+		width := len(fmt.Sprintf("%v", len(leg.Steps)))
+		for n, step := range leg.Steps {
+			i.Indicate("%*d. %v (%v)", width, n+1, step.Instructions, d.formatStep(step))
+
+			if t := step.Transit; t != nil {
+				indent := strings.Repeat(" ", width+2)
+				for _, line := range d.describeTransit(t) {
+					i.Indicate("%v%v", indent, line)
+				}
+			}
+		}
+	}
+
+	if len(d.Export) > 0 {
+		if err := d.export(route); err != nil {
+			return err
+		}
+
+		i.Indicate("")
+		i.Indicate("Exported to %v", d.Export)
+	}
+
+	return nil
+}
+
+// formatStep returns the formatted distance and duration of a Step, such as
+// "1.2 km, 6 Minutes".
+func (c *CommuteCmd) formatStep(s geo.Step) string {
+	duration := "< 1 Minute"
+	if s.Duration >= time.Minute {
+		duration = c.formatDuration(s.Duration)
+	}
+
+	return fmt.Sprintf("%v, %v", c.formatDistance(s.Distance), duration)
+}
+
+// describeTransit returns a description of the line, stops and times
+// of a transit Step, one line of output per entry.
+func (c *CommuteCmd) describeTransit(t *geo.TransitDetails) []string {
+	line := t.Line
+	if len(t.Vehicle) > 0 {
+		line = fmt.Sprintf("%v %v", t.Vehicle, line)
+	}
+	if len(t.Headsign) > 0 {
+		line = fmt.Sprintf("%v towards %v", line, t.Headsign)
+	}
+
+	stops := "stops"
+	if t.NumStops == 1 {
+		stops = "stop"
+	}
+
+	return []string{
+		line,
+		fmt.Sprintf("Depart %v at %v", t.DepartureStop, t.DepartureTime.Format(stepTimeLayout)),
+		fmt.Sprintf("Arrive %v at %v (%v %v)", t.ArrivalStop, t.ArrivalTime.Format(stepTimeLayout), t.NumStops, stops),
+	}
+}
+
+// Validate validates the DirectionsCmd is properly initialized and ready to be Run.
+func (d *DirectionsCmd) Validate(conf *Configuration) error {
+	if !d.capabilities().Directions {
+		return ErrDirectionsUnsupported
+	}
+
+	if err := d.CommuteCmd.Validate(conf); err != nil {
+		return err
+	}
+
+	if len(d.modes()) > 1 {
+		return ErrDirectionsSingleMode
+	}
+
+	if len(d.Export) > 0 {
+		if _, err := exporter(d.Export); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// String returns a string representation of the DirectionsCmd.
+func (d *DirectionsCmd) String() string {
+	return fmt.Sprintf("Directions from '%v' to '%v'", d.From, d.To)
+}
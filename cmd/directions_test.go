@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestDirectionsCmd_Run(t *testing.T) {
+	departure := time.Date(2017, time.May, 1, 8, 20, 0, 0, time.UTC)
+	arrival := time.Date(2017, time.May, 1, 8, 26, 0, 0, time.UTC)
+	routes := []geo.Route{
+		{
+			Estimate: geo.Estimate{Duration: time.Minute * 10, Distance: 1500},
+			Summary:  "Line 1",
+			Legs: []geo.Leg{
+				{
+					StartAddress: "123 Main St",
+					EndAddress:   "321 Maple Ave",
+					Steps: []geo.Step{
+						{Instructions: "Walk to Union Station", Distance: 300, Duration: time.Minute * 4},
+						{
+							Instructions: "Subway towards Finch",
+							Distance:     1200,
+							Duration:     time.Minute * 6,
+							Transit: &geo.TransitDetails{
+								Line:          "1 Yonge-University",
+								Vehicle:       "Subway",
+								Headsign:      "Finch",
+								DepartureStop: "Union Station",
+								DepartureTime: departure,
+								ArrivalStop:   "King Station",
+								ArrivalTime:   arrival,
+								NumStops:      2,
+							},
+						},
+						{Instructions: "Walk to 321 Maple Ave", Distance: 40, Duration: time.Second * 30},
+					},
+				},
+			},
+		},
+	}
+
+	m := mockDirector{
+		directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			if from != "home" {
+				t.Fatalf("Unexpected From, expected=%v, got=%v", "home", from)
+			} else if to != "work" {
+				t.Fatalf("Unexpected To, expected=%v, got=%v", "work", to)
+			} else if tm != geo.Transit {
+				t.Fatalf("Unexpected TravelMode, expected=%v, got=%v", geo.Transit, tm)
+			}
+
+			return routes, nil
+		},
+	}
+
+	d := DirectionsCmd{CommuteCmd: CommuteCmd{From: "home", To: "work", Transit: true, distance: true, Director: &m}}
+	var i mockIndicator
+	if err := d.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"Via Line 1: 10 Minutes (1.5 km, 9 km/h avg)",
+		"",
+		"123 Main St → 321 Maple Ave",
+		"1. Walk to Union Station (300 m, 4 Minutes)",
+		"2. Subway towards Finch (1.2 km, 6 Minutes)",
+		"   Subway 1 Yonge-University towards Finch",
+		"   Depart Union Station at 08:20",
+		"   Arrive King Station at 08:26 (2 stops)",
+		"3. Walk to 321 Maple Ave (40 m, < 1 Minute)",
+	}
+	if !reflect.DeepEqual(i.out, expect) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+	}
+
+	// Error from Director
+	{
+		testErr := errors.New("mock err")
+		m.directionsFn = func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			return nil, testErr
+		}
+
+		if err := d.Run(&Configuration{}, &mockIndicator{}); err != testErr {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", testErr, err)
+		}
+	}
+
+	// No routes
+	{
+		m.directionsFn = func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			return nil, nil
+		}
+
+		if err := d.Run(&Configuration{}, &mockIndicator{}); err != geo.ErrUnavailable {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", geo.ErrUnavailable, err)
+		}
+	}
+}
+
+func TestDirectionsCmd_Validate(t *testing.T) {
+	conf := Configuration{Locations: map[string]Location{"home": {Address: "123 Main St"}, "work": {Address: "321 Maple Ave"}}}
+	tests := []struct {
+		cmd    CommuteCmd
+		export string
+		err    error
+
+		expectFrom string
+		expectTo   string
+	}{
+		{CommuteCmd{From: "home", To: "work", Drive: true}, "", nil, "123 Main St", "321 Maple Ave"},
+		{CommuteCmd{From: "home", To: "1 Yonge St", Transit: true}, "", nil, "123 Main St", "1 Yonge St"},
+		{CommuteCmd{From: "home", To: "work", Drive: true}, "route.kml", nil, "123 Main St", "321 Maple Ave"},
+		{CommuteCmd{From: "home", To: "work", Drive: true}, "route.txt", ErrInvalidExportFormat, "", ""},
+		{CommuteCmd{From: "home", To: "work", Drive: true, Walk: true}, "", ErrDirectionsSingleMode, "", ""},
+		{CommuteCmd{From: "home", To: "work"}, "", ErrNoCommuteMethod, "", ""},
+		{CommuteCmd{From: "home", To: "", Walk: true}, "", ErrDefaultToMissing, "", ""},
+		{CommuteCmd{From: "home", To: "work", Drive: true, Durationer: &mockProvider{caps: geo.Capabilities{Geocoding: true}}}, "", ErrDirectionsUnsupported, "", ""},
+	}
+
+	for idx, tt := range tests {
+		d := DirectionsCmd{CommuteCmd: tt.cmd, Export: tt.export}
+		if err := d.Validate(&conf); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if d.From != tt.expectFrom {
+			t.Fatalf("[#%v] Unexpected From, expected=%v, got=%v", idx, tt.expectFrom, d.From)
+		} else if d.To != tt.expectTo {
+			t.Fatalf("[#%v] Unexpected To, expected=%v, got=%v", idx, tt.expectTo, d.To)
+		}
+	}
+}
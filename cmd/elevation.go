@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+const (
+	// sparkLevels are the characters used to draw an elevation profile, from lowest to highest.
+	sparkLevels = "_.-~^"
+
+	feetPerMeter = 3.28084
+)
+
+// profile outputs the climbing summary of the route for the provided TravelMode,
+// such as "Elevation: 120 m up, 85 m down, 6% max grade", followed by a sparkline
+// of its elevation profile.
+func (c *CommuteCmd) profile(tm geo.TravelMode, i Indicator) error {
+	routes, err := c.Director.Directions(c.From, c.To, tm, c.opts)
+	if err != nil {
+		return err
+	} else if len(routes) == 0 {
+		return geo.ErrUnavailable
+	}
+
+	p, err := c.Elevator.Elevation(routes[0])
+	if err != nil {
+		return err
+	}
+
+	i.Indicate("  Elevation: %v up, %v down, %.0f%% max grade",
+		formatHeight(p.Ascent(), c.opts.Units), formatHeight(p.Descent(), c.opts.Units), p.MaxGrade()*100)
+	i.Indicate("  %v", sparkline(p.Elevations))
+
+	return nil
+}
+
+// formatHeight takes a height in meters and returns a formatted representation
+// in the Units provided, such as "120 m" or "394 ft".
+func formatHeight(meters float64, u geo.Units) string {
+	if u == geo.Imperial {
+		return fmt.Sprintf("%.0f ft", meters*feetPerMeter)
+	}
+
+	return fmt.Sprintf("%.0f m", meters)
+}
+
+// sparkline draws a series of values as a single line of characters, scaled
+// between the lowest and highest value.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = math.Min(low, v)
+		high = math.Max(high, v)
+	}
+
+	out := make([]byte, len(values))
+	for i, v := range values {
+		var level int
+		if high > low {
+			level = int(math.Floor((v - low) / (high - low) * float64(len(sparkLevels)-1)))
+		}
+		out[i] = sparkLevels[level]
+	}
+
+	return string(out)
+}
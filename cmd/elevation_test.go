@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestCommuteCmd_Run_elevation(t *testing.T) {
+	route := geo.Route{Polyline: "abc"}
+	director := mockDirector{
+		directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			if tm != geo.Bike {
+				t.Fatalf("Unexpected TravelMode, expected=%v, got=%v", geo.Bike, tm)
+			}
+			return []geo.Route{route}, nil
+		},
+	}
+	elevator := mockElevator{
+		elevationFn: func(r geo.Route) (*geo.Profile, error) {
+			if r.Polyline != route.Polyline {
+				t.Fatalf("Unexpected Route, expected=%v, got=%v", route, r)
+			}
+			return &geo.Profile{Elevations: []float64{100, 120, 90, 150}, Distance: 3000}, nil
+		},
+	}
+	durationer := mockDurationer{
+		durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+			return &geo.Estimate{Duration: time.Minute * 30}, nil
+		},
+	}
+
+	tests := []struct {
+		units  geo.Units
+		expect []string
+	}{
+		{geo.Metric, []string{"Drive: 30 Minutes", "Bike: 30 Minutes", "  Elevation: 80 m up, 30 m down, 6% max grade", "  _-_^"}},
+		{geo.Imperial, []string{"Drive: 30 Minutes", "Bike: 30 Minutes", "  Elevation: 262 ft up, 98 ft down, 6% max grade", "  _-_^"}},
+	}
+
+	for idx, tt := range tests {
+		c := CommuteCmd{Drive: true, Bike: true, Elevation: true, Durationer: &durationer, Director: &director, Elevator: &elevator}
+		c.opts.Units = tt.units
+
+		var i mockIndicator
+		if err := c.Run(&Configuration{}, &i); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(i.out) != len(tt.expect) {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, i.out)
+		}
+		for line := range tt.expect {
+			if i.out[line] != tt.expect[line] {
+				t.Fatalf("[#%v] [Line %v] Unexpected output, expected=%v, got=%v", idx, line, tt.expect[line], i.out[line])
+			}
+		}
+	}
+
+	// Error from Elevator
+	{
+		e := errors.New("test err")
+		elevator.elevationFn = func(r geo.Route) (*geo.Profile, error) {
+			return nil, e
+		}
+
+		c := CommuteCmd{Bike: true, Elevation: true, Durationer: &durationer, Director: &director, Elevator: &elevator}
+		if err := c.Run(&Configuration{}, &mockIndicator{}); err != e {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", e, err)
+		}
+	}
+
+	// No routes
+	{
+		director.directionsFn = func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			return nil, nil
+		}
+
+		c := CommuteCmd{Bike: true, Elevation: true, Durationer: &durationer, Director: &director, Elevator: &elevator}
+		if err := c.Run(&Configuration{}, &mockIndicator{}); err != geo.ErrUnavailable {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", geo.ErrUnavailable, err)
+		}
+	}
+}
+
+func TestCommuteCmd_Validate_elevation(t *testing.T) {
+	tests := []struct {
+		c   CommuteCmd
+		err error
+	}{
+		{CommuteCmd{Bike: true, Elevation: true}, nil},
+		{CommuteCmd{Walk: true, Drive: true, Elevation: true}, nil},
+		{CommuteCmd{Drive: true, Elevation: true}, ErrElevationRequiresBikeOrWalk},
+		{CommuteCmd{Transit: true, Elevation: true}, ErrElevationRequiresBikeOrWalk},
+	}
+
+	for idx, tt := range tests {
+		tt.c.From, tt.c.To = "from", "to"
+		if err := tt.c.Validate(&Configuration{}); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		in     []float64
+		expect string
+	}{
+		{nil, ""},
+		{[]float64{10, 10, 10}, "___"},
+		{[]float64{0, 25, 50, 75, 100}, "_.-~^"},
+		{[]float64{100, 50, 0}, "^-_"},
+	}
+
+	for idx, tt := range tests {
+		if out := sparkline(tt.in); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		}
+	}
+}
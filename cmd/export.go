@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+var (
+	// ErrInvalidExportFormat is returned when the -export argument is not a supported file type.
+	ErrInvalidExportFormat = errors.New("-export must be a .gpx, .geojson or .kml file")
+
+	// exporters maps file extensions to the function used to write a route in that format.
+	exporters = map[string]func(io.Writer, string, []geo.Position, []waypoint) error{
+		".gpx":     writeGPX,
+		".geojson": writeGeoJSON,
+		".json":    writeGeoJSON,
+		".kml":     writeKML,
+	}
+)
+
+// waypoint is a named point along a route, such as its origin, destination or a stop.
+type waypoint struct {
+	Name string
+	geo.Position
+}
+
+// exporter returns the function used to write a route to the file provided,
+// based on its extension.
+func exporter(file string) (func(io.Writer, string, []geo.Position, []waypoint) error, error) {
+	fn, ok := exporters[strings.ToLower(filepath.Ext(file))]
+	if !ok {
+		return nil, ErrInvalidExportFormat
+	}
+
+	return fn, nil
+}
+
+// export writes the path of a Route, along with waypoints for its origin, destination
+// and any stops, to the Export file.
+func (d *DirectionsCmd) export(route geo.Route) (err error) {
+	write, err := exporter(d.Export)
+	if err != nil {
+		return err
+	}
+
+	f, err := d.Files.Create(d.Export)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return write(f, routeName(route), route.Path(), waypoints(route))
+}
+
+// routeName returns a name for a Route, using its summary when available and
+// otherwise its origin and destination.
+func routeName(r geo.Route) string {
+	if len(r.Summary) > 0 {
+		return r.Summary
+	} else if len(r.Legs) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%v → %v", r.Legs[0].StartAddress, r.Legs[len(r.Legs)-1].EndAddress)
+}
+
+// waypoints returns the origin of a Route, followed by the end of each of its legs.
+func waypoints(r geo.Route) []waypoint {
+	if len(r.Legs) == 0 {
+		return nil
+	}
+
+	w := []waypoint{{Name: r.Legs[0].StartAddress, Position: r.Legs[0].StartLocation}}
+	for _, l := range r.Legs {
+		w = append(w, waypoint{Name: l.EndAddress, Position: l.EndLocation})
+	}
+
+	return w
+}
+
+// coordinate formats a Latitude or Longitude without an exponent.
+func coordinate(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// writeGPX writes a route as a GPX track, with a waypoint for each stop.
+func writeGPX(w io.Writer, name string, path []geo.Position, stops []waypoint) error {
+	type point struct {
+		Lat  string `xml:"lat,attr"`
+		Lon  string `xml:"lon,attr"`
+		Name string `xml:"name,omitempty"`
+	}
+	type gpx struct {
+		XMLName   xml.Name `xml:"gpx"`
+		Version   string   `xml:"version,attr"`
+		Creator   string   `xml:"creator,attr"`
+		Namespace string   `xml:"xmlns,attr"`
+		Waypoints []point  `xml:"wpt"`
+		Name      string   `xml:"trk>name"`
+		Track     []point  `xml:"trk>trkseg>trkpt"`
+	}
+
+	doc := gpx{
+		Version:   "1.1",
+		Creator:   "commuter",
+		Namespace: "http://www.topografix.com/GPX/1/1",
+		Name:      name,
+	}
+	for _, s := range stops {
+		doc.Waypoints = append(doc.Waypoints, point{Lat: coordinate(s.Lat), Lon: coordinate(s.Lng), Name: s.Name})
+	}
+	for _, p := range path {
+		doc.Track = append(doc.Track, point{Lat: coordinate(p.Lat), Lon: coordinate(p.Lng)})
+	}
+
+	return writeXML(w, doc)
+}
+
+// writeKML writes a route as a KML LineString, with a Point placemark for each stop.
+func writeKML(w io.Writer, name string, path []geo.Position, stops []waypoint) error {
+	type geometry struct {
+		Coordinates string `xml:"coordinates"`
+	}
+	type placemark struct {
+		Name       string    `xml:"name"`
+		Point      *geometry `xml:",omitempty"`
+		LineString *geometry `xml:",omitempty"`
+	}
+	type kml struct {
+		XMLName    xml.Name    `xml:"kml"`
+		Namespace  string      `xml:"xmlns,attr"`
+		Name       string      `xml:"Document>name"`
+		Placemarks []placemark `xml:"Document>Placemark"`
+	}
+
+	doc := kml{
+		Namespace: "http://www.opengis.net/kml/2.2",
+		Name:      name,
+	}
+	for _, s := range stops {
+		doc.Placemarks = append(doc.Placemarks, placemark{Name: s.Name, Point: &geometry{coordinate(s.Lng) + "," + coordinate(s.Lat)}})
+	}
+
+	coords := make([]string, len(path))
+	for i, p := range path {
+		coords[i] = coordinate(p.Lng) + "," + coordinate(p.Lat)
+	}
+	doc.Placemarks = append(doc.Placemarks, placemark{Name: name, LineString: &geometry{strings.Join(coords, " ")}})
+
+	return writeXML(w, doc)
+}
+
+// writeXML writes an indented XML document, including the XML header.
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeGeoJSON writes a route as a GeoJSON FeatureCollection, containing a LineString
+// feature for the route and a Point feature for each stop.
+func writeGeoJSON(w io.Writer, name string, path []geo.Position, stops []waypoint) error {
+	type geometry struct {
+		Type        string      `json:"type"`
+		Coordinates interface{} `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string            `json:"type"`
+		Geometry   geometry          `json:"geometry"`
+		Properties map[string]string `json:"properties"`
+	}
+	type collection struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}
+
+	line := make([][]float64, len(path))
+	for i, p := range path {
+		line[i] = []float64{p.Lng, p.Lat}
+	}
+
+	doc := collection{
+		Type: "FeatureCollection",
+		Features: []feature{{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "LineString", Coordinates: line},
+			Properties: map[string]string{"name": name},
+		}},
+	}
+	for _, s := range stops {
+		doc.Features = append(doc.Features, feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "Point", Coordinates: []float64{s.Lng, s.Lat}},
+			Properties: map[string]string{"name": s.Name},
+		})
+	}
+
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
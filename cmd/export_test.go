@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+var exportRoute = geo.Route{
+	Summary:  "Main St",
+	Polyline: "_p~iF~ps|U_ulLnnqC",
+	Legs: []geo.Leg{
+		{StartAddress: "123 Main St", StartLocation: geo.Position{Lat: 38.5, Lng: -120.2}, EndAddress: "1 Daycare Rd", EndLocation: geo.Position{Lat: 39.1, Lng: -120.5}},
+		{StartAddress: "1 Daycare Rd", StartLocation: geo.Position{Lat: 39.1, Lng: -120.5}, EndAddress: "321 Maple Ave", EndLocation: geo.Position{Lat: 40.7, Lng: -120.95}},
+	},
+}
+
+func TestDirectionsCmd_export(t *testing.T) {
+	tests := []struct {
+		file   string
+		expect string
+	}{
+		{"route.gpx", `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="commuter" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="38.5" lon="-120.2">
+    <name>123 Main St</name>
+  </wpt>
+  <wpt lat="39.1" lon="-120.5">
+    <name>1 Daycare Rd</name>
+  </wpt>
+  <wpt lat="40.7" lon="-120.95">
+    <name>321 Maple Ave</name>
+  </wpt>
+  <trk>
+    <name>Main St</name>
+    <trkseg>
+      <trkpt lat="38.5" lon="-120.2"></trkpt>
+      <trkpt lat="40.7" lon="-120.95"></trkpt>
+    </trkseg>
+  </trk>
+</gpx>
+`},
+		{"route.KML", `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>Main St</name>
+    <Placemark>
+      <name>123 Main St</name>
+      <Point>
+        <coordinates>-120.2,38.5</coordinates>
+      </Point>
+    </Placemark>
+    <Placemark>
+      <name>1 Daycare Rd</name>
+      <Point>
+        <coordinates>-120.5,39.1</coordinates>
+      </Point>
+    </Placemark>
+    <Placemark>
+      <name>321 Maple Ave</name>
+      <Point>
+        <coordinates>-120.95,40.7</coordinates>
+      </Point>
+    </Placemark>
+    <Placemark>
+      <name>Main St</name>
+      <LineString>
+        <coordinates>-120.2,38.5 -120.95,40.7</coordinates>
+      </LineString>
+    </Placemark>
+  </Document>
+</kml>
+`},
+		{"route.geojson", `{
+  "type": "FeatureCollection",
+  "features": [
+    {
+      "type": "Feature",
+      "geometry": {
+        "type": "LineString",
+        "coordinates": [
+          [
+            -120.2,
+            38.5
+          ],
+          [
+            -120.95,
+            40.7
+          ]
+        ]
+      },
+      "properties": {
+        "name": "Main St"
+      }
+    },
+    {
+      "type": "Feature",
+      "geometry": {
+        "type": "Point",
+        "coordinates": [
+          -120.2,
+          38.5
+        ]
+      },
+      "properties": {
+        "name": "123 Main St"
+      }
+    },
+    {
+      "type": "Feature",
+      "geometry": {
+        "type": "Point",
+        "coordinates": [
+          -120.5,
+          39.1
+        ]
+      },
+      "properties": {
+        "name": "1 Daycare Rd"
+      }
+    },
+    {
+      "type": "Feature",
+      "geometry": {
+        "type": "Point",
+        "coordinates": [
+          -120.95,
+          40.7
+        ]
+      },
+      "properties": {
+        "name": "321 Maple Ave"
+      }
+    }
+  ]
+}
+`},
+	}
+
+	for idx, tt := range tests {
+		var f mockFile
+		d := DirectionsCmd{Export: tt.file, Files: &mockFileCreator{createFn: func(name string) (io.WriteCloser, error) {
+			if name != tt.file {
+				t.Fatalf("[#%v] Unexpected file created, expected=%v, got=%v", idx, tt.file, name)
+			}
+			return &f, nil
+		}}}
+
+		if err := d.export(exportRoute); err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		}
+
+		if out := f.String(); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		} else if !f.closed {
+			t.Fatalf("[#%v] Expected file to be closed", idx)
+		}
+	}
+
+	// Error creating the file
+	{
+		e := errors.New("test err")
+		d := DirectionsCmd{Export: "route.gpx", Files: &mockFileCreator{createFn: func(name string) (io.WriteCloser, error) {
+			return nil, e
+		}}}
+
+		if err := d.export(exportRoute); err != e {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", e, err)
+		}
+	}
+}
+
+func TestDirectionsCmd_Run_export(t *testing.T) {
+	var f mockFile
+	d := DirectionsCmd{
+		CommuteCmd: CommuteCmd{From: "home", To: "work", Drive: true, Director: &mockDirector{
+			directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+				return []geo.Route{exportRoute}, nil
+			},
+		}},
+		Export: "route.gpx",
+		Files: &mockFileCreator{createFn: func(name string) (io.WriteCloser, error) {
+			return &f, nil
+		}},
+	}
+
+	var i mockIndicator
+	if err := d.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "Exported to route.gpx"; i.out[len(i.out)-1] != expect {
+		t.Fatalf("Unexpected output, expected=%v, got=%v", expect, i.out[len(i.out)-1])
+	} else if !bytes.Contains(f.Bytes(), []byte("<gpx")) {
+		t.Fatalf("Unexpected export, got=%v", f.String())
+	}
+}
+
+func TestExporter(t *testing.T) {
+	tests := []struct {
+		file string
+		err  error
+	}{
+		{"route.gpx", nil},
+		{"route.GeoJSON", nil},
+		{"route.json", nil},
+		{"/tmp/route.kml", nil},
+		{"route.txt", ErrInvalidExportFormat},
+		{"route", ErrInvalidExportFormat},
+	}
+
+	for idx, tt := range tests {
+		if _, err := exporter(tt.file); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+const (
+	// maxLeaveIterations is the maximum number of departure times tried when searching
+	// for the latest departure with traffic, each of which requires a request to the Durationer.
+	maxLeaveIterations = 5
+)
+
+var (
+	// ErrLeaveArriveMissing is returned when running the leave command without the -arrive argument.
+	ErrLeaveArriveMissing = errors.New("missing -arrive parameter [ex. '09:30']")
+	// ErrLeaveScheduleProvided is returned when the -depart-at or -arrive-by arguments are used with the leave command.
+	ErrLeaveScheduleProvided = errors.New("cannot use -depart-at or -arrive-by arguments with leave, use -arrive instead")
+	// ErrInvalidBuffer is returned when the -buffer argument is not a duration of zero or more.
+	ErrInvalidBuffer = errors.New("invalid -buffer, expected a duration [ex. '10m', '1h']")
+	// ErrNoLeaveTime is returned when no departure time could be found for a commute method.
+	ErrNoLeaveTime = errors.New("unable to determine when to leave")
+)
+
+// LeaveCmd represents a command to find the latest time to leave for each
+// commute method, in order to arrive by a required time.
+type LeaveCmd struct {
+	CommuteCmd
+
+	Arrive string
+	Buffer string
+
+	arrival time.Time
+	buffer  time.Duration
+}
+
+// Run determines the latest time to leave for each commute method, and outputs them.
+func (l *LeaveCmd) Run(conf *Configuration, i Indicator) error {
+	modes := l.modes()
+	multiMode := len(modes) > 1
+
+	if len(l.here) > 0 {
+		i.Indicate("Current location: %v", l.here)
+	}
+	if len(l.nearby) > 0 {
+		i.Indicate("%v", l.nearby)
+	}
+
+	arriving := fmt.Sprintf("Arriving by %v", describeTime(l.arrival, l.toZone, l.fromZone, "origin"))
+	if l.buffer > 0 {
+		arriving += fmt.Sprintf(", with %v to spare", l.formatDuration(l.buffer))
+	}
+	i.Indicate("%v", arriving)
+
+	target := l.arrival.Add(-l.buffer)
+	for _, m := range modes {
+		departure, d, err := l.leave(m, target)
+		if err != nil {
+			return err
+		}
+
+		var method string
+		if multiMode {
+			method = fmt.Sprintf("%v: ", m)
+		}
+
+		var late string
+		if departure.Before(now()) {
+			late = ", already passed"
+		}
+
+		i.Indicate("%vLeave by %v (%v%v)", method, describeTime(departure, l.fromZone, l.toZone, "destination"), l.formatDuration(d), late)
+	}
+
+	return nil
+}
+
+// leave returns the latest departure time, truncated to the minute, to arrive by the
+// target time with the given TravelMode, along with the expected duration of the commute.
+func (l *LeaveCmd) leave(m geo.TravelMode, target time.Time) (time.Time, time.Duration, error) {
+	var departure time.Time
+	var d time.Duration
+	var err error
+
+	switch m {
+	case geo.Transit:
+		departure, d, err = l.leaveTransit(target)
+	case geo.Drive:
+		departure, d, err = l.leaveDrive(target)
+	default:
+		var e *geo.Estimate
+		e, err = l.duration(m, l.opts)
+		if err == nil {
+			d = e.Duration
+			departure = target.Add(-d)
+		}
+	}
+	if err != nil {
+		return departure, d, err
+	}
+
+	return departure.Truncate(time.Minute), d, nil
+}
+
+// leaveTransit returns the departure time of the transit route that arrives closest to,
+// without passing, the target time.
+//
+// The route is retrieved from the Director when there is one, as the estimates of the
+// Durationer may not include the departure of the trip, and may arrive well before the
+// target time. Without a Director, such as offline or with a GTFS feed, the estimate of
+// the Durationer is used.
+func (l *LeaveCmd) leaveTransit(target time.Time) (time.Time, time.Duration, error) {
+	opts := l.opts
+	opts.ArriveBy = target
+
+	if l.Director == nil {
+		e, err := l.duration(geo.Transit, opts)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+
+		if departure, ok := transitDeparture(e.Steps); ok {
+			return departure, e.Duration, nil
+		}
+		return target.Add(-e.Duration), e.Duration, nil
+	}
+
+	routes, err := l.Director.Directions(l.From, l.To, geo.Transit, opts)
+	if err != nil {
+		return time.Time{}, 0, err
+	} else if len(routes) == 0 {
+		return time.Time{}, 0, ErrNoLeaveTime
+	}
+
+	r := routes[0]
+	if len(r.Legs) > 0 {
+		if departure, ok := transitDeparture(r.Legs[0].Steps); ok {
+			return departure, r.Duration, nil
+		}
+		if !r.Legs[0].DepartureTime.IsZero() {
+			return r.Legs[0].DepartureTime, r.Duration, nil
+		}
+	}
+
+	return target.Add(-r.Duration), r.Duration, nil
+}
+
+// transitDeparture returns the time to leave to catch the first transit step, which is
+// its departure time less the time it takes to get to it.
+func transitDeparture(steps []geo.Step) (time.Time, bool) {
+	var access time.Duration
+	for _, s := range steps {
+		if s.Transit != nil && !s.Transit.DepartureTime.IsZero() {
+			return s.Transit.DepartureTime.Add(-access), true
+		}
+		access += s.Duration
+	}
+
+	return time.Time{}, false
+}
+
+// leaveDrive searches for the latest departure time that arrives by the target time,
+// accounting for the traffic expected at each departure time tried.
+//
+// Each departure tried is the target time less the duration of the previous try,
+// until the departure settles within a minute.
+func (l *LeaveCmd) leaveDrive(target time.Time) (time.Time, time.Duration, error) {
+	opts := l.opts
+
+	departure := target
+	var d time.Duration
+	for n := 0; n < maxLeaveIterations; n++ {
+		// Traffic can't be estimated for a departure in the past, so the current
+		// traffic is used instead.
+		opts.DepartAt = departure
+		if departure.Before(now()) {
+			opts.DepartAt = time.Time{}
+		}
+
+		e, err := l.duration(geo.Drive, opts)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+
+		next := target.Add(-e.Duration)
+		settled := next.Sub(departure) < time.Minute && departure.Sub(next) < time.Minute
+
+		d = e.Duration
+		departure = next
+		if settled {
+			break
+		}
+	}
+
+	return departure, d, nil
+}
+
+// Validate validates the LeaveCmd is properly initialized and ready to be Run.
+func (l *LeaveCmd) Validate(conf *Configuration) error {
+	if len(l.DepartAt) > 0 || len(l.ArriveBy) > 0 {
+		return ErrLeaveScheduleProvided
+	}
+	if len(l.Arrive) == 0 {
+		return ErrLeaveArriveMissing
+	}
+
+	l.zoned = true
+	if err := l.CommuteCmd.Validate(conf); err != nil {
+		return err
+	}
+
+	if len(l.Buffer) > 0 {
+		buffer, err := time.ParseDuration(l.Buffer)
+		if err != nil || buffer < 0 {
+			return ErrInvalidBuffer
+		}
+		l.buffer = buffer
+	}
+
+	var err error
+	l.arrival, err = parseTime(l.Arrive, now().In(l.destinationZone()))
+	return err
+}
+
+// String returns a string representation of the LeaveCmd.
+func (l *LeaveCmd) String() string {
+	return fmt.Sprintf("Leave from '%v' to '%v' to arrive by %v", l.From, l.To, l.Arrive)
+}
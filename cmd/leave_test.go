@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestLeaveCmd_Run(t *testing.T) {
+	defer func(fn func() time.Time) { now = fn }(now)
+	at := time.Date(2017, time.May, 1, 6, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+
+	arrival := time.Date(2017, time.May, 1, 9, 30, 0, 0, time.UTC)
+	target := arrival.Add(-10 * time.Minute)
+
+	var requests []time.Time
+	l := LeaveCmd{
+		CommuteCmd: CommuteCmd{
+			From:    "home",
+			To:      "client",
+			Drive:   true,
+			Walk:    true,
+			Transit: true,
+			Durationer: &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+				if tm == geo.Walk {
+					if !o.DepartAt.IsZero() {
+						t.Fatalf("Unexpected DepartAt for walking, got=%v", o.DepartAt)
+					}
+					return &geo.Estimate{Duration: time.Hour + 5*time.Minute}, nil
+				}
+
+				// Traffic gets heavier the later the departure.
+				requests = append(requests, o.DepartAt)
+				return &geo.Estimate{Duration: 30*time.Minute + o.DepartAt.Sub(at)/30, Traffic: true}, nil
+			}},
+			Director: &mockDirector{directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+				if tm != geo.Transit {
+					t.Fatalf("Unexpected TravelMode, expected=%v, got=%v", geo.Transit, tm)
+				} else if !o.ArriveBy.Equal(target) {
+					t.Fatalf("Unexpected ArriveBy, expected=%v, got=%v", target, o.ArriveBy)
+				}
+				return []geo.Route{{
+					Estimate: geo.Estimate{Duration: 45 * time.Minute},
+					Legs:     []geo.Leg{{DepartureTime: time.Date(2017, time.May, 1, 8, 32, 0, 0, time.UTC)}},
+				}}, nil
+			}},
+		},
+		arrival: arrival,
+		buffer:  10 * time.Minute,
+	}
+
+	var i mockIndicator
+	if err := l.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"Arriving by Mon May 1 09:30, with 10 Minutes to spare",
+		"Drive: Leave by Mon May 1 08:44 (35 Minutes)",
+		"Walk: Leave by Mon May 1 08:15 (1 Hour 5 Minutes)",
+		"Transit: Leave by Mon May 1 08:32 (45 Minutes)",
+	}
+	if !reflect.DeepEqual(i.out, expect) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+	} else if len(requests) > maxLeaveIterations {
+		t.Fatalf("Unexpected number of requests, expected<=%v, got=%v", maxLeaveIterations, len(requests))
+	}
+
+	// Durationer errors are returned.
+	expectErr := errors.New("durationer error")
+	l.Durationer = &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+		return nil, expectErr
+	}}
+	if err := l.Run(&Configuration{}, &i); err != expectErr {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", expectErr, err)
+	}
+}
+
+func TestLeaveCmd_leave(t *testing.T) {
+	defer func(fn func() time.Time) { now = fn }(now)
+	at := time.Date(2017, time.May, 1, 9, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+
+	target := time.Date(2017, time.May, 1, 9, 30, 0, 0, time.UTC)
+
+	// Departures in the past use the current traffic.
+	{
+		l := LeaveCmd{CommuteCmd: CommuteCmd{
+			Durationer: &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+				if !o.DepartAt.IsZero() && o.DepartAt.Before(at) {
+					t.Fatalf("Unexpected DepartAt in the past, got=%v", o.DepartAt)
+				}
+				return &geo.Estimate{Duration: 45 * time.Minute}, nil
+			}},
+		}}
+
+		departure, d, err := l.leave(geo.Drive, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := time.Date(2017, time.May, 1, 8, 45, 0, 0, time.UTC)
+		if !departure.Equal(expect) {
+			t.Fatalf("Unexpected departure, expected=%v, got=%v", expect, departure)
+		} else if d != 45*time.Minute {
+			t.Fatalf("Unexpected duration, expected=%v, got=%v", 45*time.Minute, d)
+		}
+	}
+
+	// Transit without departure times falls back to the route duration.
+	{
+		l := LeaveCmd{CommuteCmd: CommuteCmd{
+			Director: &mockDirector{directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+				return []geo.Route{{Estimate: geo.Estimate{Duration: 20*time.Minute + 30*time.Second}}}, nil
+			}},
+		}}
+
+		departure, _, err := l.leave(geo.Transit, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := time.Date(2017, time.May, 1, 9, 9, 0, 0, time.UTC)
+		if !departure.Equal(expect) {
+			t.Fatalf("Unexpected departure, expected=%v, got=%v", expect, departure)
+		}
+	}
+
+	// Transit without routes is an error.
+	{
+		l := LeaveCmd{CommuteCmd: CommuteCmd{
+			Director: &mockDirector{directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+				return nil, nil
+			}},
+		}}
+
+		if _, _, err := l.leave(geo.Transit, target); err != ErrNoLeaveTime {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", ErrNoLeaveTime, err)
+		}
+	}
+
+	// Transit departs in time to walk to the first transit step, rather than the
+	// duration before the target, as the trip may arrive early.
+	steps := []geo.Step{
+		{Instructions: "Walk to King St West", Duration: 3 * time.Minute},
+		{Instructions: "Take the 504 King", Duration: 20 * time.Minute, Transit: &geo.TransitDetails{DepartureTime: time.Date(2017, time.May, 1, 8, 55, 0, 0, time.UTC)}},
+	}
+	{
+		l := LeaveCmd{CommuteCmd: CommuteCmd{
+			Durationer: &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+				t.Fatal("Unexpected duration with a Director")
+				return nil, nil
+			}},
+			Director: &mockDirector{directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+				return []geo.Route{{
+					Estimate: geo.Estimate{Duration: 25 * time.Minute},
+					Legs:     []geo.Leg{{DepartureTime: time.Date(2017, time.May, 1, 8, 55, 0, 0, time.UTC), Steps: steps}},
+				}}, nil
+			}},
+		}}
+
+		departure, d, err := l.leave(geo.Transit, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := time.Date(2017, time.May, 1, 8, 52, 0, 0, time.UTC)
+		if !departure.Equal(expect) {
+			t.Fatalf("Unexpected departure, expected=%v, got=%v", expect, departure)
+		} else if d != 25*time.Minute {
+			t.Fatalf("Unexpected duration, expected=%v, got=%v", 25*time.Minute, d)
+		}
+	}
+
+	// Without a Director, such as offline, transit is estimated by the Durationer.
+	tests := []struct {
+		e         *geo.Estimate
+		err       error
+		departure time.Time
+	}{
+		{&geo.Estimate{Duration: 25 * time.Minute, Steps: steps}, nil, time.Date(2017, time.May, 1, 8, 52, 0, 0, time.UTC)},
+		{&geo.Estimate{Duration: 20*time.Minute + 30*time.Second}, nil, time.Date(2017, time.May, 1, 9, 9, 0, 0, time.UTC)},
+		{nil, errors.New("no transit route"), time.Time{}},
+	}
+
+	for idx, tt := range tests {
+		l := LeaveCmd{CommuteCmd: CommuteCmd{
+			Durationer: &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+				if !o.ArriveBy.Equal(target) {
+					t.Fatalf("[#%v] Unexpected ArriveBy, expected=%v, got=%v", idx, target, o.ArriveBy)
+				}
+				return tt.e, tt.err
+			}},
+		}}
+
+		departure, _, err := l.leave(geo.Transit, target)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err == nil && !departure.Equal(tt.departure) {
+			t.Fatalf("[#%v] Unexpected departure, expected=%v, got=%v", idx, tt.departure, departure)
+		}
+	}
+}
+
+func TestLeaveCmd_Validate(t *testing.T) {
+	defer func(fn func() time.Time) { now = fn }(now)
+	at := time.Date(2017, time.May, 1, 6, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+
+	est := time.FixedZone("EST", -5*60*60)
+	conf := Configuration{Locations: map[string]Location{
+		"home":   {Address: "123 Main St", Zone: "UTC"},
+		"client": {Address: "321 Maple Ave", Lat: 43.6, Lng: -79.3},
+	}}
+	tz := &mockTimeZoner{timezoneFn: func(lat, lng float64, when time.Time) (*time.Location, error) {
+		return est, nil
+	}}
+
+	tests := []struct {
+		l         LeaveCmd
+		expectErr error
+		arrival   time.Time
+		buffer    time.Duration
+	}{
+		{LeaveCmd{CommuteCmd: CommuteCmd{Drive: true}, Arrive: "09:30"}, nil, time.Date(2017, time.May, 1, 14, 30, 0, 0, time.UTC), 0},
+		{LeaveCmd{CommuteCmd: CommuteCmd{Drive: true}, Arrive: "09:30", Buffer: "10m"}, nil, time.Date(2017, time.May, 1, 14, 30, 0, 0, time.UTC), 10 * time.Minute},
+		{LeaveCmd{CommuteCmd: CommuteCmd{Drive: true}, Arrive: "tomorrow 8am", Buffer: "0s"}, nil, time.Date(2017, time.May, 2, 13, 0, 0, 0, time.UTC), 0},
+		{LeaveCmd{CommuteCmd: CommuteCmd{Drive: true}}, ErrLeaveArriveMissing, time.Time{}, 0},
+		{LeaveCmd{CommuteCmd: CommuteCmd{Drive: true}, Arrive: "09:30", Buffer: "-10m"}, ErrInvalidBuffer, time.Time{}, 0},
+		{LeaveCmd{CommuteCmd: CommuteCmd{Drive: true}, Arrive: "09:30", Buffer: "soon"}, ErrInvalidBuffer, time.Time{}, 0},
+		{LeaveCmd{CommuteCmd: CommuteCmd{Drive: true, DepartAt: "08:00"}, Arrive: "09:30"}, ErrLeaveScheduleProvided, time.Time{}, 0},
+		{LeaveCmd{CommuteCmd: CommuteCmd{Transit: true, ArriveBy: "08:00"}, Arrive: "09:30"}, ErrLeaveScheduleProvided, time.Time{}, 0},
+		{LeaveCmd{CommuteCmd: CommuteCmd{}, Arrive: "09:30"}, ErrNoCommuteMethod, time.Time{}, 0},
+	}
+
+	for idx, tt := range tests {
+		tt.l.From = "home"
+		tt.l.To = "client"
+		tt.l.TimeZoner = tz
+
+		err := tt.l.Validate(&conf)
+		if err != tt.expectErr {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.expectErr, err)
+		} else if !tt.l.arrival.Equal(tt.arrival) {
+			t.Fatalf("[#%v] Unexpected arrival, expected=%v, got=%v", idx, tt.arrival, tt.l.arrival)
+		} else if tt.l.buffer != tt.buffer {
+			t.Fatalf("[#%v] Unexpected buffer, expected=%v, got=%v", idx, tt.buffer, tt.l.buffer)
+		}
+	}
+}
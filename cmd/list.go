@@ -7,7 +7,8 @@ import (
 // ListCmd represents a request to list all locations.
 type ListCmd struct{}
 
-// Run lists all named aliases and their value.
+// Run lists all named aliases and their value, along with any route features
+// they override to avoid.
 func (l *ListCmd) Run(conf *Configuration, i Indicator) error {
 	names := make([]string, len(conf.Locations), len(conf.Locations))
 	var idx int
@@ -25,6 +26,11 @@ func (l *ListCmd) Run(conf *Configuration, i Indicator) error {
 	sort.Sort(byNameDefaultFirst(names))
 
 	for _, name := range names {
+		if avoid, ok := conf.LocationAvoid[name]; ok {
+			i.Indicate("%*s: %v (avoid: %v)", maxLen, name, conf.Locations[name], avoid)
+			continue
+		}
+
 		i.Indicate("%*s: %v", maxLen, name, conf.Locations[name])
 	}
 
@@ -7,25 +7,25 @@ import (
 
 func TestListCmd_Run(t *testing.T) {
 	tests := []struct {
-		locs   map[string]string
+		locs   map[string]Location
 		expect [][2]string
 	}{
 		{
-			map[string]string{"aname": "value1", "bname": "value2"},
+			map[string]Location{"aname": {Address: "value1"}, "bname": {Address: "value2"}},
 			[][2]string{
 				{"aname", "value1"},
 				{"bname", "value2"},
 			},
 		},
 		{
-			map[string]string{"bname": "value1", "aname": "value2"},
+			map[string]Location{"bname": {Address: "value1"}, "aname": {Address: "value2"}},
 			[][2]string{
 				{"aname", "value2"},
 				{"bname", "value1"},
 			},
 		},
 		{
-			map[string]string{"bname": "value1", "aname": "value2", "default": "defaultval"},
+			map[string]Location{"bname": {Address: "value1"}, "aname": {Address: "value2"}, "default": {Address: "defaultval"}},
 			[][2]string{
 				{"default", "defaultval"},
 				{"aname", "value2"},
@@ -37,7 +37,7 @@ func TestListCmd_Run(t *testing.T) {
 	for idx, tt := range tests {
 		var l ListCmd
 		var m mockIndicator
-		conf := Configuration{Locations: tt.locs}
+		conf := Configuration{Locations: tt.locs, LocationAvoid: map[string]string{"missing": "tolls"}}
 
 		if err := l.Run(&conf, &m); err != nil {
 			t.Fatal(err)
@@ -58,13 +58,36 @@ func TestListCmd_Run(t *testing.T) {
 	}
 }
 
+func TestListCmd_Run_avoid(t *testing.T) {
+	var l ListCmd
+	var m mockIndicator
+	conf := Configuration{
+		Locations:     map[string]Location{"default": {Address: "home"}, "work": {Address: "office"}},
+		LocationAvoid: map[string]string{"work": "highways"},
+	}
+
+	if err := l.Run(&conf, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"default: home", "work: office (avoid: highways)"}
+	if len(m.out) != len(expect) {
+		t.Fatalf("Unexpected number of output lines, expected=%v, got=%v", len(expect), len(m.out))
+	}
+	for line := range expect {
+		if out := strings.TrimSpace(m.out[line]); out != expect[line] {
+			t.Fatalf("[Line %v] Unexpected output, expected=%v, got=%v", line, expect[line], out)
+		}
+	}
+}
+
 func TestListCmd_Validate(t *testing.T) {
 	tests := []struct {
 		conf Configuration
 	}{
 		{Configuration{}},
-		{Configuration{Locations: make(map[string]string)}},
-		{Configuration{Locations: map[string]string{"name": "value"}}},
+		{Configuration{Locations: make(map[string]Location)}},
+		{Configuration{Locations: map[string]Location{"name": {Address: "value"}}}},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"encoding/json"
+)
+
+// Location is a named location, stored with the canonical address
+// and coordinates it was geocoded to.
+type Location struct {
+	Address string
+	Lat     float64 `json:",omitempty"`
+	Lng     float64 `json:",omitempty"`
+
+	// Zone is the name of the location's time zone, cached the first
+	// time it's needed.
+	Zone string `json:",omitempty"`
+}
+
+// UnmarshalJSON decodes a Location, migrating locations that were stored
+// as a plain address by earlier versions of commuter.
+func (l *Location) UnmarshalJSON(b []byte) error {
+	var address string
+	if err := json.Unmarshal(b, &address); err == nil {
+		*l = Location{Address: address}
+		return nil
+	}
+
+	// Decode into an alias type to avoid recursively calling UnmarshalJSON.
+	type location Location
+	return json.Unmarshal(b, (*location)(l))
+}
+
+// String returns the address of the Location.
+func (l Location) String() string {
+	return l.Address
+}
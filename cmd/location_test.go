@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLocation_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect map[string]Location
+		err    bool
+	}{
+		{`{"home": "123 Main St"}`, map[string]Location{"home": {Address: "123 Main St"}}, false},
+		{`{"home": {"Address": "123 Main St, Toronto, ON", "Lat": 43.6, "Lng": -79.3}}`, map[string]Location{"home": {Address: "123 Main St, Toronto, ON", Lat: 43.6, Lng: -79.3}}, false},
+		{`{"home": "123 Main St", "work": {"Address": "321 Maple Ave"}}`, map[string]Location{"home": {Address: "123 Main St"}, "work": {Address: "321 Maple Ave"}}, false},
+		{`{"home": 123}`, nil, true},
+	}
+
+	for idx, tt := range tests {
+		var locs map[string]Location
+		err := json.Unmarshal([]byte(tt.in), &locs)
+		if (err != nil) != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(locs, tt.expect) {
+			t.Fatalf("[#%v] Unexpected Locations, expected=%v, got=%v", idx, tt.expect, locs)
+		}
+	}
+}
+
+func TestConfiguration_migrate(t *testing.T) {
+	old := `{"APIKey": "key", "Locations": {"default": "123 Main St", "work": "321 Maple Ave"}}`
+
+	var conf Configuration
+	if err := json.Unmarshal([]byte(old), &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var migrated Configuration
+	if err := json.Unmarshal(b, &migrated); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]Location{"default": {Address: "123 Main St"}, "work": {Address: "321 Maple Ave"}}
+	if !reflect.DeepEqual(migrated.Locations, expect) {
+		t.Fatalf("Unexpected Locations, expected=%v, got=%v", expect, migrated.Locations)
+	}
+}
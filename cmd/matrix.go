@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+var (
+	// ErrMatrixFromMissing is returned when running the matrix command and the -from argument is missing.
+	ErrMatrixFromMissing = errors.New("missing -from or -all parameter")
+	// ErrMatrixToMissing is returned when running the matrix command and the -to argument is missing.
+	ErrMatrixToMissing = errors.New("missing -to or -all parameter")
+	// ErrNoLocations is returned when the -all argument is used without any named locations.
+	ErrNoLocations = errors.New("no named locations, add one with 'commuter add'")
+)
+
+// MatrixCmd represents a command to retrieve the commute time between
+// each of a set of origins and destinations.
+//
+// MatrixCmd uses the commute methods and options of the CommuteCmd it embeds,
+// with the Origins and Destinations taking the place of the From and To locations.
+type MatrixCmd struct {
+	CommuteCmd
+
+	Origins      []string
+	Destinations []string
+	All          bool
+
+	Matrixer Matrixer
+
+	origins      []string
+	destinations []string
+}
+
+// Run calculates the duration between each of the Origins and Destinations,
+// and outputs them as a grid for each commute method.
+func (m *MatrixCmd) Run(conf *Configuration, i Indicator) error {
+	modes := m.modes()
+	multiMode := len(modes) > 1
+
+	if s := m.describeSchedule(); len(s) > 0 {
+		i.Indicate("%v", s)
+	}
+
+	for n, mode := range modes {
+		estimates, err := m.Matrixer.Matrix(m.origins, m.destinations, mode, m.opts)
+		if err != nil {
+			return err
+		}
+
+		if multiMode {
+			if n > 0 {
+				i.Indicate("")
+			}
+			i.Indicate("%v:", mode)
+		}
+
+		var buf bytes.Buffer
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "\t%v\n", strings.Join(m.Destinations, "\t"))
+		for o, row := range estimates {
+			cells := make([]string, len(row))
+			for d, e := range row {
+				cells[d] = unavailableColumn
+				if e != nil && m.Origins[o] != m.Destinations[d] {
+					cells[d] = m.formatDuration(e.Duration)
+				}
+			}
+
+			fmt.Fprintf(w, "%v\t%v\n", m.Origins[o], strings.Join(cells, "\t"))
+		}
+		w.Flush()
+
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			i.Indicate("%v", strings.TrimRight(line, " "))
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the MatrixCmd is properly initialized and ready to be Run.
+func (m *MatrixCmd) Validate(conf *Configuration) (err error) {
+	if len(m.modes()) == 0 {
+		return ErrNoCommuteMethod
+	}
+	caps := m.capabilities()
+	if !caps.Matrix {
+		return ErrMatrixUnsupported
+	}
+	if m.Transit && !caps.Transit {
+		return ErrTransitUnsupported
+	}
+
+	m.opts, err = m.parseSchedule()
+	if err != nil {
+		return
+	}
+
+	m.opts.Avoid, m.opts.DefaultAvoid, err = m.avoid(conf)
+	if err != nil {
+		return
+	}
+
+	m.opts.Units, err = m.units(conf)
+	if err != nil {
+		return
+	}
+
+	m.opts.TransitModes, m.opts.TransitPreference, err = m.transit(conf)
+	if err != nil {
+		return
+	}
+
+	if m.All {
+		names := make([]string, 0, len(conf.Locations))
+		for name := range conf.Locations {
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return ErrNoLocations
+		}
+		sort.Sort(byNameDefaultFirst(names))
+
+		if len(m.Origins) == 0 {
+			m.Origins = names
+		}
+		if len(m.Destinations) == 0 {
+			m.Destinations = names
+		}
+	}
+
+	if len(m.Origins) == 0 {
+		return ErrMatrixFromMissing
+	} else if len(m.Destinations) == 0 {
+		return ErrMatrixToMissing
+	}
+
+	if m.origins, err = m.resolve(conf, m.Origins); err != nil {
+		return
+	}
+	m.destinations, err = m.resolve(conf, m.Destinations)
+	return
+}
+
+// resolve returns the locations for a list of named locations or addresses, which are
+// resolved to coordinates when the routing provider doesn't support geocoding.
+func (m *MatrixCmd) resolve(conf *Configuration, values []string) ([]string, error) {
+	geocoding := m.capabilities().Geocoding
+
+	locations := make([]string, len(values))
+	for n, v := range values {
+		locations[n] = m.alias(conf, v)
+		if geocoding {
+			continue
+		}
+
+		lat, lng, ok := m.coordinates(conf, v, locations[n], false)
+		if !ok {
+			return nil, ErrCoordinatesRequired
+		}
+		locations[n] = geo.Position{Lat: lat, Lng: lng}.String()
+	}
+
+	return locations, nil
+}
+
+// String returns a string representation of the MatrixCmd.
+func (m *MatrixCmd) String() string {
+	return fmt.Sprintf("Matrix from '%v' to '%v'", strings.Join(m.Origins, ", "), strings.Join(m.Destinations, ", "))
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestMatrixCmd_Run(t *testing.T) {
+	var calls []geo.TravelMode
+	m := mockMatrixer{
+		matrixFn: func(from, to []string, tm geo.TravelMode, o geo.Options) ([][]*geo.Estimate, error) {
+			calls = append(calls, tm)
+
+			expectFrom := []string{"123 Main St", "1 Daycare Rd"}
+			expectTo := []string{"321 Maple Ave", "123 Main St"}
+			if !reflect.DeepEqual(from, expectFrom) {
+				t.Fatalf("Unexpected From, expected=%v, got=%v", expectFrom, from)
+			} else if !reflect.DeepEqual(to, expectTo) {
+				t.Fatalf("Unexpected To, expected=%v, got=%v", expectTo, to)
+			}
+
+			return [][]*geo.Estimate{
+				{{Duration: time.Minute * 32}, {}},
+				{{Duration: time.Minute * 75}, nil},
+			}, nil
+		},
+	}
+
+	conf := Configuration{Locations: map[string]Location{"home": {Address: "123 Main St"}, "work": {Address: "321 Maple Ave"}}}
+	c := MatrixCmd{
+		CommuteCmd:   CommuteCmd{Drive: true, Transit: true},
+		Origins:      []string{"home", "1 Daycare Rd"},
+		Destinations: []string{"work", "home"},
+		Matrixer:     &m,
+	}
+	if err := c.Validate(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	var i mockIndicator
+	if err := c.Run(&conf, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(calls, []geo.TravelMode{geo.Drive, geo.Transit}) {
+		t.Fatalf("Unexpected TravelModes, got=%v", calls)
+	}
+
+	table := []string{
+		"              work               home",
+		"home          32 Minutes         -",
+		"1 Daycare Rd  1 Hour 15 Minutes  -",
+	}
+	expect := append(append(append([]string{"Drive:"}, table...), "", "Transit:"), table...)
+	if !reflect.DeepEqual(i.out, expect) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+	}
+
+	// Error from Matrixer
+	{
+		testErr := errors.New("mock err")
+		m.matrixFn = func(from, to []string, tm geo.TravelMode, o geo.Options) ([][]*geo.Estimate, error) {
+			return nil, testErr
+		}
+
+		if err := c.Run(&conf, &mockIndicator{}); err != testErr {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", testErr, err)
+		}
+	}
+}
+
+func TestMatrixCmd_Validate(t *testing.T) {
+	conf := Configuration{Locations: map[string]Location{"work": {Address: "321 Maple Ave"}, "default": {Address: "123 Main St"}, "gym": {Address: "10 Gym Ave"}}}
+	tests := []struct {
+		cmd  MatrixCmd
+		conf Configuration
+		err  error
+
+		expectOrigins      []string
+		expectDestinations []string
+	}{
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true}, Origins: []string{"default"}, Destinations: []string{"work", "1 Yonge St"}}, conf, nil, []string{"default"}, []string{"work", "1 Yonge St"}},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true}, All: true}, conf, nil, []string{"default", "gym", "work"}, []string{"default", "gym", "work"}},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true}, Origins: []string{"1 Yonge St"}, All: true}, conf, nil, []string{"1 Yonge St"}, []string{"default", "gym", "work"}},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true}, All: true}, Configuration{}, ErrNoLocations, nil, nil},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true}, Destinations: []string{"work"}}, conf, ErrMatrixFromMissing, nil, nil},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true}, Origins: []string{"work"}}, conf, ErrMatrixToMissing, nil, nil},
+		{MatrixCmd{Origins: []string{"work"}, Destinations: []string{"gym"}}, conf, ErrNoCommuteMethod, nil, nil},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Walk: true, Avoid: "tolls"}, Origins: []string{"work"}, Destinations: []string{"gym"}}, conf, ErrAvoidRequiresDrive, nil, nil},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true, Durationer: &mockProvider{caps: geo.Capabilities{Geocoding: true}}}, Origins: []string{"work"}, Destinations: []string{"gym"}}, conf, ErrMatrixUnsupported, nil, nil},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Transit: true, Durationer: &mockProvider{caps: geo.Capabilities{Matrix: true}}}, Origins: []string{"work"}, Destinations: []string{"gym"}}, conf, ErrTransitUnsupported, nil, nil},
+		{MatrixCmd{CommuteCmd: CommuteCmd{Drive: true, Durationer: &mockProvider{caps: geo.Capabilities{Matrix: true}}}, Origins: []string{"work"}, Destinations: []string{"gym"}}, conf, ErrCoordinatesRequired, nil, nil},
+	}
+
+	for idx, tt := range tests {
+		c := tt.cmd
+		if err := c.Validate(&tt.conf); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(c.Origins, tt.expectOrigins) {
+			t.Fatalf("[#%v] Unexpected Origins, expected=%v, got=%v", idx, tt.expectOrigins, c.Origins)
+		} else if !reflect.DeepEqual(c.Destinations, tt.expectDestinations) {
+			t.Fatalf("[#%v] Unexpected Destinations, expected=%v, got=%v", idx, tt.expectDestinations, c.Destinations)
+		}
+
+		for n, o := range c.Origins {
+			if expect := c.alias(&tt.conf, o); c.origins[n] != expect {
+				t.Fatalf("[#%v] Unexpected resolved origin, expected=%v, got=%v", idx, expect, c.origins[n])
+			}
+		}
+	}
+
+	// Locations are resolved to coordinates for providers without geocoding.
+	conf = Configuration{Locations: map[string]Location{"home": {Address: "123 Main St", Lat: 43.6, Lng: -79.3}}}
+	c := MatrixCmd{CommuteCmd: CommuteCmd{Drive: true, Durationer: &mockProvider{caps: geo.Capabilities{Matrix: true}}}, Origins: []string{"home"}, Destinations: []string{"37.7,-122.4"}}
+	if err := c.Validate(&conf); err != nil {
+		t.Fatal(err)
+	} else if expect := []string{"43.6,-79.3"}; !reflect.DeepEqual(c.origins, expect) {
+		t.Fatalf("Unexpected resolved origins, expected=%v, got=%v", expect, c.origins)
+	} else if expect := []string{"37.7,-122.4"}; !reflect.DeepEqual(c.destinations, expect) {
+		t.Fatalf("Unexpected resolved destinations, expected=%v, got=%v", expect, c.destinations)
+	}
+}
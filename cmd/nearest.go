@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+const (
+	// nearestPrefix is the prefix of a location expression that resolves to the nearest place
+	// of a category, such as "nearest:pharmacy".
+	nearestPrefix = "nearest:"
+
+	// maxNearestCandidates is the number of nearby places compared to find the quickest to reach.
+	maxNearestCandidates = 5
+)
+
+var (
+	// ErrNearestCategoryMissing is returned when a nearest: location is provided without a category.
+	ErrNearestCategoryMissing = errors.New("missing category for nearest: location [ex. 'nearest:pharmacy']")
+	// ErrNearestBothProvided is returned when both the -from and -to arguments are nearest: locations.
+	ErrNearestBothProvided = errors.New("cannot use nearest: locations for both -from and -to arguments")
+	// ErrNearestOriginUnknown is returned when the location a nearest: location is relative to can't be found.
+	ErrNearestOriginUnknown = errors.New("unable to determine the location to search for the nearest place from")
+)
+
+// nearestCategory returns the category of a nearest: location expression, and
+// whether or not the value is one.
+func nearestCategory(value string) (string, bool) {
+	if !strings.HasPrefix(value, nearestPrefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(value, nearestPrefix)), true
+}
+
+// resolveNearest resolves a nearest: location provided for either the From or To location to the
+// place of that category that's quickest to reach from, or return to, the other location.
+//
+// Nearby places are searched for around the other location, and the durations to each of the
+// closest candidates are compared with a single Matrix request.
+func (c *CommuteCmd) resolveNearest(conf *Configuration, fromName, toName string) error {
+	fromCategory, fromNearest := nearestCategory(c.From)
+	toCategory, toNearest := nearestCategory(c.To)
+
+	switch {
+	case fromNearest && toNearest:
+		return ErrNearestBothProvided
+	case fromNearest:
+		place, err := c.nearest(conf, fromCategory, toName, c.To, c.ToCurrent, false)
+		if err != nil {
+			return err
+		}
+		c.From = place.String()
+	case toNearest:
+		place, err := c.nearest(conf, toCategory, fromName, c.From, c.FromCurrent, true)
+		if err != nil {
+			return err
+		}
+		c.To = place.String()
+	}
+
+	return nil
+}
+
+// nearest finds the place of a category that's quickest to reach from the location provided,
+// or to return to it from when outbound is false.
+func (c *CommuteCmd) nearest(conf *Configuration, category, name, address string, current, outbound bool) (*geo.Place, error) {
+	if len(category) == 0 {
+		return nil, ErrNearestCategoryMissing
+	}
+
+	lat, lng, ok := c.coordinates(conf, name, address, current)
+	if !ok {
+		return nil, ErrNearestOriginUnknown
+	}
+
+	places, err := c.NearbySearcher.Nearby(lat, lng, category)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) > maxNearestCandidates {
+		places = places[:maxNearestCandidates]
+	}
+
+	candidates := make([]string, len(places))
+	for i, p := range places {
+		candidates[i] = geo.Position{Lat: p.Lat, Lng: p.Lng}.String()
+	}
+
+	origins, destinations := []string{address}, candidates
+	if !outbound {
+		origins, destinations = candidates, []string{address}
+	}
+
+	res, err := c.Matrixer.Matrix(origins, destinations, c.modes()[0], c.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	best := -1
+	var fastest *geo.Estimate
+	for i := range places {
+		var e *geo.Estimate
+		if outbound {
+			e = res[0][i]
+		} else {
+			e = res[i][0]
+		}
+
+		if e != nil && (fastest == nil || e.Duration < fastest.Duration) {
+			best, fastest = i, e
+		}
+	}
+	if best < 0 {
+		return nil, geo.ErrUnavailable
+	}
+
+	place := places[best]
+	c.nearby = fmt.Sprintf("Nearest %v: %v", category, place)
+	return &place, nil
+}
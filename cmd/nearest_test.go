@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestCommuteCmd_Validate_nearest(t *testing.T) {
+	conf := Configuration{Locations: map[string]Location{
+		"work": {Address: "321 Maple Ave", Lat: 43.6, Lng: -79.3},
+	}}
+	places := []geo.Place{
+		{Name: "Closest Pharmacy", Address: "1 King St", Lat: 43.61, Lng: -79.31},
+		{Name: "Quickest Pharmacy", Address: "2 Queen St", Lat: 43.62, Lng: -79.32},
+	}
+	nearby := mockNearbySearcher{
+		nearbyFn: func(lat, lng float64, keyword string) ([]geo.Place, error) {
+			if lat != 43.6 || lng != -79.3 {
+				t.Fatalf("Unexpected coordinates, expected=43.6,-79.3, got=%v,%v", lat, lng)
+			} else if keyword != "pharmacy" {
+				t.Fatalf("Unexpected keyword, expected=%v, got=%v", "pharmacy", keyword)
+			}
+			return places, nil
+		},
+	}
+
+	tests := []struct {
+		from, to string
+
+		expectOrigins      []string
+		expectDestinations []string
+		matrix             [][]*geo.Estimate
+		expectFrom         string
+		expectTo           string
+	}{
+		{
+			"work", "nearest:pharmacy",
+			[]string{"321 Maple Ave"}, []string{"43.61,-79.31", "43.62,-79.32"},
+			[][]*geo.Estimate{{{Duration: time.Minute * 8}, {Duration: time.Minute * 5}}},
+			"321 Maple Ave", "Quickest Pharmacy, 2 Queen St",
+		},
+		{
+			"nearest:pharmacy", "work",
+			[]string{"43.61,-79.31", "43.62,-79.32"}, []string{"321 Maple Ave"},
+			[][]*geo.Estimate{{{Duration: time.Minute * 3}}, {nil}},
+			"Closest Pharmacy, 1 King St", "321 Maple Ave",
+		},
+	}
+
+	for idx, tt := range tests {
+		m := mockMatrixer{
+			matrixFn: func(from, to []string, tm geo.TravelMode, o geo.Options) ([][]*geo.Estimate, error) {
+				if !reflect.DeepEqual(from, tt.expectOrigins) {
+					t.Fatalf("[#%v] Unexpected origins, expected=%v, got=%v", idx, tt.expectOrigins, from)
+				} else if !reflect.DeepEqual(to, tt.expectDestinations) {
+					t.Fatalf("[#%v] Unexpected destinations, expected=%v, got=%v", idx, tt.expectDestinations, to)
+				} else if tm != geo.Walk {
+					t.Fatalf("[#%v] Unexpected TravelMode, expected=%v, got=%v", idx, geo.Walk, tm)
+				}
+				return tt.matrix, nil
+			},
+		}
+
+		c := CommuteCmd{From: tt.from, To: tt.to, Walk: true, NearbySearcher: &nearby, Matrixer: &m}
+		if err := c.Validate(&conf); err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		}
+
+		if c.From != tt.expectFrom {
+			t.Fatalf("[#%v] Unexpected From, expected=%v, got=%v", idx, tt.expectFrom, c.From)
+		} else if c.To != tt.expectTo {
+			t.Fatalf("[#%v] Unexpected To, expected=%v, got=%v", idx, tt.expectTo, c.To)
+		}
+	}
+
+	// Negative
+	{
+		e := errors.New("test err")
+		m := mockMatrixer{
+			matrixFn: func(from, to []string, tm geo.TravelMode, o geo.Options) ([][]*geo.Estimate, error) {
+				return [][]*geo.Estimate{{nil, nil}}, nil
+			},
+		}
+		failing := mockNearbySearcher{
+			nearbyFn: func(lat, lng float64, keyword string) ([]geo.Place, error) {
+				return nil, e
+			},
+		}
+
+		nTests := []struct {
+			c   CommuteCmd
+			err error
+		}{
+			{CommuteCmd{From: "nearest:pharmacy", To: "nearest:bank", Drive: true, NearbySearcher: &nearby, Matrixer: &m}, ErrNearestBothProvided},
+			{CommuteCmd{From: "work", To: "nearest:", Drive: true, NearbySearcher: &nearby, Matrixer: &m}, ErrNearestCategoryMissing},
+			{CommuteCmd{From: "123 Main St", To: "nearest:pharmacy", Drive: true, NearbySearcher: &nearby, Matrixer: &m}, ErrNearestOriginUnknown},
+			{CommuteCmd{From: "work", To: "nearest:pharmacy", Drive: true, NearbySearcher: &failing, Matrixer: &m}, e},
+			{CommuteCmd{From: "work", To: "nearest:pharmacy", Drive: true, NearbySearcher: &nearby, Matrixer: &m}, geo.ErrUnavailable},
+		}
+
+		for idx, tt := range nTests {
+			if err := tt.c.Validate(&conf); err != tt.err {
+				t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+			}
+		}
+	}
+}
+
+func TestCommuteCmd_Run_nearest(t *testing.T) {
+	c := CommuteCmd{From: "321 Maple Ave", To: "Pharmacy, 2 Queen St", Drive: true, nearby: "Nearest pharmacy: Pharmacy, 2 Queen St", Durationer: &mockDurationer{
+		durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+			return &geo.Estimate{Duration: time.Minute * 5}, nil
+		},
+	}}
+
+	var i mockIndicator
+	if err := c.Run(&Configuration{}, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"Nearest pharmacy: Pharmacy, 2 Queen St", "5 Minutes"}
+	if !reflect.DeepEqual(i.out, expect) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+	}
+}
+
+func TestParseLatLng(t *testing.T) {
+	tests := []struct {
+		in        string
+		expectLat float64
+		expectLng float64
+		expectErr bool
+	}{
+		{"43.6,-79.3", 43.6, -79.3, false},
+		{" 43.6 , -79.3 ", 43.6, -79.3, false},
+		{"123 Main St", 0, 0, true},
+		{"Toronto, ON", 0, 0, true},
+	}
+
+	for idx, tt := range tests {
+		lat, lng, err := parseLatLng(tt.in)
+		if (err != nil) != tt.expectErr {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.expectErr, err)
+		} else if lat != tt.expectLat || lng != tt.expectLng {
+			t.Fatalf("[#%v] Unexpected coordinates, expected=%v,%v, got=%v,%v", idx, tt.expectLat, tt.expectLng, lat, lng)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+var (
+	// ErrOfflineUnavailable is returned when the -offline argument is used without an Estimator.
+	ErrOfflineUnavailable = errors.New("offline estimates are unavailable")
+	// ErrOfflineOptionsUnsupported is returned when the -offline argument is used with options that require directions.
+	ErrOfflineOptionsUnsupported = errors.New("cannot use -offline with the -via, -alternatives or -elevation arguments")
+	// ErrOfflineLocationUnsupported is returned when the -offline argument is used with locations that require a network request to find.
+	ErrOfflineLocationUnsupported = errors.New("cannot use -offline with the -from-current or -to-current arguments, or nearest: locations")
+)
+
+// offline replaces the Durationer with the Estimator when the Offline option is
+// set, and removes every other dependency that makes network requests.
+func (c *CommuteCmd) offline() error {
+	if !c.Offline {
+		return nil
+	}
+	if c.Estimator == nil {
+		return ErrOfflineUnavailable
+	}
+	if len(c.Via) > 0 || c.Alternatives || c.Elevation {
+		return ErrOfflineOptionsUnsupported
+	}
+	_, fromNearest := nearestCategory(c.From)
+	_, toNearest := nearestCategory(c.To)
+	if c.FromCurrent || c.ToCurrent || fromNearest || toNearest {
+		return ErrOfflineLocationUnsupported
+	}
+
+	c.Durationer = c.Estimator
+	c.Director = nil
+	c.Locator = nil
+	c.ReverseGeocoder = nil
+	c.Geocoder = nil
+	c.TimeZoner = nil
+	c.Elevator = nil
+	c.NearbySearcher = nil
+	c.Matrixer = nil
+	return nil
+}
+
+// fallback stores the known coordinates of the From and To locations, so that the
+// Estimator can be used when the Durationer fails.
+//
+// The From and To names are those provided by the user, before any aliases were resolved.
+func (c *CommuteCmd) fallback(conf *Configuration, fromName, toName string) {
+	if c.Estimator == nil || c.Offline {
+		return
+	}
+
+	fromLat, fromLng, ok := c.knownCoordinates(conf, fromName, c.From, c.FromCurrent)
+	if !ok {
+		return
+	}
+	toLat, toLng, ok := c.knownCoordinates(conf, toName, c.To, c.ToCurrent)
+	if !ok {
+		return
+	}
+
+	c.fromPosition = &geo.Position{Lat: fromLat, Lng: fromLng}
+	c.toPosition = &geo.Position{Lat: toLat, Lng: toLng}
+}
+
+// duration returns the Estimate of the commute from the Durationer, falling back to
+// an offline Estimate when the Durationer fails and the coordinates of the From and
+// To locations are known.
+func (c *CommuteCmd) duration(m geo.TravelMode, opts geo.Options) (*geo.Estimate, error) {
+	e, err := c.Durationer.Duration(c.From, c.To, m, opts)
+	if err == nil || c.fromPosition == nil || c.toPosition == nil {
+		return e, err
+	}
+
+	if offline, oerr := c.Estimator.Duration(c.fromPosition.String(), c.toPosition.String(), m, opts); oerr == nil {
+		return offline, nil
+	}
+	return e, err
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestCommuteCmd_offline(t *testing.T) {
+	conf := Configuration{Locations: map[string]Location{
+		"home": {Address: "123 Main St", Lat: 43.6, Lng: -79.3},
+		"work": {Address: "321 Maple Ave", Lat: 43.7, Lng: -79.4},
+	}}
+
+	var estimated []string
+	estimator := &mockProvider{
+		mockDurationer: mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+			estimated = append(estimated, from, to)
+			return &geo.Estimate{Duration: 20 * time.Minute, Offline: true}, nil
+		}},
+		caps: geo.Capabilities{Transit: true},
+	}
+	online := &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+		t.Fatal("Unexpected online duration")
+		return nil, nil
+	}}
+	geocoder := &mockGeocoder{geocodeFn: func(address string) ([]geo.Place, error) {
+		t.Fatal("Unexpected geocode")
+		return nil, nil
+	}}
+
+	c := CommuteCmd{
+		From:       "home",
+		To:         "work",
+		Drive:      true,
+		DepartAt:   "08:00",
+		Offline:    true,
+		Durationer: online,
+		Estimator:  estimator,
+		Geocoder:   geocoder,
+		TimeZoner: &mockTimeZoner{timezoneFn: func(lat, lng float64, at time.Time) (*time.Location, error) {
+			t.Fatal("Unexpected time zone lookup")
+			return nil, nil
+		}},
+	}
+	if err := c.Validate(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	var i mockIndicator
+	if err := c.Run(&conf, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"43.6,-79.3", "43.7,-79.4"}
+	if !reflect.DeepEqual(estimated, expect) {
+		t.Fatalf("Unexpected estimated locations, expected=%v, got=%v", expect, estimated)
+	} else if out := i.out[len(i.out)-1]; out != "20 Minutes (estimated offline)" {
+		t.Fatalf("Unexpected output, expected=%v, got=%v", "20 Minutes (estimated offline)", out)
+	}
+
+	// Addresses can't be geocoded offline.
+	c = CommuteCmd{From: "home", To: "1 Market St", Drive: true, Offline: true, Durationer: online, Estimator: estimator, Geocoder: geocoder}
+	if err := c.Validate(&conf); err != ErrCoordinatesRequired {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrCoordinatesRequired, err)
+	}
+
+	tests := []struct {
+		c         CommuteCmd
+		expectErr error
+	}{
+		{CommuteCmd{From: "home", To: "work", Drive: true, Offline: true}, ErrOfflineUnavailable},
+		{CommuteCmd{From: "home", To: "work", Drive: true, Offline: true, Estimator: estimator, Alternatives: true}, ErrOfflineOptionsUnsupported},
+		{CommuteCmd{From: "home", To: "work", Bike: true, Offline: true, Estimator: estimator, Elevation: true}, ErrOfflineOptionsUnsupported},
+		{CommuteCmd{From: "home", To: "work", Drive: true, Offline: true, Estimator: estimator, Via: []string{"gym"}}, ErrOfflineOptionsUnsupported},
+		{CommuteCmd{FromCurrent: true, To: "work", Drive: true, Offline: true, Estimator: estimator}, ErrOfflineLocationUnsupported},
+		{CommuteCmd{From: "home", ToCurrent: true, Drive: true, Offline: true, Estimator: estimator}, ErrOfflineLocationUnsupported},
+		{CommuteCmd{From: "home", To: "nearest:pharmacy", Drive: true, Offline: true, Estimator: estimator}, ErrOfflineLocationUnsupported},
+		{CommuteCmd{From: "nearest:gym", To: "work", Drive: true, Offline: true, Estimator: estimator}, ErrOfflineLocationUnsupported},
+		{CommuteCmd{From: "home", To: "work", Drive: true, Offline: true, Estimator: estimator, Range: true}, ErrTrafficRangeUnsupported},
+	}
+
+	for idx, tt := range tests {
+		if err := tt.c.Validate(&conf); err != tt.expectErr {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.expectErr, err)
+		}
+	}
+}
+
+func TestCommuteCmd_duration(t *testing.T) {
+	conf := Configuration{Locations: map[string]Location{
+		"home": {Address: "123 Main St", Lat: 43.6, Lng: -79.3},
+		"work": {Address: "321 Maple Ave"},
+	}}
+
+	onlineErr := errors.New("network unreachable")
+	online := &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+		return nil, onlineErr
+	}}
+	estimator := &mockDurationer{durationFn: func(from, to string, tm geo.TravelMode, o geo.Options) (*geo.Estimate, error) {
+		if from != "43.6,-79.3" || to != "37.7,-122.4" {
+			t.Fatalf("Unexpected estimated locations, got=%v/%v", from, to)
+		}
+		return &geo.Estimate{Duration: time.Hour, Offline: true}, nil
+	}}
+
+	// Online failures fall back to the Estimator when coordinates are known.
+	c := CommuteCmd{From: "home", To: "37.7,-122.4", Drive: true, Durationer: online, Estimator: estimator}
+	if err := c.Validate(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := c.duration(geo.Drive, c.opts)
+	if err != nil {
+		t.Fatal(err)
+	} else if !e.Offline || e.Duration != time.Hour {
+		t.Fatalf("Unexpected Estimate, expected offline, got=%+v", e)
+	}
+
+	// Otherwise the online error is returned.
+	c = CommuteCmd{From: "home", To: "work", Drive: true, Durationer: online, Estimator: estimator}
+	if err := c.Validate(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.duration(geo.Drive, c.opts); err != onlineErr {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", onlineErr, err)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+var (
+	// ErrTransitUnsupported is returned when the transit commute method is used with a routing provider that doesn't support it.
+	ErrTransitUnsupported = errors.New("the routing provider doesn't support the -transit commute method")
+	// ErrTrafficRangeUnsupported is returned when traffic ranges are requested from a routing provider that doesn't support them.
+	ErrTrafficRangeUnsupported = errors.New("the routing provider doesn't support traffic ranges, used by -range and -reliable")
+	// ErrDirectionsUnsupported is returned when directions are requested from a routing provider that doesn't support them.
+	ErrDirectionsUnsupported = errors.New("the routing provider doesn't support directions, used by the directions command and -via, -alternatives and -elevation")
+	// ErrNearestUnsupported is returned when a nearest: location is used with a routing provider that can't search for places.
+	ErrNearestUnsupported = errors.New("the routing provider doesn't support searching for places, used by nearest: locations")
+	// ErrMatrixUnsupported is returned when a matrix is requested from a routing provider that doesn't support it.
+	ErrMatrixUnsupported = errors.New("the routing provider doesn't support estimating a matrix of commutes")
+	// ErrCoordinatesRequired is returned when a location can't be resolved to coordinates for a routing provider without geocoding.
+	ErrCoordinatesRequired = errors.New("the routing provider requires coordinates, use a named location or a 'lat,lng' instead of an address")
+)
+
+// capabilities returns the Capabilities of the Durationer, which are assumed to
+// be complete when it doesn't describe them.
+func (c *CommuteCmd) capabilities() geo.Capabilities {
+	if p, ok := c.Durationer.(Capable); ok {
+		return p.Capabilities()
+	}
+
+	return geo.Capabilities{
+		Traffic:      true,
+		TrafficRange: true,
+		Transit:      true,
+		Geocoding:    true,
+		Location:     true,
+		Directions:   true,
+		Matrix:       true,
+		Places:       true,
+	}
+}
+
+// capable validates the Durationer supports the directions and nearby places required
+// by the options of the commute, so that they aren't requested from another service.
+func (c *CommuteCmd) capable() error {
+	caps := c.capabilities()
+	if (len(c.Via) > 0 || c.Alternatives || c.Elevation) && !caps.Directions {
+		return ErrDirectionsUnsupported
+	}
+
+	_, fromNearest := nearestCategory(c.From)
+	_, toNearest := nearestCategory(c.To)
+	if (fromNearest || toNearest) && (!caps.Places || !caps.Matrix) {
+		return ErrNearestUnsupported
+	}
+
+	return nil
+}
+
+// supported validates the Durationer supports the options of the commute, and
+// resolves the From and To locations to coordinates when it doesn't support geocoding.
+//
+// The From and To names are those provided by the user, so that the stored coordinates
+// of named locations are used.
+func (c *CommuteCmd) supported(conf *Configuration, fromName, toName string) error {
+	caps := c.capabilities()
+	if c.Transit && !caps.Transit {
+		return ErrTransitUnsupported
+	}
+	if c.opts.TrafficRange && !caps.TrafficRange {
+		return ErrTrafficRangeUnsupported
+	}
+	if caps.Geocoding {
+		return nil
+	}
+
+	fromLat, fromLng, ok := c.coordinates(conf, fromName, c.From, c.FromCurrent)
+	if !ok {
+		return ErrCoordinatesRequired
+	}
+	toLat, toLng, ok := c.coordinates(conf, toName, c.To, c.ToCurrent)
+	if !ok {
+		return ErrCoordinatesRequired
+	}
+
+	c.From = geo.Position{Lat: fromLat, Lng: fromLng}.String()
+	c.To = geo.Position{Lat: toLat, Lng: toLng}.String()
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+type mockProvider struct {
+	mockDurationer
+	caps geo.Capabilities
+}
+
+func (m *mockProvider) Capabilities() geo.Capabilities {
+	return m.caps
+}
+
+func TestCommuteCmd_supported(t *testing.T) {
+	conf := Configuration{Locations: map[string]Location{
+		"home": {Address: "123 Main St", Lat: 43.6, Lng: -79.3},
+		"work": {Address: "321 Maple Ave"},
+	}}
+	full := geo.Capabilities{Traffic: true, TrafficRange: true, Transit: true, Geocoding: true}
+
+	tests := []struct {
+		c          CommuteCmd
+		caps       *geo.Capabilities
+		expectErr  error
+		expectFrom string
+		expectTo   string
+	}{
+		// Durationers that don't describe their capabilities support everything.
+		{CommuteCmd{From: "home", To: "1 Market St", Transit: true}, nil, nil, "123 Main St", "1 Market St"},
+		{CommuteCmd{From: "home", To: "1 Market St", Drive: true, Range: true}, &full, nil, "123 Main St", "1 Market St"},
+
+		{CommuteCmd{From: "home", To: "1 Market St", Transit: true}, &geo.Capabilities{Geocoding: true}, ErrTransitUnsupported, "", ""},
+		{CommuteCmd{From: "home", To: "1 Market St", Drive: true, Range: true}, &geo.Capabilities{Traffic: true, Geocoding: true}, ErrTrafficRangeUnsupported, "", ""},
+		{CommuteCmd{From: "home", To: "1 Market St", Drive: true, DepartAt: "08:00"}, &geo.Capabilities{Geocoding: true}, nil, "123 Main St", "1 Market St"},
+
+		// Directions and places aren't requested from another service.
+		{CommuteCmd{From: "home", To: "1 Market St", Drive: true, Via: []string{"gym"}}, &geo.Capabilities{Geocoding: true, Matrix: true}, ErrDirectionsUnsupported, "", ""},
+		{CommuteCmd{From: "home", To: "1 Market St", Drive: true, Alternatives: true}, &geo.Capabilities{Geocoding: true}, ErrDirectionsUnsupported, "", ""},
+		{CommuteCmd{From: "home", To: "1 Market St", Bike: true, Elevation: true}, &geo.Capabilities{Geocoding: true}, ErrDirectionsUnsupported, "", ""},
+		{CommuteCmd{From: "home", To: "nearest:pharmacy", Drive: true}, &geo.Capabilities{Geocoding: true, Matrix: true}, ErrNearestUnsupported, "", ""},
+		{CommuteCmd{From: "nearest:gym", To: "home", Drive: true}, &geo.Capabilities{Geocoding: true, Places: true}, ErrNearestUnsupported, "", ""},
+
+		// Locations are resolved to coordinates without geocoding.
+		{CommuteCmd{From: "home", To: "37.7, -122.4", Drive: true}, &geo.Capabilities{}, nil, "43.6,-79.3", "37.7,-122.4"},
+		{CommuteCmd{From: "home", To: "1 Market St", Drive: true}, &geo.Capabilities{}, ErrCoordinatesRequired, "", ""},
+		{CommuteCmd{From: "work", To: "home", Drive: true}, &geo.Capabilities{}, ErrCoordinatesRequired, "", ""},
+	}
+
+	for idx, tt := range tests {
+		tt.c.Durationer = &mockDurationer{}
+		if tt.caps != nil {
+			tt.c.Durationer = &mockProvider{caps: *tt.caps}
+		}
+
+		err := tt.c.Validate(&conf)
+		if err != tt.expectErr {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.expectErr, err)
+		} else if err != nil {
+			continue
+		}
+
+		if tt.c.From != tt.expectFrom {
+			t.Fatalf("[#%v] Unexpected From, expected=%v, got=%v", idx, tt.expectFrom, tt.c.From)
+		} else if tt.c.To != tt.expectTo {
+			t.Fatalf("[#%v] Unexpected To, expected=%v, got=%v", idx, tt.expectTo, tt.c.To)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSearchQueryMissing is returned when running the search command without a query.
+	ErrSearchQueryMissing = errors.New("missing search query [ex. commuter search \"cn tower\"]")
+)
+
+// SearchCmd represents a command to search for the places matching a query.
+//
+// When a Name is provided, the user is prompted to choose one of the places
+// using the Input, and it's added as a named location.
+type SearchCmd struct {
+	Query string
+	Name  string
+
+	Searcher Searcher
+	Input    Scanner
+	Store    StorageProvider
+}
+
+// Run searches for and outputs the places matching the Query, saving the chosen
+// place as a named location if a Name was provided.
+func (s *SearchCmd) Run(conf *Configuration, i Indicator) error {
+	places, err := s.Searcher.Search(s.Query)
+	if err != nil {
+		return err
+	}
+
+	if len(s.Name) == 0 {
+		for n, p := range places {
+			i.Indicate("%v. %v", n+1, p)
+		}
+		return nil
+	}
+
+	place := places[0]
+	if len(places) > 1 {
+		place, err = choosePlace(s.Input, i, places)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := saveLocation(conf, s.Store, s.Name, "", place); err != nil {
+		return err
+	}
+
+	i.Indicate("%v: %v", s.Name, place)
+	return nil
+}
+
+// Validate validates the SearchCmd is properly initialized and ready to be Run.
+func (s *SearchCmd) Validate(conf *Configuration) error {
+	if len(s.Query) == 0 {
+		return ErrSearchQueryMissing
+	}
+
+	return nil
+}
+
+// String returns a string representation of the SearchCmd.
+func (s *SearchCmd) String() string {
+	return fmt.Sprintf("Searching for '%v'", s.Query)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestSearchCmd_Run(t *testing.T) {
+	places := []geo.Place{
+		{Name: "CN Tower", Address: "290 Bremner Blvd, Toronto, ON", Lat: 43.64, Lng: -79.38},
+		{Name: "CN Tower Parking", Address: "Bremner Blvd, Toronto, ON", Lat: 43.64, Lng: -79.39},
+	}
+	searcher := mockSearcher{
+		searchFn: func(query string) ([]geo.Place, error) {
+			if query != "cn tower" {
+				t.Fatalf("Unexpected query, expected=%v, got=%v", "cn tower", query)
+			}
+			return places, nil
+		},
+	}
+
+	tests := []struct {
+		name  string
+		input []string
+
+		expectSave bool
+		expect     []string
+	}{
+		{"", nil, false, []string{"1. CN Tower, 290 Bremner Blvd, Toronto, ON", "2. CN Tower Parking, Bremner Blvd, Toronto, ON"}},
+		{"tower", []string{"2"}, true, []string{"1. CN Tower, 290 Bremner Blvd, Toronto, ON", "2. CN Tower Parking, Bremner Blvd, Toronto, ON", "> Choose a location: [1-2]", "tower: CN Tower Parking, Bremner Blvd, Toronto, ON"}},
+	}
+
+	for idx, tt := range tests {
+		var conf Configuration
+		var saved bool
+		m := mockStorageProvider{
+			saveFn: func(i interface{}) error {
+				saved = true
+				return nil
+			},
+		}
+		s := SearchCmd{Query: "cn tower", Name: tt.name, Searcher: &searcher, Input: &mockScanner{lines: tt.input}, Store: &m}
+
+		var i mockIndicator
+		if err := s.Run(&conf, &i); err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		}
+
+		if saved != tt.expectSave {
+			t.Fatalf("[#%v] Unexpected save, expected=%v, got=%v", idx, tt.expectSave, saved)
+		} else if !reflect.DeepEqual(i.out, tt.expect) {
+			t.Fatalf("[#%v] Unexpected output, expected=%q, got=%q", idx, tt.expect, i.out)
+		}
+
+		if tt.expectSave {
+			expect := Location{Address: places[1].Address, Lat: places[1].Lat, Lng: places[1].Lng}
+			if conf.Locations[tt.name] != expect {
+				t.Fatalf("[#%v] Unexpected value stored, expected=%v, got=%v", idx, expect, conf.Locations[tt.name])
+			}
+		}
+	}
+
+	// Negative
+	{
+		e := errors.New("test err")
+		s := SearchCmd{Query: "cn tower", Searcher: &mockSearcher{searchFn: func(query string) ([]geo.Place, error) {
+			return nil, e
+		}}}
+
+		if err := s.Run(&Configuration{}, &mockIndicator{}); err != e {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", e, err)
+		}
+
+		s = SearchCmd{Query: "cn tower", Name: "tower", Searcher: &searcher, Input: &mockScanner{lines: []string{"9"}}}
+		if err := s.Run(&Configuration{}, &mockIndicator{}); err != ErrInvalidChoice {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", ErrInvalidChoice, err)
+		}
+	}
+}
+
+func TestSearchCmd_Validate(t *testing.T) {
+	tests := []struct {
+		query string
+		err   error
+	}{
+		{"cn tower", nil},
+		{"", ErrSearchQueryMissing},
+	}
+
+	for idx, tt := range tests {
+		s := SearchCmd{Query: tt.query}
+		if err := s.Validate(nil); err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// timeDisplayLayout is the layout used when displaying a departure or arrival time to the user.
+	timeDisplayLayout = "Mon Jan 2 15:04"
+)
+
+var (
+	// ErrInvalidTime is returned when a departure or arrival time cannot be parsed.
+	ErrInvalidTime = errors.New("invalid time, expected a time [ex. '08:15', '5:30pm'], a day and time [ex. 'tomorrow 08:15', 'Mon 09:00'], a date and time [ex. '2017-05-01 08:15'] or a relative time [ex. 'in 30m', '+2h']")
+	// ErrTimeInPast is returned when a departure or arrival time is in the past.
+	ErrTimeInPast = errors.New("time cannot be in the past")
+
+	// now returns the current time, and can be replaced to provide a fixed clock.
+	now = time.Now
+
+	dateLayouts = []string{
+		"2006-01-02 15:04",
+		"2006-01-02T15:04",
+		time.RFC3339,
+	}
+	clockLayouts = []string{
+		"15:04",
+		"3:04pm",
+		"3pm",
+	}
+	relativeUnits = strings.NewReplacer(
+		"minutes", "m", "minute", "m", "mins", "m", "min", "m",
+		"hours", "h", "hour", "h", "hrs", "h", "hr", "h",
+		" ", "",
+	)
+	weekdays = map[string]time.Weekday{
+		"sun": time.Sunday, "sunday": time.Sunday,
+		"mon": time.Monday, "monday": time.Monday,
+		"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+		"wed": time.Wednesday, "wednesday": time.Wednesday,
+		"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+		"fri": time.Friday, "friday": time.Friday,
+		"sat": time.Saturday, "saturday": time.Saturday,
+	}
+)
+
+// parseTime parses a natural or relative time, such as 'tomorrow 08:15', 'Mon 9am'
+// or 'in 30m', relative to the time provided.
+//
+// A time of day without a day is assumed to be the next occurrence of that time.
+func parseTime(value string, from time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	// Absolute dates: '2017-05-01 08:15'
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, value, from.Location())
+		if err != nil {
+			continue
+		}
+
+		if t.Before(from) {
+			return time.Time{}, ErrTimeInPast
+		}
+		return t, nil
+	}
+
+	value = strings.ToLower(value)
+	if value == "now" {
+		return from, nil
+	}
+
+	// Relative times: 'in 30m', '+1h30m', 'in 2 hours'
+	if strings.HasPrefix(value, "in ") || strings.HasPrefix(value, "+") {
+		rel := strings.TrimPrefix(strings.TrimPrefix(value, "in "), "+")
+		d, err := time.ParseDuration(relativeUnits.Replace(rel))
+		if err != nil {
+			return time.Time{}, ErrInvalidTime
+		} else if d < 0 {
+			return time.Time{}, ErrTimeInPast
+		}
+
+		return from.Add(d), nil
+	}
+
+	// Days and times: '08:15', 'tomorrow 8:15am', 'mon 9:00'
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return time.Time{}, ErrInvalidTime
+	}
+
+	days := -1
+	switch day := fields[0]; day {
+	case "today":
+		days = 0
+	case "tomorrow":
+		days = 1
+	default:
+		if wd, ok := weekdays[day]; ok {
+			days = (int(wd) - int(from.Weekday()) + 7) % 7
+		}
+	}
+
+	clock := strings.Join(fields, "")
+	if days >= 0 {
+		clock = strings.Join(fields[1:], "")
+	}
+
+	c, err := parseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	offset := days
+	if offset < 0 {
+		offset = 0
+	}
+
+	t := time.Date(from.Year(), from.Month(), from.Day()+offset, c.Hour(), c.Minute(), 0, 0, from.Location())
+	if !t.Before(from) {
+		return t, nil
+	}
+
+	switch {
+	case days < 0:
+		// Time only, use the next occurrence.
+		return t.AddDate(0, 0, 1), nil
+	case fields[0] != "today" && days == 0:
+		// Weekday matching today, use the same day next week.
+		return t.AddDate(0, 0, 7), nil
+	}
+
+	return time.Time{}, ErrTimeInPast
+}
+
+// parseClock parses a time of day, such as '08:15', '8:15am' or '8am'.
+func parseClock(value string) (time.Time, error) {
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, ErrInvalidTime
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	// Monday, May 1st 2017 at 10:30
+	from := time.Date(2017, time.May, 1, 10, 30, 0, 0, time.UTC)
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2017, time.May, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		value  string
+		expect time.Time
+		err    error
+	}{
+		// Now and relative times
+		{"now", from, nil},
+		{" NOW ", from, nil},
+		{"in 30m", at(1, 11, 0), nil},
+		{"+1h15m", at(1, 11, 45), nil},
+		{"in 2 hours", at(1, 12, 30), nil},
+		{"in 45 minutes", at(1, 11, 15), nil},
+
+		// Times of day
+		{"11:00", at(1, 11, 0), nil},
+		{"10:30", at(1, 10, 30), nil},
+		{"08:15", at(2, 8, 15), nil},
+		{"5:30pm", at(1, 17, 30), nil},
+		{"9am", at(2, 9, 0), nil},
+		{"9 AM", at(2, 9, 0), nil},
+
+		// Days and times
+		{"today 17:00", at(1, 17, 0), nil},
+		{"tomorrow 08:15", at(2, 8, 15), nil},
+		{"Tomorrow 8:15am", at(2, 8, 15), nil},
+		{"Mon 11:00", at(1, 11, 0), nil},
+		{"Mon 09:00", at(8, 9, 0), nil},
+		{"tue 09:00", at(2, 9, 0), nil},
+		{"Sunday 6pm", at(7, 18, 0), nil},
+
+		// Dates
+		{"2017-05-03 08:15", at(3, 8, 15), nil},
+		{"2017-05-03T08:15", at(3, 8, 15), nil},
+		{"2017-05-03T08:15:00Z", at(3, 8, 15), nil},
+
+		// Negative
+		{"", time.Time{}, ErrInvalidTime},
+		{"soon", time.Time{}, ErrInvalidTime},
+		{"in a while", time.Time{}, ErrInvalidTime},
+		{"someday 08:15", time.Time{}, ErrInvalidTime},
+		{"tomorrow", time.Time{}, ErrInvalidTime},
+		{"25:00", time.Time{}, ErrInvalidTime},
+		{"+-5m", time.Time{}, ErrTimeInPast},
+		{"today 08:15", time.Time{}, ErrTimeInPast},
+		{"2017-04-30 08:15", time.Time{}, ErrTimeInPast},
+	}
+
+	for idx, tt := range tests {
+		out, err := parseTime(tt.value, from)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error for '%v', expected=%v, got=%v", idx, tt.value, tt.err, err)
+		} else if !out.Equal(tt.expect) {
+			t.Fatalf("[#%v] Unexpected time for '%v', expected=%v, got=%v", idx, tt.value, tt.expect, out)
+		}
+	}
+}
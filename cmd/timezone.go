@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// zoneDisplayLayout is the layout used when displaying a departure or arrival time
+	// along with its time zone.
+	zoneDisplayLayout = timeDisplayLayout + " MST"
+)
+
+// localize interprets the DepartAt time in the time zone of the origin, and the ArriveBy
+// time in the time zone of the destination.
+//
+// Time zones are only determined when a departure or arrival time is provided, or when
+// the command requires them to interpret its own times.
+//
+// The From and To names are those provided by the user, before any aliases were resolved,
+// so that the time zone can be cached for named locations. When a time zone can't be
+// determined, times are interpreted in the local time zone.
+func (c *CommuteCmd) localize(conf *Configuration, fromName, toName string) (err error) {
+	if c.TimeZoner == nil || (len(c.DepartAt) == 0 && len(c.ArriveBy) == 0 && !c.zoned) {
+		return nil
+	}
+
+	at := now()
+	c.fromZone, err = c.zone(conf, fromName, c.From, c.FromCurrent, at)
+	if err != nil {
+		return
+	}
+	c.toZone, err = c.zone(conf, toName, c.To, c.ToCurrent, at)
+	if err != nil {
+		return
+	}
+
+	if len(c.DepartAt) > 0 {
+		c.opts.DepartAt, err = parseTime(c.DepartAt, at.In(c.fromZone))
+	}
+	if len(c.ArriveBy) > 0 {
+		c.opts.ArriveBy, err = parseTime(c.ArriveBy, at.In(c.toZone))
+	}
+
+	return
+}
+
+// originZone returns the time zone of the From location, or the local time zone
+// if it wasn't determined.
+func (c *CommuteCmd) originZone() *time.Location {
+	if c.fromZone == nil {
+		return time.Local
+	}
+	return c.fromZone
+}
+
+// destinationZone returns the time zone of the To location, or the local time zone
+// if it wasn't determined.
+func (c *CommuteCmd) destinationZone() *time.Location {
+	if c.toZone == nil {
+		return time.Local
+	}
+	return c.toZone
+}
+
+// zone determines the time zone of a location, preferring the time zone cached for a named
+// location, and otherwise looking it up by the location's coordinates.
+//
+// Time zones looked up for named locations are cached in the Configuration.
+func (c *CommuteCmd) zone(conf *Configuration, name, address string, current bool, at time.Time) (*time.Location, error) {
+	loc, named := conf.Locations[name]
+	named = named && !current
+	if named && len(loc.Zone) > 0 {
+		if z, err := time.LoadLocation(loc.Zone); err == nil {
+			return z, nil
+		}
+	}
+
+	lat, lng, ok := c.coordinates(conf, name, address, current)
+	if !ok {
+		return time.Local, nil
+	}
+
+	z, err := c.TimeZoner.Timezone(lat, lng, at)
+	if err != nil {
+		return time.Local, nil
+	}
+
+	if named && c.Store != nil {
+		loc.Zone = z.String()
+		conf.Locations[name] = loc
+		if err := c.Store.Save(conf); err != nil {
+			return nil, err
+		}
+	}
+
+	return z, nil
+}
+
+// describeTime returns a readable representation of a time in the time zone provided,
+// followed by the time in the other time zone when their offsets differ, such as
+// "Mon May 1 08:15 EDT (05:15 PDT at destination)".
+//
+// When no time zone was determined, the time is displayed without a zone.
+func describeTime(t time.Time, zone, other *time.Location, otherLabel string) string {
+	if zone == nil {
+		return t.Format(timeDisplayLayout)
+	}
+
+	local := t.In(zone)
+	out := local.Format(zoneDisplayLayout)
+	if other == nil {
+		return out
+	}
+
+	remote := t.In(other)
+	_, localOffset := local.Zone()
+	_, remoteOffset := remote.Zone()
+	if localOffset == remoteOffset {
+		return out
+	}
+
+	layout := "15:04 MST"
+	if remote.YearDay() != local.YearDay() {
+		layout = zoneDisplayLayout
+	}
+
+	return fmt.Sprintf("%v (%v at %v)", out, remote.Format(layout), otherLabel)
+}
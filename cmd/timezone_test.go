@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestCommuteCmd_Validate_timezone(t *testing.T) {
+	defer func(fn func() time.Time) { now = fn }(now)
+	at := time.Date(2017, time.May, 1, 6, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+
+	est := time.FixedZone("EST", -5*60*60)
+	pst := time.FixedZone("PST", -8*60*60)
+
+	// Zones are looked up by coordinates and cached for named locations.
+	{
+		conf := Configuration{Locations: map[string]Location{
+			"home": {Address: "123 Main St", Lat: 43.6, Lng: -79.3},
+		}}
+
+		var saved bool
+		c := CommuteCmd{
+			From:     "home",
+			To:       "1 Market St",
+			Drive:    true,
+			DepartAt: "08:15",
+			Geocoder: &mockGeocoder{geocodeFn: func(address string) ([]geo.Place, error) {
+				if address != "1 Market St" {
+					t.Fatalf("Unexpected address geocoded, expected=%v, got=%v", "1 Market St", address)
+				}
+				return []geo.Place{{Address: address, Lat: 37.7, Lng: -122.4}}, nil
+			}},
+			TimeZoner: &mockTimeZoner{timezoneFn: func(lat, lng float64, when time.Time) (*time.Location, error) {
+				if !when.Equal(at) {
+					t.Fatalf("Unexpected time, expected=%v, got=%v", at, when)
+				}
+				if lat == 43.6 && lng == -79.3 {
+					return est, nil
+				}
+				return pst, nil
+			}},
+			Store: &mockStorageProvider{saveFn: func(i interface{}) error {
+				saved = true
+				return nil
+			}},
+		}
+
+		if err := c.Validate(&conf); err != nil {
+			t.Fatal(err)
+		}
+
+		expect := time.Date(2017, time.May, 1, 13, 15, 0, 0, time.UTC)
+		if !c.opts.DepartAt.Equal(expect) {
+			t.Fatalf("Unexpected DepartAt, expected=%v, got=%v", expect, c.opts.DepartAt)
+		} else if conf.Locations["home"].Zone != "EST" {
+			t.Fatalf("Unexpected cached Zone, expected=%v, got=%v", "EST", conf.Locations["home"].Zone)
+		} else if !saved {
+			t.Fatal("Expected Configuration to be saved")
+		}
+
+		expectSchedule := "Departing Mon May 1 08:15 EST (05:15 PST at destination)"
+		if s := c.describeSchedule(); s != expectSchedule {
+			t.Fatalf("Unexpected schedule, expected=%v, got=%v", expectSchedule, s)
+		}
+	}
+
+	// Times are only in the past in the time zone of the origin.
+	for _, tt := range []struct {
+		departAt  string
+		expectErr error
+	}{
+		{"2017-05-01 05:00", nil},
+		{"2017-05-01 00:30", ErrTimeInPast},
+	} {
+		conf := Configuration{Locations: map[string]Location{
+			"home": {Address: "123 Main St", Zone: "EST"},
+			"work": {Address: "321 Maple Ave", Zone: "EST"},
+		}}
+
+		c := CommuteCmd{
+			From:      "home",
+			To:        "work",
+			Drive:     true,
+			DepartAt:  tt.departAt,
+			TimeZoner: &mockTimeZoner{},
+		}
+		if err := c.Validate(&conf); err != tt.expectErr {
+			t.Fatalf("Unexpected error for '%v', expected=%v, got=%v", tt.departAt, tt.expectErr, err)
+		}
+	}
+
+	// Cached zones are used without a lookup.
+	{
+		conf := Configuration{Locations: map[string]Location{
+			"home": {Address: "123 Main St", Zone: "UTC"},
+			"work": {Address: "321 Maple Ave", Zone: "UTC"},
+		}}
+
+		c := CommuteCmd{
+			From:     "home",
+			To:       "work",
+			Transit:  true,
+			ArriveBy: "09:00",
+			TimeZoner: &mockTimeZoner{timezoneFn: func(lat, lng float64, when time.Time) (*time.Location, error) {
+				t.Fatal("Unexpected time zone lookup")
+				return nil, nil
+			}},
+		}
+
+		if err := c.Validate(&conf); err != nil {
+			t.Fatal(err)
+		}
+
+		expectSchedule := "Arriving by Mon May 1 09:00 UTC"
+		if s := c.describeSchedule(); s != expectSchedule {
+			t.Fatalf("Unexpected schedule, expected=%v, got=%v", expectSchedule, s)
+		}
+	}
+
+	// Lookup errors fall back to the local time zone.
+	{
+		c := CommuteCmd{
+			From:     "123 Main St",
+			To:       "321 Maple Ave",
+			Drive:    true,
+			DepartAt: "08:15",
+			Geocoder: &mockGeocoder{geocodeFn: func(address string) ([]geo.Place, error) {
+				return []geo.Place{{Address: address, Lat: 1, Lng: 1}}, nil
+			}},
+			TimeZoner: &mockTimeZoner{timezoneFn: func(lat, lng float64, when time.Time) (*time.Location, error) {
+				return nil, errors.New("test err")
+			}},
+		}
+
+		if err := c.Validate(&Configuration{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if c.fromZone != time.Local || c.toZone != time.Local {
+			t.Fatalf("Unexpected zones, expected=%v, got=[%v, %v]", time.Local, c.fromZone, c.toZone)
+		}
+	}
+}
+
+func TestDescribeTime(t *testing.T) {
+	at := time.Date(2017, time.May, 1, 12, 15, 0, 0, time.UTC)
+	est := time.FixedZone("EST", -5*60*60)
+	edt := time.FixedZone("EDT", -5*60*60)
+	jst := time.FixedZone("JST", 9*60*60)
+	lint := time.FixedZone("LINT", 14*60*60)
+
+	tests := []struct {
+		zone   *time.Location
+		other  *time.Location
+		expect string
+	}{
+		{nil, nil, "Mon May 1 12:15"},
+		{est, nil, "Mon May 1 07:15 EST"},
+		{est, edt, "Mon May 1 07:15 EST"},
+		{jst, est, "Mon May 1 21:15 JST (07:15 EST at origin)"},
+		{est, jst, "Mon May 1 07:15 EST (21:15 JST at origin)"},
+		{est, lint, "Mon May 1 07:15 EST (Tue May 2 02:15 LINT at origin)"},
+	}
+
+	for idx, tt := range tests {
+		if out := describeTime(at, tt.zone, tt.other, "origin"); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+// legs retrieves the route for a TravelMode through each of the Via stops, and outputs
+// the duration of each leg followed by the total duration.
+func (c *CommuteCmd) legs(tm geo.TravelMode, i Indicator) error {
+	routes, err := c.Director.Directions(c.From, c.To, tm, c.opts)
+	if err != nil {
+		return err
+	} else if len(routes) == 0 {
+		return geo.ErrUnavailable
+	}
+	route := routes[0]
+
+	if s := c.describeOrder(route); len(s) > 0 {
+		i.Indicate("%v", s)
+	}
+
+	for _, l := range route.Legs {
+		e := geo.Estimate{Duration: l.Duration, Distance: l.Distance}
+		if l.DurationInTraffic > 0 {
+			e.Duration = l.DurationInTraffic
+			e.Traffic = true
+		}
+
+		i.Indicate("%v → %v: %v", l.StartAddress, l.EndAddress, c.format(&e))
+	}
+
+	i.Indicate("Total: %v", c.format(&route.Estimate))
+	return nil
+}
+
+// describeOrder returns the order the Via stops are visited in when they've been
+// optimized, such as "Stops: daycare, gym", or an empty string otherwise.
+func (c *CommuteCmd) describeOrder(r geo.Route) string {
+	if !c.Optimize || len(r.WaypointOrder) != len(c.Via) {
+		return ""
+	}
+
+	stops := make([]string, len(r.WaypointOrder))
+	for n, idx := range r.WaypointOrder {
+		stops[n] = c.Via[idx]
+	}
+
+	return fmt.Sprintf("Stops: %v", strings.Join(stops, ", "))
+}
+
+// stops validates the Via stops and resolves any named locations, in the same way
+// as the From and To locations.
+func (c *CommuteCmd) stops(conf *Configuration) ([]string, error) {
+	if len(c.Via) == 0 {
+		if c.Optimize {
+			return nil, ErrOptimizeRequiresVia
+		}
+		return nil, nil
+	}
+
+	switch {
+	case c.Transit:
+		return nil, ErrViaNotSupportedByTransit
+	case c.Range:
+		return nil, ErrViaAndRangeProvided
+	case c.Alternatives:
+		return nil, ErrViaAndAlternativesProvided
+	}
+
+	stops := make([]string, len(c.Via))
+	for n, v := range c.Via {
+		stops[n] = c.alias(conf, v)
+	}
+
+	return stops, nil
+}
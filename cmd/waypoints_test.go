@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestCommuteCmd_legs(t *testing.T) {
+	m := mockDirector{
+		directionsFn: func(from, to string, tm geo.TravelMode, o geo.Options) ([]geo.Route, error) {
+			expect := []string{"1 Daycare Rd", "10 Gym Ave"}
+			if !reflect.DeepEqual(o.Waypoints, expect) {
+				t.Fatalf("Unexpected Waypoints, expected=%v, got=%v", expect, o.Waypoints)
+			} else if !o.Optimize {
+				t.Fatal("Expected Optimize to be requested")
+			}
+
+			return []geo.Route{
+				{
+					Estimate:      geo.Estimate{Duration: time.Minute * 40, Traffic: true, Avoided: []geo.Avoid{geo.AvoidTolls}},
+					WaypointOrder: []int{1, 0},
+					Legs: []geo.Leg{
+						{StartAddress: "123 Main St", EndAddress: "10 Gym Ave", Duration: time.Minute * 8},
+						{StartAddress: "10 Gym Ave", EndAddress: "1 Daycare Rd", Duration: time.Minute * 10, DurationInTraffic: time.Minute * 12},
+						{StartAddress: "1 Daycare Rd", EndAddress: "321 Maple Ave", Duration: time.Minute * 20},
+					},
+				},
+			}, nil
+		},
+	}
+
+	conf := Configuration{Locations: map[string]Location{"home": {Address: "123 Main St"}, "work": {Address: "321 Maple Ave"}, "daycare": {Address: "1 Daycare Rd"}}}
+	c := CommuteCmd{From: "home", To: "work", Drive: true, Via: []string{"daycare", "10 Gym Ave"}, Optimize: true, Director: &m}
+	if err := c.Validate(&conf); err != nil {
+		t.Fatal(err)
+	}
+
+	var i mockIndicator
+	if err := c.Run(&conf, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"Stops: 10 Gym Ave, daycare",
+		"123 Main St → 10 Gym Ave: 8 Minutes",
+		"10 Gym Ave → 1 Daycare Rd: 12 Minutes (in traffic)",
+		"1 Daycare Rd → 321 Maple Ave: 20 Minutes",
+		"Total: 40 Minutes (in traffic, avoiding tolls)",
+	}
+	if !reflect.DeepEqual(i.out, expect) {
+		t.Fatalf("Unexpected output, expected=%q, got=%q", expect, i.out)
+	}
+}
+
+func TestCommuteCmd_stops(t *testing.T) {
+	conf := Configuration{Locations: map[string]Location{"daycare": {Address: "1 Daycare Rd"}}}
+	tests := []struct {
+		cmd    CommuteCmd
+		expect []string
+		err    error
+	}{
+		{CommuteCmd{Drive: true}, nil, nil},
+		{CommuteCmd{Drive: true, Via: []string{"daycare"}}, []string{"1 Daycare Rd"}, nil},
+		{CommuteCmd{Walk: true, Via: []string{"daycare", "10 Gym Ave"}, Optimize: true}, []string{"1 Daycare Rd", "10 Gym Ave"}, nil},
+		{CommuteCmd{Drive: true, Optimize: true}, nil, ErrOptimizeRequiresVia},
+		{CommuteCmd{Transit: true, Via: []string{"daycare"}}, nil, ErrViaNotSupportedByTransit},
+		{CommuteCmd{Drive: true, Range: true, Via: []string{"daycare"}}, nil, ErrViaAndRangeProvided},
+		{CommuteCmd{Drive: true, Alternatives: true, Via: []string{"daycare"}}, nil, ErrViaAndAlternativesProvided},
+	}
+
+	for idx, tt := range tests {
+		stops, err := tt.cmd.stops(&conf)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if !reflect.DeepEqual(stops, tt.expect) {
+			t.Fatalf("[#%v] Unexpected stops, expected=%v, got=%v", idx, tt.expect, stops)
+		}
+	}
+}
+
+func TestCommuteCmd_describeOrder(t *testing.T) {
+	tests := []struct {
+		via      []string
+		optimize bool
+		order    []int
+		expect   string
+	}{
+		{[]string{"a", "b", "c"}, true, []int{2, 0, 1}, "Stops: c, a, b"},
+		{[]string{"a", "b"}, true, []int{0, 1}, "Stops: a, b"},
+		{[]string{"a", "b"}, false, []int{1, 0}, ""},
+		{[]string{"a", "b"}, true, nil, ""},
+	}
+
+	for idx, tt := range tests {
+		c := CommuteCmd{Via: tt.via, Optimize: tt.optimize}
+		if out := c.describeOrder(geo.Route{WaypointOrder: tt.order}); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		}
+	}
+}
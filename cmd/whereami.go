@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"math"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+// WhereAmICmd represents a command to describe the current location,
+// and the named location it's nearest to.
+type WhereAmICmd struct {
+	Locator         Locator
+	ReverseGeocoder ReverseGeocoder
+
+	units geo.Units
+}
+
+// Run outputs the address and coordinates of the current location, followed by the
+// nearest named location that has coordinates, if any.
+func (w *WhereAmICmd) Run(conf *Configuration, i Indicator) error {
+	pos, err := w.Locator.CurrentLocation()
+	if err != nil {
+		return err
+	}
+
+	i.Indicate("%v", describePosition(w.ReverseGeocoder, pos))
+	i.Indicate("Coordinates: %v", pos)
+
+	var nearest string
+	minDist := math.MaxFloat64
+	for name, loc := range conf.Locations {
+		if loc.Lat == 0 && loc.Lng == 0 {
+			continue
+		}
+
+		// Break ties by name so the output is stable.
+		d := geo.Haversine(pos.Lat, pos.Lng, loc.Lat, loc.Lng)
+		if d < minDist || (d == minDist && name < nearest) {
+			nearest, minDist = name, d
+		}
+	}
+
+	if len(nearest) > 0 {
+		i.Indicate("Nearest named location: %v (%v away)", nearest, formatDistance(int(minDist), w.units))
+	}
+
+	return nil
+}
+
+// Validate validates the WhereAmICmd is properly initialized and ready to be Run.
+func (w *WhereAmICmd) Validate(conf *Configuration) (err error) {
+	units := conf.Units
+	if len(units) == 0 {
+		units = DefaultUnits
+	}
+
+	w.units, err = geo.ParseUnits(units)
+	return
+}
+
+// String returns a string representation of the WhereAmICmd.
+func (w *WhereAmICmd) String() string {
+	return "Where am I"
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+func TestWhereAmICmd_Run(t *testing.T) {
+	locator := mockLocator{
+		locateFn: func() (*geo.Position, error) {
+			return &geo.Position{Lat: 43.6532, Lng: -79.3832, Accuracy: 40}, nil
+		},
+	}
+	reverse := mockReverseGeocoder{
+		reverseFn: func(lat, lng float64) (*geo.Place, error) {
+			if lat != 43.6532 || lng != -79.3832 {
+				t.Fatalf("Unexpected coordinates, expected=43.6532,-79.3832, got=%v,%v", lat, lng)
+			}
+			return &geo.Place{Address: "100 Queen St W, Toronto, ON"}, nil
+		},
+	}
+
+	tests := []struct {
+		conf    Configuration
+		reverse ReverseGeocoder
+		expect  []string
+	}{
+		{
+			Configuration{},
+			&reverse,
+			[]string{"100 Queen St W, Toronto, ON (within 40 m)", "Coordinates: 43.6532,-79.3832"},
+		},
+		{
+			Configuration{Locations: map[string]Location{
+				"home":   {Address: "123 Main St", Lat: 43.6629, Lng: -79.3957},
+				"work":   {Address: "321 Maple Ave", Lat: 43.6544, Lng: -79.3807},
+				"legacy": {Address: "1 Yonge St"},
+			}},
+			&reverse,
+			[]string{"100 Queen St W, Toronto, ON (within 40 m)", "Coordinates: 43.6532,-79.3832", "Nearest named location: work (241 m away)"},
+		},
+		{
+			Configuration{Units: "imperial", Locations: map[string]Location{
+				"cottage": {Address: "Muskoka", Lat: 45.0, Lng: -79.3},
+			}},
+			nil,
+			[]string{"43.6532,-79.3832 (within 40 m)", "Coordinates: 43.6532,-79.3832", "Nearest named location: cottage (93.1 mi away)"},
+		},
+	}
+
+	for idx, tt := range tests {
+		w := WhereAmICmd{Locator: &locator, ReverseGeocoder: tt.reverse}
+		if err := w.Validate(&tt.conf); err != nil {
+			t.Fatal(err)
+		}
+
+		var i mockIndicator
+		if err := w.Run(&tt.conf, &i); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(i.out, tt.expect) {
+			t.Fatalf("[#%v] Unexpected output, expected=%q, got=%q", idx, tt.expect, i.out)
+		}
+	}
+
+	// Reverse geocoding failure falls back to coordinates
+	{
+		r := mockReverseGeocoder{
+			reverseFn: func(lat, lng float64) (*geo.Place, error) {
+				return nil, geo.ErrBadLocation
+			},
+		}
+		w := WhereAmICmd{Locator: &locator, ReverseGeocoder: &r}
+
+		var i mockIndicator
+		if err := w.Run(&Configuration{}, &i); err != nil {
+			t.Fatal(err)
+		} else if i.out[0] != "43.6532,-79.3832 (within 40 m)" {
+			t.Fatalf("Unexpected output, expected=%v, got=%v", "43.6532,-79.3832 (within 40 m)", i.out[0])
+		}
+	}
+
+	// Error from Locator
+	{
+		testErr := errors.New("mock err")
+		l := mockLocator{
+			locateFn: func() (*geo.Position, error) {
+				return nil, testErr
+			},
+		}
+		w := WhereAmICmd{Locator: &l}
+		if err := w.Run(&Configuration{}, &mockIndicator{}); err != testErr {
+			t.Fatalf("Unexpected error, expected=%v, got=%v", testErr, err)
+		}
+	}
+}
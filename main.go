@@ -11,6 +11,11 @@ import (
 const (
 	configurationFileName string = "config.json"
 	configurationDirName  string = "commuter"
+
+	// recordEnv and replayEnv are the environment variables containing the path
+	// of a cassette to record requests to, or replay responses from.
+	recordEnv string = "COMMUTER_RECORD"
+	replayEnv string = "COMMUTER_REPLAY"
 )
 
 func main() {
@@ -18,10 +23,13 @@ func main() {
 	store := storage.NewFileStore(configurationDirName, configurationFileName)
 	conf := cmd.NewConfiguration(store)
 	parser := cli.NewArgParser(os.Args[1:])
+	parser.Record = os.Getenv(recordEnv)
+	parser.Replay = os.Getenv(replayEnv)
 
 	r, err := parser.Parse(conf, store)
 	if err != nil {
-		panic(err)
+		out.Indicate("Error: %v", err)
+		os.Exit(1)
 	}
 
 	exec(out, conf, r)
@@ -32,6 +40,7 @@ func exec(i cmd.Indicator, c *cmd.Configuration, r cmd.RunnerValidator) {
 	if err := r.Validate(c); err != nil {
 		i.Indicate("Invalid command: %v", r)
 		i.Indicate("Error: %v", err)
+		return
 	}
 
 	if err := r.Run(c, i); err != nil {
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/KyleBanks/commuter/cmd"
+	"github.com/KyleBanks/commuter/pkg/geo"
+)
+
+type mockIndicator struct {
+	out []string
+}
+
+func (m *mockIndicator) Indicate(msg string, args ...interface{}) {
+	m.out = append(m.out, fmt.Sprintf(msg, args...))
+}
+
+type mockRunner struct {
+	validateErr error
+	runErr      error
+	ran         bool
+}
+
+func (m *mockRunner) Validate(c *cmd.Configuration) error {
+	return m.validateErr
+}
+
+func (m *mockRunner) Run(c *cmd.Configuration, i cmd.Indicator) error {
+	m.ran = true
+	return m.runErr
+}
+
+func (m *mockRunner) String() string {
+	return "mock"
+}
+
+func TestExec(t *testing.T) {
+	invalid := errors.New("invalid")
+	failed := errors.New("failed")
+
+	tests := []struct {
+		r      mockRunner
+		ran    bool
+		expect []string
+	}{
+		{mockRunner{}, true, nil},
+		{mockRunner{runErr: failed}, true, []string{"Command Failed: mock", "Error: failed"}},
+		{mockRunner{validateErr: invalid, runErr: failed}, false, []string{"Invalid command: mock", "Error: invalid"}},
+	}
+
+	for idx, tt := range tests {
+		var i mockIndicator
+		exec(&i, &cmd.Configuration{}, &tt.r)
+
+		if tt.r.ran != tt.ran {
+			t.Fatalf("[#%v] Unexpected ran, expected=%v, got=%v", idx, tt.ran, tt.r.ran)
+		} else if !reflect.DeepEqual(i.out, tt.expect) {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, i.out)
+		}
+	}
+}
+
+func TestExec_unsupported(t *testing.T) {
+	// Commands rejected by the provider's capabilities aren't run without the
+	// dependencies they require.
+	osrm := geo.NewOSRM("http://localhost:5000", nil)
+	commute := cmd.CommuteCmd{From: "43.6,-79.3", To: "43.7,-79.4", Drive: true, Durationer: osrm}
+
+	via := commute
+	via.Via = []string{"43.65,-79.35"}
+
+	tests := []struct {
+		r   cmd.RunnerValidator
+		err error
+	}{
+		{&cmd.DirectionsCmd{CommuteCmd: commute}, cmd.ErrDirectionsUnsupported},
+		{&via, cmd.ErrDirectionsUnsupported},
+	}
+
+	for idx, tt := range tests {
+		var i mockIndicator
+		exec(&i, &cmd.Configuration{}, tt.r)
+
+		if expect := "Error: " + tt.err.Error(); len(i.out) != 2 || i.out[1] != expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, expect, i.out)
+		}
+	}
+}
+
+func TestExec_offline(t *testing.T) {
+	// Options rejected offline aren't run without the dependencies removed by -offline.
+	offline := cmd.CommuteCmd{
+		From:      "43.6,-79.3",
+		To:        "43.7,-79.4",
+		Bike:      true,
+		Offline:   true,
+		Estimator: geo.NewOffline(geo.DefaultDetour, geo.DefaultSpeeds),
+	}
+
+	via, alternatives, elevation := offline, offline, offline
+	via.Via = []string{"43.65,-79.35"}
+	alternatives.Alternatives = true
+	elevation.Elevation = true
+
+	tests := []cmd.RunnerValidator{
+		&via,
+		&alternatives,
+		&elevation,
+		&cmd.DirectionsCmd{CommuteCmd: via},
+	}
+
+	for idx, r := range tests {
+		var i mockIndicator
+		exec(&i, &cmd.Configuration{}, r)
+
+		if expect := "Error: " + cmd.ErrOfflineOptionsUnsupported.Error(); len(i.out) != 2 || i.out[1] != expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, expect, i.out)
+		}
+	}
+}
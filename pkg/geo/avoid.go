@@ -0,0 +1,92 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+
+	"googlemaps.github.io/maps"
+)
+
+const (
+	// AvoidNone is used to explicitly avoid no features.
+	AvoidNone = "none"
+)
+
+var (
+	// ErrInvalidAvoid is returned when a list of features to avoid cannot be parsed.
+	ErrInvalidAvoid = errors.New("invalid avoid, expected a comma separated list of 'tolls', 'highways' and 'ferries', or 'none'")
+)
+
+// Avoid is a route feature to avoid when driving.
+type Avoid maps.Avoid
+
+var (
+	// AvoidTolls avoids toll roads and bridges.
+	AvoidTolls = Avoid(maps.AvoidTolls)
+	// AvoidHighways avoids highways.
+	AvoidHighways = Avoid(maps.AvoidHighways)
+	// AvoidFerries avoids ferries.
+	AvoidFerries = Avoid(maps.AvoidFerries)
+
+	avoidValues = map[string]Avoid{
+		string(AvoidTolls):    AvoidTolls,
+		string(AvoidHighways): AvoidHighways,
+		string(AvoidFerries):  AvoidFerries,
+	}
+)
+
+// ParseAvoid parses a comma separated list of features to avoid, such as
+// "tolls,highways". The value "none" returns an empty list.
+func ParseAvoid(value string) ([]Avoid, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) == 1 && strings.ToLower(strings.TrimSpace(parts[0])) == AvoidNone {
+		return nil, nil
+	}
+
+	var avoid []Avoid
+	for _, v := range parts {
+		v = strings.ToLower(strings.TrimSpace(v))
+
+		a, ok := avoidValues[v]
+		if !ok {
+			return nil, ErrInvalidAvoid
+		}
+
+		if !containsAvoid(avoid, a) {
+			avoid = append(avoid, a)
+		}
+	}
+
+	return avoid, nil
+}
+
+// FormatAvoid returns the comma separated representation of a list of features to avoid,
+// the inverse of ParseAvoid.
+func FormatAvoid(avoid []Avoid) string {
+	if len(avoid) == 0 {
+		return AvoidNone
+	}
+
+	return strings.Join(avoidStrings(avoid), ",")
+}
+
+// avoidStrings returns the string values of a list of features to avoid.
+func avoidStrings(avoid []Avoid) []string {
+	s := make([]string, len(avoid))
+	for i, a := range avoid {
+		s[i] = string(a)
+	}
+
+	return s
+}
+
+// containsAvoid returns true if the list of features contains the Avoid provided.
+func containsAvoid(avoid []Avoid, a Avoid) bool {
+	for _, v := range avoid {
+		if v == a {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+package geo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAvoid(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect []Avoid
+		err    error
+	}{
+		{"tolls", []Avoid{AvoidTolls}, nil},
+		{"Highways", []Avoid{AvoidHighways}, nil},
+		{"tolls,highways,ferries", []Avoid{AvoidTolls, AvoidHighways, AvoidFerries}, nil},
+		{" ferries , tolls ", []Avoid{AvoidFerries, AvoidTolls}, nil},
+		{"tolls,tolls", []Avoid{AvoidTolls}, nil},
+		{"none", nil, nil},
+		{"NONE", nil, nil},
+
+		{"", nil, ErrInvalidAvoid},
+		{"bridges", nil, ErrInvalidAvoid},
+		{"tolls,", nil, ErrInvalidAvoid},
+		{"none,tolls", nil, ErrInvalidAvoid},
+	}
+
+	for idx, tt := range tests {
+		avoid, err := ParseAvoid(tt.value)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if !reflect.DeepEqual(avoid, tt.expect) {
+			t.Fatalf("[#%v] Unexpected Avoid, expected=%v, got=%v", idx, tt.expect, avoid)
+		}
+	}
+}
+
+func TestFormatAvoid(t *testing.T) {
+	tests := []struct {
+		avoid  []Avoid
+		expect string
+	}{
+		{nil, "none"},
+		{[]Avoid{}, "none"},
+		{[]Avoid{AvoidTolls}, "tolls"},
+		{[]Avoid{AvoidHighways, AvoidFerries}, "highways,ferries"},
+	}
+
+	for idx, tt := range tests {
+		if out := FormatAvoid(tt.avoid); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// redacted replaces the API keys and other secrets written to a Cassette.
+	redacted = "REDACTED"
+)
+
+var (
+	// ErrCassetteMiss is returned when replaying a Cassette that has no recorded
+	// response for the request made.
+	ErrCassetteMiss = errors.New("no recorded response for request")
+
+	// redactedParams are the query parameters containing credentials, which are
+	// never written to a Cassette.
+	redactedParams = []string{"key", "signature", "client", "access_token"}
+)
+
+// Cassette is a recording of the HTTP requests made, and the responses received,
+// which can be replayed to run without network access.
+type Cassette struct {
+	Interactions []Interaction
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest
+	Response RecordedResponse
+}
+
+// RecordedRequest is a request stored in a Cassette, with its credentials redacted.
+type RecordedRequest struct {
+	Method string
+	URL    string
+	Body   string `json:",omitempty"`
+}
+
+// RecordedResponse is a response stored in a Cassette.
+type RecordedResponse struct {
+	StatusCode int
+	Header     http.Header `json:",omitempty"`
+	Body       string
+}
+
+// LoadCassette reads the Cassette stored at the path provided.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Save writes the Cassette to the path provided.
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+// Recorder is an http.RoundTripper that makes requests with an underlying RoundTripper,
+// and records each request and response to a Cassette.
+type Recorder struct {
+	path    string
+	base    http.RoundTripper
+	secrets []string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder initializes and returns a Recorder that writes its Cassette to the path
+// provided after every request. The secrets, such as an API key, are redacted wherever
+// they appear. The default transport is used when base is nil.
+func NewRecorder(path string, base http.RoundTripper, secrets ...string) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Recorder{
+		path:    path,
+		base:    base,
+		secrets: secrets,
+	}
+}
+
+// RoundTrip makes the request and records it, along with the response received.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    redactURL(req.URL, r.secrets),
+			Body:   redactString(body, r.secrets),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       redactString(string(b), r.secrets),
+		},
+	})
+	if err := r.cassette.Save(r.path); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper that serves the responses recorded to a Cassette,
+// without making any requests.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	played   []bool
+}
+
+// NewReplayer initializes and returns a Replayer serving the Cassette stored at the
+// path provided.
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Replayer{
+		cassette: c,
+		played:   make([]bool, len(c.Interactions)),
+	}, nil
+}
+
+// RoundTrip returns the recorded response to the request, matched by method, URL and
+// body. Identical requests are served in the order they were recorded, with the last
+// response repeated once each has been played.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	u := redactURL(req.URL, nil)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request.Method != req.Method || in.Request.URL != u || in.Request.Body != body {
+			continue
+		}
+
+		match = i
+		if !r.played[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, ErrCassetteMiss
+	}
+	r.played[match] = true
+
+	res := r.cassette.Interactions[match].Response
+	header := res.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode)),
+		StatusCode:    res.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(res.Body)),
+		ContentLength: int64(len(res.Body)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody reads and returns the body of the request, replacing it so that
+// it can be sent.
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	return string(b), nil
+}
+
+// redactURL returns the URL with its credentials and secrets redacted, and its query
+// parameters sorted so that recorded and replayed requests can be compared.
+func redactURL(u *url.URL, secrets []string) string {
+	c := *u
+	q := c.Query()
+	for _, p := range redactedParams {
+		if _, ok := q[p]; ok {
+			q.Set(p, redacted)
+		}
+	}
+	c.RawQuery = q.Encode()
+
+	return redactString(c.String(), secrets)
+}
+
+// redactString returns s with each of the secrets redacted.
+func redactString(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if len(secret) > 0 {
+			s = strings.Replace(s, secret, redacted, -1)
+		}
+	}
+	return s
+}
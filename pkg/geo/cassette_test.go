@@ -0,0 +1,211 @@
+package geo
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	cassetteMatrixResponse   = `{"status":"OK","origin_addresses":["Toronto"],"destination_addresses":["Ottawa"],"rows":[{"elements":[{"status":"OK","duration":{"value":16200,"text":"4 hours 30 mins"},"distance":{"value":450000,"text":"450 km"}}]}]}`
+	cassetteLocationResponse = `{"location":{"lat":43.65,"lng":-79.38},"accuracy":1200}`
+)
+
+// roundTripperFunc is an http.RoundTripper that calls itself, standing in for the network.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// cassettePath returns the path to a Cassette in a temporary directory, and a
+// function to remove it.
+func cassettePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filepath.Join(dir, "cassette.json"), func() { os.RemoveAll(dir) }
+}
+
+// googleStub responds to Distance Matrix and Geolocation requests as Google Maps would.
+func googleStub(requests *int) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		*requests++
+
+		body := cassetteMatrixResponse
+		if strings.Contains(req.URL.Path, "geolocate") {
+			body = cassetteLocationResponse
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+}
+
+func TestRouter_RecordReplay(t *testing.T) {
+	path, cleanup := cassettePath(t)
+	defer cleanup()
+
+	var requests int
+	rec := NewRecorder(path, googleStub(&requests), "secret-key")
+
+	tests := []struct {
+		key string
+		rt  http.RoundTripper
+	}{
+		{"secret-key", rec},
+		{"another-key", nil},
+	}
+
+	for idx, tt := range tests {
+		rt := tt.rt
+		if rt == nil {
+			var err error
+			if rt, err = NewReplayer(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r, err := NewRouterWithClient(tt.key, &http.Client{Transport: rt})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e, err := r.Duration("Toronto", "Ottawa", Drive, Options{})
+		if err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		} else if e.Duration != 4*time.Hour+30*time.Minute {
+			t.Fatalf("[#%v] Unexpected duration, expected=%v, got=%v", idx, 4*time.Hour+30*time.Minute, e.Duration)
+		}
+
+		pos, err := r.CurrentLocation()
+		if err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		} else if pos.Lat != 43.65 || pos.Lng != -79.38 || pos.Accuracy != 1200 {
+			t.Fatalf("[#%v] Unexpected position, expected=%v, got=%v", idx, Position{Lat: 43.65, Lng: -79.38, Accuracy: 1200}, *pos)
+		}
+
+		// The request body must be sent again on the next call.
+		if _, err := r.CurrentLocation(); err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		}
+	}
+
+	if requests != 3 {
+		t.Fatalf("Unexpected number of requests, expected=%v, got=%v", 3, requests)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if strings.Contains(string(b), "secret-key") {
+		t.Fatalf("Expected API key to be redacted, got=%s", b)
+	}
+}
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	path, cleanup := cassettePath(t)
+	defer cleanup()
+
+	var requests int
+	rec := NewRecorder(path, googleStub(&requests), "hunter2")
+	hc := http.Client{Transport: rec}
+
+	if _, err := hc.Get("http://example.com/maps?key=abc&b=2&a=1"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := hc.Post("http://example.com/geolocate?access_token=abc", "text/plain", strings.NewReader("password=hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if b, err := ioutil.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	} else if string(b) != cassetteLocationResponse {
+		t.Fatalf("Unexpected response body, expected=%v, got=%s", cassetteLocationResponse, b)
+	}
+
+	c, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []RecordedRequest{
+		{Method: "GET", URL: "http://example.com/maps?a=1&b=2&key=REDACTED"},
+		{Method: "POST", URL: "http://example.com/geolocate?access_token=REDACTED", Body: "password=REDACTED"},
+	}
+	if len(c.Interactions) != len(expect) {
+		t.Fatalf("Unexpected number of interactions, expected=%v, got=%v", len(expect), len(c.Interactions))
+	}
+	for idx, in := range c.Interactions {
+		if in.Request != expect[idx] {
+			t.Fatalf("[#%v] Unexpected request, expected=%+v, got=%+v", idx, expect[idx], in.Request)
+		} else if in.Response.StatusCode != http.StatusOK {
+			t.Fatalf("[#%v] Unexpected status code, expected=%v, got=%v", idx, http.StatusOK, in.Response.StatusCode)
+		}
+	}
+}
+
+func TestReplayer_RoundTrip(t *testing.T) {
+	path, cleanup := cassettePath(t)
+	defer cleanup()
+
+	c := Cassette{Interactions: []Interaction{
+		{RecordedRequest{Method: "GET", URL: "http://example.com/a?key=REDACTED"}, RecordedResponse{StatusCode: 200, Body: "first"}},
+		{RecordedRequest{Method: "GET", URL: "http://example.com/b"}, RecordedResponse{StatusCode: 404, Body: "other"}},
+		{RecordedRequest{Method: "GET", URL: "http://example.com/a?key=REDACTED"}, RecordedResponse{StatusCode: 200, Body: "second"}},
+	}}
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc := http.Client{Transport: rep}
+
+	tests := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"http://example.com/a?key=1", 200, "first"},
+		{"http://example.com/a?key=2", 200, "second"},
+		{"http://example.com/a?key=3", 200, "second"},
+		{"http://example.com/b", 404, "other"},
+	}
+
+	for idx, tt := range tests {
+		resp, err := hc.Get(tt.url)
+		if err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.status {
+			t.Fatalf("[#%v] Unexpected status code, expected=%v, got=%v", idx, tt.status, resp.StatusCode)
+		} else if string(b) != tt.body {
+			t.Fatalf("[#%v] Unexpected body, expected=%v, got=%s", idx, tt.body, b)
+		}
+	}
+
+	if _, err := hc.Get("http://example.com/c"); err == nil || !strings.Contains(err.Error(), ErrCassetteMiss.Error()) {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrCassetteMiss, err)
+	}
+}
@@ -0,0 +1,257 @@
+package geo
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/html"
+	"googlemaps.github.io/maps"
+)
+
+// Route is a route between two locations, including step by step directions.
+//
+// The embedded Estimate describes the route as a whole.
+type Route struct {
+	Estimate
+
+	// Summary is a short description of the route, such as the major roads it uses.
+	Summary string
+	// Legs contains a leg for each segment of the route.
+	Legs []Leg
+	// WaypointOrder is the order the waypoints are visited in, as indexes of the
+	// requested Waypoints.
+	WaypointOrder []int
+	// Polyline is the encoded polyline of the approximate path of the route.
+	Polyline string
+}
+
+// Path returns the approximate path of the Route, decoded from its Polyline.
+func (r Route) Path() []Position {
+	if len(r.Polyline) == 0 {
+		return nil
+	}
+
+	latlngs := maps.DecodePolyline(r.Polyline)
+	path := make([]Position, len(latlngs))
+	for i, l := range latlngs {
+		path[i] = Position{Lat: l.Lat, Lng: l.Lng}
+	}
+
+	return path
+}
+
+// TypicalDuration returns the duration of the Route without accounting for traffic.
+func (r Route) TypicalDuration() time.Duration {
+	var d time.Duration
+	for _, l := range r.Legs {
+		d += l.Duration
+	}
+
+	return d
+}
+
+// Leg is a segment of a Route between two locations.
+type Leg struct {
+	StartAddress  string
+	StartLocation Position
+	EndAddress    string
+	EndLocation   Position
+
+	// Distance is the distance of the leg, in meters.
+	Distance int
+	Duration time.Duration
+	// DurationInTraffic is only available for driving legs with a known departure time.
+	DurationInTraffic time.Duration
+
+	// DepartureTime and ArrivalTime are only available for transit legs.
+	DepartureTime time.Time
+	ArrivalTime   time.Time
+
+	Steps []Step
+}
+
+// Step is a single instruction within a Leg.
+type Step struct {
+	// Instructions are the plain text instructions for the step.
+	Instructions string
+	// Distance is the distance of the step, in meters.
+	Distance int
+	Duration time.Duration
+
+	// Transit contains the details of a transit step, and is nil for all other steps.
+	Transit *TransitDetails
+}
+
+// TransitDetails describes the transit line, stops and times of a transit Step.
+type TransitDetails struct {
+	// Line is the name of the transit line, such as "Line 1 Yonge-University".
+	Line string
+	// Vehicle is the type of vehicle used on the line, such as "Subway".
+	Vehicle string
+	// Headsign is the direction of travel as marked on the vehicle, such as "Finch".
+	Headsign string
+
+	DepartureStop string
+	DepartureTime time.Time
+	ArrivalStop   string
+	ArrivalTime   time.Time
+
+	// NumStops is the number of stops travelled, including the arrival stop.
+	NumStops int
+}
+
+// Directions returns the routes between the From and To address, departing
+// or arriving at the time specified by the Options.
+func (r Router) Directions(from, to string, tm TravelMode, o Options) ([]Route, error) {
+	req := maps.DirectionsRequest{
+		Origin:       from,
+		Destination:  to,
+		Mode:         maps.Mode(tm),
+		Units:        maps.Units(o.Units),
+		Alternatives: o.Alternatives,
+	}
+	if tm == Drive {
+		for _, a := range o.Avoid {
+			req.Avoid = append(req.Avoid, maps.Avoid(a))
+		}
+	}
+	if tm == Transit {
+		for _, m := range o.TransitModes {
+			req.TransitMode = append(req.TransitMode, maps.TransitMode(m))
+		}
+		req.TransitRoutingPreference = maps.TransitRoutingPreference(o.TransitPreference)
+	}
+	if !o.DepartAt.IsZero() {
+		req.DepartureTime = timestamp(o.DepartAt)
+		if tm == Drive {
+			req.TrafficModel = maps.TrafficModelBestGuess
+		}
+	}
+	if !o.ArriveBy.IsZero() {
+		req.ArrivalTime = timestamp(o.ArriveBy)
+	}
+	if len(o.Waypoints) > 0 {
+		if o.Optimize {
+			req.Waypoints = append(req.Waypoints, optimizeWaypoints)
+		}
+		req.Waypoints = append(req.Waypoints, o.Waypoints...)
+	}
+
+	// Alternatives are compared by their duration in traffic, which requires
+	// a departure time, so assume the user is leaving now if one wasn't provided.
+	if tm == Drive && o.Alternatives && len(req.DepartureTime) == 0 {
+		req.DepartureTime = departureNow
+		req.TrafficModel = maps.TrafficModelBestGuess
+	}
+
+	res, _, err := r.client.Directions(context.Background(), &req)
+	if err != nil {
+		return nil, err
+	} else if len(res) == 0 {
+		return nil, ErrUnavailable
+	}
+
+	routes := make([]Route, len(res))
+	for i, route := range res {
+		routes[i] = newRoute(route, avoided(tm, o))
+	}
+
+	return routes, nil
+}
+
+// newRoute converts a Google Maps route into a Route.
+func newRoute(r maps.Route, avoid []Avoid) Route {
+	route := Route{
+		Estimate:      Estimate{Avoided: avoid},
+		Summary:       r.Summary,
+		Legs:          make([]Leg, len(r.Legs)),
+		WaypointOrder: r.WaypointOrder,
+		Polyline:      r.OverviewPolyline.Points,
+	}
+
+	for i, l := range r.Legs {
+		leg := Leg{
+			StartAddress:      l.StartAddress,
+			StartLocation:     Position{Lat: l.StartLocation.Lat, Lng: l.StartLocation.Lng},
+			EndAddress:        l.EndAddress,
+			EndLocation:       Position{Lat: l.EndLocation.Lat, Lng: l.EndLocation.Lng},
+			Distance:          l.Distance.Meters,
+			Duration:          l.Duration,
+			DurationInTraffic: l.DurationInTraffic,
+			DepartureTime:     l.DepartureTime,
+			ArrivalTime:       l.ArrivalTime,
+			Steps:             make([]Step, len(l.Steps)),
+		}
+
+		for j, s := range l.Steps {
+			leg.Steps[j] = newStep(s)
+		}
+
+		route.Legs[i] = leg
+		route.Distance += leg.Distance
+		if leg.DurationInTraffic > 0 {
+			route.Duration += leg.DurationInTraffic
+			route.Traffic = true
+		} else {
+			route.Duration += leg.Duration
+		}
+	}
+
+	return route
+}
+
+// newStep converts a Google Maps step into a Step.
+func newStep(s *maps.Step) Step {
+	step := Step{
+		Instructions: stripHTML(s.HTMLInstructions),
+		Distance:     s.Distance.Meters,
+		Duration:     s.Duration,
+	}
+
+	if t := s.TransitDetails; t != nil {
+		line := t.Line.ShortName
+		if len(t.Line.Name) > 0 {
+			line = strings.TrimSpace(line + " " + t.Line.Name)
+		}
+
+		step.Transit = &TransitDetails{
+			Line:          line,
+			Vehicle:       t.Line.Vehicle.Name,
+			Headsign:      t.Headsign,
+			DepartureStop: t.DepartureStop.Name,
+			DepartureTime: t.DepartureTime,
+			ArrivalStop:   t.ArrivalStop.Name,
+			ArrivalTime:   t.ArrivalTime,
+			NumStops:      int(t.NumStops),
+		}
+	}
+
+	return step
+}
+
+// stripHTML converts HTML instructions into plain text, separating
+// block elements such as <div> into sentences.
+func stripHTML(s string) string {
+	var out string
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(out), " ")
+		case html.TextToken:
+			out += string(z.Text())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if string(name) != "div" && string(name) != "br" {
+				continue
+			}
+
+			trimmed := strings.TrimSpace(out)
+			if len(trimmed) > 0 && !strings.ContainsAny(trimmed[len(trimmed)-1:], ".!?") {
+				out = trimmed + "."
+			}
+			out += " "
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package geo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+func TestRouter_Directions(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+
+	// Positive Case
+	{
+		departure := time.Date(2017, time.May, 1, 8, 20, 0, 0, time.UTC)
+		arrival := time.Date(2017, time.May, 1, 8, 24, 0, 0, time.UTC)
+		mc.directionsFn = func(c context.Context, r *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error) {
+			if c == nil {
+				t.Fatal("Unexpected nil Context")
+			}
+
+			if r.Origin != "from" {
+				t.Fatalf("Unexpected Origin, expected=%v, got=%v", "from", r.Origin)
+			} else if r.Destination != "to" {
+				t.Fatalf("Unexpected Destination, expected=%v, got=%v", "to", r.Destination)
+			} else if r.Mode != maps.TravelModeTransit {
+				t.Fatalf("Unexpected Mode, expected=%v, got=%v", maps.TravelModeTransit, r.Mode)
+			}
+
+			return []maps.Route{
+				{
+					Summary:          "Line 1",
+					WaypointOrder:    []int{1, 0},
+					OverviewPolyline: maps.Polyline{Points: "_p~iF~ps|U_ulLnnqC"},
+					Legs: []*maps.Leg{
+						{
+							StartAddress:  "123 Main St",
+							StartLocation: maps.LatLng{Lat: 43.6, Lng: -79.3},
+							EndAddress:    "321 Maple Ave",
+							EndLocation:   maps.LatLng{Lat: 43.7, Lng: -79.4},
+							Distance:      maps.Distance{Meters: 1500},
+							Duration:      time.Minute * 10,
+							DepartureTime: departure,
+							ArrivalTime:   arrival,
+							Steps: []*maps.Step{
+								{
+									HTMLInstructions: "Walk to <b>Union Station</b>",
+									Distance:         maps.Distance{Meters: 300},
+									Duration:         time.Minute * 4,
+								},
+								{
+									HTMLInstructions: "Subway towards Finch",
+									Distance:         maps.Distance{Meters: 1200},
+									Duration:         time.Minute * 6,
+									TransitDetails: &maps.TransitDetails{
+										DepartureStop: maps.TransitStop{Name: "Union Station"},
+										ArrivalStop:   maps.TransitStop{Name: "King Station"},
+										DepartureTime: departure,
+										ArrivalTime:   arrival,
+										Headsign:      "Finch",
+										NumStops:      2,
+										Line: maps.TransitLine{
+											Name:      "Yonge-University",
+											ShortName: "1",
+											Vehicle:   maps.TransitLineVehicle{Name: "Subway"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}, nil, nil
+		}
+
+		routes, err := r.Directions("from", "to", Transit, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := []Route{
+			{
+				Estimate:      Estimate{Duration: time.Minute * 10, Distance: 1500},
+				Summary:       "Line 1",
+				WaypointOrder: []int{1, 0},
+				Polyline:      "_p~iF~ps|U_ulLnnqC",
+				Legs: []Leg{
+					{
+						StartAddress:  "123 Main St",
+						StartLocation: Position{Lat: 43.6, Lng: -79.3},
+						EndAddress:    "321 Maple Ave",
+						EndLocation:   Position{Lat: 43.7, Lng: -79.4},
+						Distance:      1500,
+						Duration:      time.Minute * 10,
+						DepartureTime: departure,
+						ArrivalTime:   arrival,
+						Steps: []Step{
+							{Instructions: "Walk to Union Station", Distance: 300, Duration: time.Minute * 4},
+							{
+								Instructions: "Subway towards Finch",
+								Distance:     1200,
+								Duration:     time.Minute * 6,
+								Transit: &TransitDetails{
+									Line:          "1 Yonge-University",
+									Vehicle:       "Subway",
+									Headsign:      "Finch",
+									DepartureStop: "Union Station",
+									DepartureTime: departure,
+									ArrivalStop:   "King Station",
+									ArrivalTime:   arrival,
+									NumStops:      2,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		if !reflect.DeepEqual(routes, expect) {
+			t.Fatalf("Unexpected Routes, expected=%+v, got=%+v", expect, routes)
+		}
+	}
+
+	// Options
+	{
+		at := time.Date(2017, time.May, 1, 8, 15, 0, 0, time.UTC)
+		tests := []struct {
+			mode   TravelMode
+			opts   Options
+			expect maps.DirectionsRequest
+		}{
+			{Drive, Options{}, maps.DirectionsRequest{Mode: maps.TravelModeDriving}},
+			{Drive, Options{Avoid: []Avoid{AvoidTolls, AvoidFerries}, Units: Imperial}, maps.DirectionsRequest{Mode: maps.TravelModeDriving, Avoid: []maps.Avoid{maps.AvoidTolls, maps.AvoidFerries}, Units: maps.UnitsImperial}},
+			{Drive, Options{DepartAt: at}, maps.DirectionsRequest{Mode: maps.TravelModeDriving, DepartureTime: "1493626500", TrafficModel: maps.TrafficModelBestGuess}},
+			{Walk, Options{DepartAt: at, Avoid: []Avoid{AvoidTolls}}, maps.DirectionsRequest{Mode: maps.TravelModeWalking, DepartureTime: "1493626500"}},
+			{Drive, Options{Alternatives: true}, maps.DirectionsRequest{Mode: maps.TravelModeDriving, Alternatives: true, DepartureTime: "now", TrafficModel: maps.TrafficModelBestGuess}},
+			{Drive, Options{Alternatives: true, DepartAt: at}, maps.DirectionsRequest{Mode: maps.TravelModeDriving, Alternatives: true, DepartureTime: "1493626500", TrafficModel: maps.TrafficModelBestGuess}},
+			{Bike, Options{Alternatives: true}, maps.DirectionsRequest{Mode: maps.TravelModeBicycling, Alternatives: true}},
+			{Drive, Options{Waypoints: []string{"a", "b"}}, maps.DirectionsRequest{Mode: maps.TravelModeDriving, Waypoints: []string{"a", "b"}}},
+			{Drive, Options{Waypoints: []string{"a", "b"}, Optimize: true}, maps.DirectionsRequest{Mode: maps.TravelModeDriving, Waypoints: []string{"optimize:true", "a", "b"}}},
+			{Transit, Options{ArriveBy: at, TransitModes: []TransitMode{TransitRail}, TransitPreference: LessWalking}, maps.DirectionsRequest{Mode: maps.TravelModeTransit, ArrivalTime: "1493626500", TransitMode: []maps.TransitMode{maps.TransitModeRail}, TransitRoutingPreference: maps.TransitRoutingPreferenceLessWalking}},
+		}
+
+		for idx, tt := range tests {
+			tt.expect.Origin = "from"
+			tt.expect.Destination = "to"
+
+			mc.directionsFn = func(c context.Context, r *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error) {
+				if !reflect.DeepEqual(*r, tt.expect) {
+					t.Fatalf("[#%v] Unexpected DirectionsRequest, expected=%+v, got=%+v", idx, tt.expect, *r)
+				}
+
+				return []maps.Route{{Legs: []*maps.Leg{{Duration: time.Minute, DurationInTraffic: time.Minute * 2}}}}, nil, nil
+			}
+
+			routes, err := r.Directions("from", "to", tt.mode, tt.opts)
+			if err != nil {
+				t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+			}
+
+			if routes[0].Duration != time.Minute*2 || !routes[0].Traffic {
+				t.Fatalf("[#%v] Unexpected traffic Estimate, got=%+v", idx, routes[0].Estimate)
+			} else if !reflect.DeepEqual(routes[0].Avoided, avoided(tt.mode, tt.opts)) {
+				t.Fatalf("[#%v] Unexpected Avoided, expected=%v, got=%v", idx, avoided(tt.mode, tt.opts), routes[0].Avoided)
+			}
+		}
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.directionsFn = func(c context.Context, r *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error) {
+			return nil, nil, e
+		}
+
+		if _, err := r.Directions("from", "to", Drive, Options{}); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+
+	// No routes
+	{
+		mc.directionsFn = func(c context.Context, r *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error) {
+			return nil, nil, nil
+		}
+
+		if _, err := r.Directions("from", "to", Drive, Options{}); err != ErrUnavailable {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", ErrUnavailable, err)
+		}
+	}
+}
+
+func TestRoute_TypicalDuration(t *testing.T) {
+	tests := []struct {
+		r      Route
+		expect time.Duration
+	}{
+		{Route{}, 0},
+		{Route{Legs: []Leg{{Duration: time.Minute, DurationInTraffic: time.Minute * 3}}}, time.Minute},
+		{Route{Legs: []Leg{{Duration: time.Minute}, {Duration: time.Minute * 5}}}, time.Minute * 6},
+	}
+
+	for idx, tt := range tests {
+		if d := tt.r.TypicalDuration(); d != tt.expect {
+			t.Fatalf("[#%v] Unexpected TypicalDuration, expected=%v, got=%v", idx, tt.expect, d)
+		}
+	}
+}
+
+func TestRoute_Path(t *testing.T) {
+	tests := []struct {
+		r      Route
+		expect []Position
+	}{
+		{Route{}, nil},
+		{Route{Polyline: "_p~iF~ps|U_ulLnnqC"}, []Position{{Lat: 38.5, Lng: -120.2}, {Lat: 40.7, Lng: -120.95}}},
+	}
+
+	for idx, tt := range tests {
+		if p := tt.r.Path(); !reflect.DeepEqual(p, tt.expect) {
+			t.Fatalf("[#%v] Unexpected Path, expected=%v, got=%v", idx, tt.expect, p)
+		}
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{"Head <b>north</b> on <b>Main St</b>", "Head north on Main St"},
+		{`Turn <b>left</b> onto <b>Maple Ave</b><div style="font-size:0.9em">Destination will be on the right</div>`, "Turn left onto Maple Ave. Destination will be on the right"},
+		{"Take the ramp<div>Toll road</div><div>Partial restricted usage road</div>", "Take the ramp. Toll road. Partial restricted usage road"},
+		{"Continue straight.<div>Pass by the park</div>", "Continue straight. Pass by the park"},
+		{"Walk to Union &amp; King", "Walk to Union & King"},
+		{"", ""},
+	}
+
+	for idx, tt := range tests {
+		if out := stripHTML(tt.in); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		}
+	}
+}
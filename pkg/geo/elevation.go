@@ -0,0 +1,96 @@
+package geo
+
+import (
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+const (
+	// profileSamples is the number of evenly spaced points sampled along a route
+	// to build its elevation Profile.
+	profileSamples = 40
+)
+
+// Profile is the elevation profile of a route, sampled at evenly spaced points.
+type Profile struct {
+	// Elevations are the sampled elevations along the route, in meters.
+	Elevations []float64
+	// Distance is the length of the route, in meters.
+	Distance int
+}
+
+// Ascent returns the total elevation climbed along the Profile, in meters.
+func (p Profile) Ascent() float64 {
+	var total float64
+	for i := 1; i < len(p.Elevations); i++ {
+		if d := p.Elevations[i] - p.Elevations[i-1]; d > 0 {
+			total += d
+		}
+	}
+
+	return total
+}
+
+// Descent returns the total elevation descended along the Profile, in meters.
+func (p Profile) Descent() float64 {
+	var total float64
+	for i := 1; i < len(p.Elevations); i++ {
+		if d := p.Elevations[i-1] - p.Elevations[i]; d > 0 {
+			total += d
+		}
+	}
+
+	return total
+}
+
+// MaxGrade returns the steepest climb between two samples of the Profile,
+// as a fraction of the distance between them (ex. 0.06 for a 6% grade).
+func (p Profile) MaxGrade() float64 {
+	if len(p.Elevations) < 2 || p.Distance <= 0 {
+		return 0
+	}
+
+	spacing := float64(p.Distance) / float64(len(p.Elevations)-1)
+
+	var max float64
+	for i := 1; i < len(p.Elevations); i++ {
+		if g := (p.Elevations[i] - p.Elevations[i-1]) / spacing; g > max {
+			max = g
+		}
+	}
+
+	return max
+}
+
+// Elevation returns the elevation Profile of a Route.
+func (r Router) Elevation(route Route) (*Profile, error) {
+	path := route.Path()
+	if len(path) == 0 {
+		return nil, ErrUnavailable
+	}
+
+	req := maps.ElevationRequest{
+		Path:    make([]maps.LatLng, len(path)),
+		Samples: profileSamples,
+	}
+	for i, p := range path {
+		req.Path[i] = maps.LatLng{Lat: p.Lat, Lng: p.Lng}
+	}
+
+	res, err := r.client.Elevation(context.Background(), &req)
+	if err != nil {
+		return nil, err
+	} else if len(res) == 0 {
+		return nil, ErrUnavailable
+	}
+
+	p := Profile{
+		Elevations: make([]float64, len(res)),
+		Distance:   route.Distance,
+	}
+	for i, e := range res {
+		p.Elevations[i] = e.Elevation
+	}
+
+	return &p, nil
+}
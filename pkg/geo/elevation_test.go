@@ -0,0 +1,83 @@
+package geo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+func TestRouter_Elevation(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+	route := Route{Estimate: Estimate{Distance: 2000}, Polyline: "_p~iF~ps|U_ulLnnqC"}
+
+	// Positive Case
+	{
+		mc.elevationFn = func(c context.Context, r *maps.ElevationRequest) ([]maps.ElevationResult, error) {
+			expect := []maps.LatLng{{Lat: 38.5, Lng: -120.2}, {Lat: 40.7, Lng: -120.95}}
+			if !reflect.DeepEqual(r.Path, expect) {
+				t.Fatalf("Unexpected Path, expected=%v, got=%v", expect, r.Path)
+			} else if r.Samples != profileSamples {
+				t.Fatalf("Unexpected Samples, expected=%v, got=%v", profileSamples, r.Samples)
+			}
+
+			return []maps.ElevationResult{{Elevation: 100}, {Elevation: 120}, {Elevation: 90}}, nil
+		}
+
+		p, err := r.Elevation(route)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := Profile{Elevations: []float64{100, 120, 90}, Distance: 2000}
+		if !reflect.DeepEqual(*p, expect) {
+			t.Fatalf("Unexpected Profile, expected=%+v, got=%+v", expect, *p)
+		}
+	}
+
+	// No path
+	if _, err := r.Elevation(Route{}); err != ErrUnavailable {
+		t.Fatalf("Unexpected error returned, expected=%v, got=%v", ErrUnavailable, err)
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.elevationFn = func(c context.Context, r *maps.ElevationRequest) ([]maps.ElevationResult, error) {
+			return nil, e
+		}
+
+		if _, err := r.Elevation(route); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+}
+
+func TestProfile(t *testing.T) {
+	tests := []struct {
+		p             Profile
+		expectAscent  float64
+		expectDescent float64
+		expectGrade   float64
+	}{
+		{Profile{}, 0, 0, 0},
+		{Profile{Elevations: []float64{100}, Distance: 1000}, 0, 0, 0},
+		{Profile{Elevations: []float64{100, 120, 90, 150}, Distance: 3000}, 80, 30, 0.06},
+		{Profile{Elevations: []float64{150, 100}, Distance: 500}, 0, 50, 0},
+	}
+
+	for idx, tt := range tests {
+		if a := tt.p.Ascent(); a != tt.expectAscent {
+			t.Fatalf("[#%v] Unexpected Ascent, expected=%v, got=%v", idx, tt.expectAscent, a)
+		} else if d := tt.p.Descent(); d != tt.expectDescent {
+			t.Fatalf("[#%v] Unexpected Descent, expected=%v, got=%v", idx, tt.expectDescent, d)
+		} else if g := tt.p.MaxGrade(); g != tt.expectGrade {
+			t.Fatalf("[#%v] Unexpected MaxGrade, expected=%v, got=%v", idx, tt.expectGrade, g)
+		}
+	}
+}
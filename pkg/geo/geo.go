@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
@@ -13,10 +15,15 @@ import (
 )
 
 const (
-	statusOk       = "OK"
-	statusNotFound = "NOT_FOUND"
+	statusOk          = "OK"
+	statusNotFound    = "NOT_FOUND"
+	statusZeroResults = "ZERO_RESULTS"
 
 	geolocationURL = "https://www.googleapis.com/geolocation/v1/geolocate?key="
+
+	departureNow = "now"
+
+	optimizeWaypoints = "optimize:true"
 )
 
 var (
@@ -28,9 +35,13 @@ var (
 	// could not be found.
 	ErrBadLocation = errors.New("failed to find one of the provided locations")
 
-	geolocationBody = bytes.NewBuffer([]byte(`{"considerIp": "true"}`))
+	geolocationBody = []byte(`{"considerIp": "true"}`)
 
-	defaultAvoid = maps.AvoidTolls
+	rangeTrafficModels = []maps.TrafficModel{
+		maps.TrafficModelOptimistic,
+		maps.TrafficModelBestGuess,
+		maps.TrafficModelPessimistic,
+	}
 )
 
 // TravelMode dictates the type of travel when determining the duration.
@@ -59,37 +70,191 @@ func (t TravelMode) String() string {
 	return travelModeStrings[t]
 }
 
+// Options configures when a duration should be calculated for.
+//
+// The zero value calculates the duration for departing immediately.
+type Options struct {
+	// DepartAt is the desired time of departure.
+	DepartAt time.Time
+	// ArriveBy is the desired time of arrival. Only supported by the Transit TravelMode.
+	ArriveBy time.Time
+	// TrafficRange requests optimistic and pessimistic durations in addition to
+	// the most likely duration. Only supported by the Drive TravelMode.
+	TrafficRange bool
+	// Avoid is the list of route features to avoid. Only supported by the Drive TravelMode.
+	Avoid []Avoid
+	// DefaultAvoid indicates Avoid is a default rather than requested, so that providers
+	// that may not be able to avoid the features can ignore it.
+	DefaultAvoid bool
+	// Units is the unit system used to express distances.
+	Units Units
+	// TransitModes and TransitPreference bias the routes used for transit.
+	// Only supported by the Transit TravelMode.
+	TransitModes      []TransitMode
+	TransitPreference TransitPreference
+	// Alternatives requests alternative routes in addition to the recommended
+	// route. Only supported by Directions.
+	Alternatives bool
+	// Waypoints are intermediate stops to route through, in order unless Optimize
+	// is set, in which case they are reordered for the shortest route. Only
+	// supported by Directions, and not by the Transit TravelMode.
+	Waypoints []string
+	Optimize  bool
+}
+
+// Estimate is the estimated time it will take to travel between two locations.
+type Estimate struct {
+	// Duration is the most likely travel duration.
+	Duration time.Duration
+	// Traffic indicates if the Duration accounts for traffic conditions.
+	Traffic bool
+	// Avoided is the list of route features that were avoided.
+	Avoided []Avoid
+	// Distance is the distance travelled, in meters.
+	Distance int
+	// Offline indicates the Estimate was approximated without a routing service,
+	// from the straight line distance.
+	Offline bool
+
+	// Optimistic and Pessimistic are the shortest and longest expected durations
+	// based on traffic conditions, and are only set when a TrafficRange is requested.
+	Optimistic  time.Duration
+	Pessimistic time.Duration
+
+	// Transfers is the number of transfers between transit lines, and Steps are the
+	// walking and transit steps of the journey, which are only set for transit
+	// estimated from a timetable.
+	Transfers int
+	Steps     []Step
+}
+
+// IsRange returns true if the Estimate contains optimistic and pessimistic durations.
+func (e Estimate) IsRange() bool {
+	return e.Pessimistic > 0
+}
+
 // Router provides the ability to calculate travel duration between Routes.
 type Router struct {
 	apiKey string
 
-	client Communicator
+	client     Communicator
+	httpClient *http.Client
 }
 
 // NewRouter initializes and returns a Router with a Google Maps API key.
 func NewRouter(apiKey string) (*Router, error) {
-	c, err := maps.NewClient(maps.WithAPIKey(apiKey))
+	return NewRouterWithClient(apiKey, nil)
+}
+
+// NewRouterWithClient initializes and returns a Router with a Google Maps API key,
+// which makes its requests with the http.Client provided, such as one that records
+// or replays a Cassette. The default http.Client is used when nil.
+func NewRouterWithClient(apiKey string, hc *http.Client) (*Router, error) {
+	opts := []maps.ClientOption{maps.WithAPIKey(apiKey)}
+	if hc != nil {
+		opts = append(opts, maps.WithHTTPClient(hc))
+	}
+
+	c, err := maps.NewClient(opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Router{
-		apiKey: apiKey,
-		client: c,
+		apiKey:     apiKey,
+		client:     c,
+		httpClient: hc,
 	}, nil
 }
 
-// Duration returns the time it will take to travel between
-// the From and To address.
-func (r Router) Duration(from, to string, tm TravelMode) (*time.Duration, error) {
+// Duration returns the estimated time it will take to travel between
+// the From and To address, departing or arriving at the time
+// specified by the Options.
+//
+// Driving estimates account for traffic when a departure time or
+// traffic range is requested.
+func (r Router) Duration(from, to string, tm TravelMode, o Options) (*Estimate, error) {
+	req := matrixRequest([]string{from}, []string{to}, tm, o)
+
+	if tm != Drive || (o.DepartAt.IsZero() && !o.TrafficRange) {
+		el, err := r.element(&req)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Estimate{Duration: el.Duration, Distance: el.Distance.Meters, Avoided: avoided(tm, o)}, nil
+	}
+
+	// Traffic models require a departure time, so assume the
+	// user is leaving now if one wasn't provided.
+	if len(req.DepartureTime) == 0 {
+		req.DepartureTime = departureNow
+	}
+
+	if !o.TrafficRange {
+		req.TrafficModel = maps.TrafficModelBestGuess
+		el, err := r.element(&req)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Estimate{Duration: inTraffic(el), Distance: el.Distance.Meters, Traffic: el.DurationInTraffic > 0, Avoided: avoided(tm, o)}, nil
+	}
+
+	e := Estimate{Avoided: avoided(tm, o)}
+	for _, model := range rangeTrafficModels {
+		req.TrafficModel = model
+		el, err := r.element(&req)
+		if err != nil {
+			return nil, err
+		}
+
+		switch model {
+		case maps.TrafficModelOptimistic:
+			e.Optimistic = inTraffic(el)
+		case maps.TrafficModelBestGuess:
+			e.Duration = inTraffic(el)
+			e.Distance = el.Distance.Meters
+			e.Traffic = el.DurationInTraffic > 0
+		case maps.TrafficModelPessimistic:
+			e.Pessimistic = inTraffic(el)
+		}
+	}
+
+	return &e, nil
+}
+
+// matrixRequest returns a DistanceMatrix request between each of the origins
+// and destinations, configured by the Options.
+func matrixRequest(origins, destinations []string, tm TravelMode, o Options) maps.DistanceMatrixRequest {
 	req := maps.DistanceMatrixRequest{
-		Origins:      []string{from},
-		Destinations: []string{to},
+		Origins:      origins,
+		Destinations: destinations,
 		Mode:         maps.Mode(tm),
-		Avoid:        defaultAvoid,
+		Units:        maps.Units(o.Units),
+	}
+	if tm == Drive && len(o.Avoid) > 0 {
+		req.Avoid = maps.Avoid(strings.Join(avoidStrings(o.Avoid), "|"))
+	}
+	if tm == Transit {
+		for _, m := range o.TransitModes {
+			req.TransitMode = append(req.TransitMode, maps.TransitMode(m))
+		}
+		req.TransitRoutingPreference = maps.TransitRoutingPreference(o.TransitPreference)
+	}
+	if !o.DepartAt.IsZero() {
+		req.DepartureTime = timestamp(o.DepartAt)
 	}
+	if !o.ArriveBy.IsZero() {
+		req.ArrivalTime = timestamp(o.ArriveBy)
+	}
+
+	return req
+}
 
-	res, err := r.client.DistanceMatrix(context.Background(), &req)
+// element performs a DistanceMatrix request and returns the first successful element.
+func (r Router) element(req *maps.DistanceMatrixRequest) (*maps.DistanceMatrixElement, error) {
+	res, err := r.client.DistanceMatrix(context.Background(), req)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +265,7 @@ func (r Router) Duration(from, to string, tm TravelMode) (*time.Duration, error)
 			case statusNotFound:
 				return nil, ErrBadLocation
 			case statusOk:
-				return &el.Duration, nil
+				return el, nil
 			}
 		}
 	}
@@ -108,22 +273,64 @@ func (r Router) Duration(from, to string, tm TravelMode) (*time.Duration, error)
 	return nil, ErrUnavailable
 }
 
-// CurrentLocation attempts to use Geolocation to return the Lat/Long of the system device
+// searchErr returns ErrBadLocation when a search request failed because nothing
+// matched, which the client reports as an error, and the error provided otherwise.
+func searchErr(err error) error {
+	if strings.Contains(err.Error(), statusZeroResults) {
+		return ErrBadLocation
+	}
+
+	return err
+}
+
+// avoided returns the list of route features that are avoided for a TravelMode.
+func avoided(tm TravelMode, o Options) []Avoid {
+	if tm != Drive {
+		return nil
+	}
+
+	return o.Avoid
+}
+
+// inTraffic returns the duration in traffic of an element, falling back to the
+// duration when traffic conditions are unavailable.
+func inTraffic(el *maps.DistanceMatrixElement) time.Duration {
+	if el.DurationInTraffic > 0 {
+		return el.DurationInTraffic
+	}
+
+	return el.Duration
+}
+
+// timestamp returns the Unix timestamp representation of a time, as expected by the Google Maps API.
+func timestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// CurrentLocation attempts to use Geolocation to return the Position of the system device
 // based on it's IP Address.
-func (r Router) CurrentLocation() (float64, float64, error) {
-	resp, err := http.Post(geolocationURL+r.apiKey, "application/json", geolocationBody)
+func (r Router) CurrentLocation() (*Position, error) {
+	hc := r.httpClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	resp, err := hc.Post(geolocationURL+r.apiKey, "application/json", bytes.NewReader(geolocationBody))
 	if err != nil {
-		return 0, 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var loc struct {
-		LatLng *maps.LatLng `json:"location"`
+		LatLng   *maps.LatLng `json:"location"`
+		Accuracy float64      `json:"accuracy"`
 	}
 	decoder := json.NewDecoder(resp.Body)
 	if err := decoder.Decode(&loc); err != nil {
-		return 0, 0, err
+		return nil, err
+	} else if loc.LatLng == nil {
+		return nil, ErrUnavailable
 	}
 
-	return loc.LatLng.Lat, loc.LatLng.Lng, nil
+	return &Position{Lat: loc.LatLng.Lat, Lng: loc.LatLng.Lng, Accuracy: loc.Accuracy}, nil
 }
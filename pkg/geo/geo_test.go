@@ -2,6 +2,7 @@ package geo
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -10,13 +11,48 @@ import (
 )
 
 type MockCommunicator struct {
-	distanceFn func(context.Context, *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error)
+	distanceFn   func(context.Context, *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error)
+	directionsFn func(context.Context, *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error)
+	geocodeFn    func(context.Context, *maps.GeocodingRequest) ([]maps.GeocodingResult, error)
+	reverseFn    func(context.Context, *maps.GeocodingRequest) ([]maps.GeocodingResult, error)
+	timezoneFn   func(context.Context, *maps.TimezoneRequest) (*maps.TimezoneResult, error)
+	elevationFn  func(context.Context, *maps.ElevationRequest) ([]maps.ElevationResult, error)
+	searchFn     func(context.Context, *maps.TextSearchRequest) (maps.PlacesSearchResponse, error)
+	nearbyFn     func(context.Context, *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error)
 }
 
 func (m *MockCommunicator) DistanceMatrix(c context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
 	return m.distanceFn(c, r)
 }
 
+func (m *MockCommunicator) Directions(c context.Context, r *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error) {
+	return m.directionsFn(c, r)
+}
+
+func (m *MockCommunicator) Geocode(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+	return m.geocodeFn(c, r)
+}
+
+func (m *MockCommunicator) ReverseGeocode(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+	return m.reverseFn(c, r)
+}
+
+func (m *MockCommunicator) Timezone(c context.Context, r *maps.TimezoneRequest) (*maps.TimezoneResult, error) {
+	return m.timezoneFn(c, r)
+}
+
+func (m *MockCommunicator) Elevation(c context.Context, r *maps.ElevationRequest) ([]maps.ElevationResult, error) {
+	return m.elevationFn(c, r)
+}
+
+func (m *MockCommunicator) TextSearch(c context.Context, r *maps.TextSearchRequest) (maps.PlacesSearchResponse, error) {
+	return m.searchFn(c, r)
+}
+
+func (m *MockCommunicator) NearbySearch(c context.Context, r *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
+	return m.nearbyFn(c, r)
+}
+
 func TestNewRouter(t *testing.T) {
 	if _, err := NewRouter(""); err == nil {
 		t.Fatal("Expected error for empty API key")
@@ -58,8 +94,14 @@ func TestRouter_Duration(t *testing.T) {
 			if r.Mode != maps.Mode(mode) {
 				t.Fatalf("Unexpected Mode, expected=%v, got=%v", mode, r.Mode)
 			}
-			if r.Avoid != defaultAvoid {
-				t.Fatalf("Unexpected Avoid, expected=%v, got=%v", defaultAvoid, r.Avoid)
+			if r.Avoid != "" {
+				t.Fatalf("Unexpected Avoid, expected empty, got=%v", r.Avoid)
+			}
+			if r.Units != "" {
+				t.Fatalf("Unexpected Units, expected empty, got=%v", r.Units)
+			}
+			if r.DepartureTime != "" || r.ArrivalTime != "" {
+				t.Fatalf("Unexpected DepartureTime/ArrivalTime, expected empty, got=[%v, %v]", r.DepartureTime, r.ArrivalTime)
 			}
 
 			return &maps.DistanceMatrixResponse{
@@ -69,6 +111,7 @@ func TestRouter_Duration(t *testing.T) {
 							&maps.DistanceMatrixElement{
 								Status:   statusOk,
 								Duration: duration,
+								Distance: maps.Distance{Meters: 24100},
 							},
 						},
 					},
@@ -76,13 +119,242 @@ func TestRouter_Duration(t *testing.T) {
 			}, nil
 		}
 
-		d, err := r.Duration(from, to, mode)
+		d, err := r.Duration(from, to, mode, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d.Duration != duration {
+			t.Fatalf("Unexpected duration returned, expected=%v, got=%v", duration, d.Duration)
+		} else if d.Distance != 24100 {
+			t.Fatalf("Unexpected distance returned, expected=%v, got=%v", 24100, d.Distance)
+		} else if d.Traffic || d.IsRange() {
+			t.Fatalf("Unexpected traffic estimate returned, got=%+v", d)
+		}
+	}
+
+	// Departure and Arrival Times
+	{
+		at := time.Date(2017, time.May, 1, 8, 15, 0, 0, time.UTC)
+		tests := []struct {
+			opts          Options
+			mode          TravelMode
+			expectDepart  string
+			expectArrival string
+		}{
+			{Options{DepartAt: at}, Drive, "1493626500", ""},
+			{Options{DepartAt: at}, Transit, "1493626500", ""},
+			{Options{ArriveBy: at}, Transit, "", "1493626500"},
+		}
+
+		for idx, tt := range tests {
+			mc.distanceFn = func(c context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+				if r.DepartureTime != tt.expectDepart {
+					t.Fatalf("[#%v] Unexpected DepartureTime, expected=%v, got=%v", idx, tt.expectDepart, r.DepartureTime)
+				} else if r.ArrivalTime != tt.expectArrival {
+					t.Fatalf("[#%v] Unexpected ArrivalTime, expected=%v, got=%v", idx, tt.expectArrival, r.ArrivalTime)
+				}
+
+				return &maps.DistanceMatrixResponse{
+					Rows: []maps.DistanceMatrixElementsRow{
+						{Elements: []*maps.DistanceMatrixElement{{Status: statusOk}}},
+					},
+				}, nil
+			}
+
+			if _, err := r.Duration("from", "to", tt.mode, tt.opts); err != nil {
+				t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+			}
+		}
+	}
+
+	// Traffic
+	{
+		at := time.Date(2017, time.May, 1, 8, 15, 0, 0, time.UTC)
+		durations := map[maps.TrafficModel]time.Duration{
+			maps.TrafficModelOptimistic:  time.Minute * 28,
+			maps.TrafficModelBestGuess:   time.Minute * 33,
+			maps.TrafficModelPessimistic: time.Minute * 41,
+		}
+		distances := map[maps.TrafficModel]int{
+			maps.TrafficModelOptimistic:  9000,
+			maps.TrafficModelBestGuess:   10000,
+			maps.TrafficModelPessimistic: 11000,
+		}
+
+		tests := []struct {
+			opts         Options
+			mode         TravelMode
+			expectDepart string
+			expectModels []maps.TrafficModel
+			expect       Estimate
+		}{
+			{Options{}, Drive, "", []maps.TrafficModel{""}, Estimate{Duration: time.Minute * 30}},
+			{Options{DepartAt: at}, Walk, "1493626500", []maps.TrafficModel{""}, Estimate{Duration: time.Minute * 30}},
+			{Options{DepartAt: at}, Drive, "1493626500", []maps.TrafficModel{maps.TrafficModelBestGuess}, Estimate{Duration: time.Minute * 33, Distance: 10000, Traffic: true}},
+			{Options{TrafficRange: true}, Drive, departureNow, rangeTrafficModels, Estimate{Duration: time.Minute * 33, Distance: 10000, Optimistic: time.Minute * 28, Pessimistic: time.Minute * 41, Traffic: true}},
+			{Options{DepartAt: at, TrafficRange: true}, Drive, "1493626500", rangeTrafficModels, Estimate{Duration: time.Minute * 33, Distance: 10000, Optimistic: time.Minute * 28, Pessimistic: time.Minute * 41, Traffic: true}},
+		}
+
+		for idx, tt := range tests {
+			var models []maps.TrafficModel
+			mc.distanceFn = func(c context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+				if r.DepartureTime != tt.expectDepart {
+					t.Fatalf("[#%v] Unexpected DepartureTime, expected=%v, got=%v", idx, tt.expectDepart, r.DepartureTime)
+				}
+				models = append(models, r.TrafficModel)
+
+				return &maps.DistanceMatrixResponse{
+					Rows: []maps.DistanceMatrixElementsRow{
+						{Elements: []*maps.DistanceMatrixElement{{
+							Status:            statusOk,
+							Duration:          time.Minute * 30,
+							DurationInTraffic: durations[r.TrafficModel],
+							Distance:          maps.Distance{Meters: distances[r.TrafficModel]},
+						}}},
+					},
+				}, nil
+			}
+
+			e, err := r.Duration("from", "to", tt.mode, tt.opts)
+			if err != nil {
+				t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+			}
+
+			if len(models) != len(tt.expectModels) {
+				t.Fatalf("[#%v] Unexpected TrafficModels requested, expected=%v, got=%v", idx, tt.expectModels, models)
+			}
+			for i := range models {
+				if models[i] != tt.expectModels[i] {
+					t.Fatalf("[#%v] Unexpected TrafficModels requested, expected=%v, got=%v", idx, tt.expectModels, models)
+				}
+			}
+
+			if !reflect.DeepEqual(*e, tt.expect) {
+				t.Fatalf("[#%v] Unexpected Estimate, expected=%+v, got=%+v", idx, tt.expect, *e)
+			}
+		}
+	}
+
+	// Traffic unavailable
+	{
+		mc.distanceFn = func(c context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+			return &maps.DistanceMatrixResponse{
+				Rows: []maps.DistanceMatrixElementsRow{
+					{Elements: []*maps.DistanceMatrixElement{{Status: statusOk, Duration: time.Minute * 30}}},
+				},
+			}, nil
+		}
+
+		e, err := r.Duration("from", "to", Drive, Options{TrafficRange: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if e.Duration != time.Minute*30 || e.Optimistic != time.Minute*30 || e.Pessimistic != time.Minute*30 {
+			t.Fatalf("Unexpected Estimate, expected durations of %v, got=%+v", time.Minute*30, *e)
+		} else if e.Traffic {
+			t.Fatalf("Unexpected Traffic, expected=false, got=%+v", *e)
+		}
+
+		e, err = r.Duration("from", "to", Drive, Options{})
 		if err != nil {
 			t.Fatal(err)
+		} else if e.Duration != time.Minute*30 || e.Traffic {
+			t.Fatalf("Unexpected Estimate, expected a duration of %v without traffic, got=%+v", time.Minute*30, *e)
+		}
+	}
+
+	// Avoid
+	{
+		tests := []struct {
+			mode        TravelMode
+			avoid       []Avoid
+			expectAvoid maps.Avoid
+			expect      []Avoid
+		}{
+			{Drive, nil, "", nil},
+			{Drive, []Avoid{AvoidTolls}, "tolls", []Avoid{AvoidTolls}},
+			{Drive, []Avoid{AvoidHighways, AvoidFerries}, "highways|ferries", []Avoid{AvoidHighways, AvoidFerries}},
+			{Walk, []Avoid{AvoidTolls}, "", nil},
+			{Transit, []Avoid{AvoidHighways}, "", nil},
+		}
+
+		for idx, tt := range tests {
+			mc.distanceFn = func(c context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+				if r.Avoid != tt.expectAvoid {
+					t.Fatalf("[#%v] Unexpected Avoid, expected=%v, got=%v", idx, tt.expectAvoid, r.Avoid)
+				}
+
+				return &maps.DistanceMatrixResponse{
+					Rows: []maps.DistanceMatrixElementsRow{
+						{Elements: []*maps.DistanceMatrixElement{{Status: statusOk}}},
+					},
+				}, nil
+			}
+
+			e, err := r.Duration("from", "to", tt.mode, Options{Avoid: tt.avoid})
+			if err != nil {
+				t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+			} else if !reflect.DeepEqual(e.Avoided, tt.expect) {
+				t.Fatalf("[#%v] Unexpected Avoided, expected=%v, got=%v", idx, tt.expect, e.Avoided)
+			}
+		}
+	}
+
+	// Units
+	{
+		for idx, units := range []Units{Metric, Imperial} {
+			mc.distanceFn = func(c context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+				if r.Units != maps.Units(units) {
+					t.Fatalf("[#%v] Unexpected Units, expected=%v, got=%v", idx, units, r.Units)
+				}
+
+				return &maps.DistanceMatrixResponse{
+					Rows: []maps.DistanceMatrixElementsRow{
+						{Elements: []*maps.DistanceMatrixElement{{Status: statusOk}}},
+					},
+				}, nil
+			}
+
+			if _, err := r.Duration("from", "to", Drive, Options{Units: units}); err != nil {
+				t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+			}
+		}
+	}
+
+	// Transit
+	{
+		tests := []struct {
+			mode       TravelMode
+			opts       Options
+			expectMode []maps.TransitMode
+			expectPref maps.TransitRoutingPreference
+		}{
+			{Transit, Options{}, nil, ""},
+			{Transit, Options{TransitModes: []TransitMode{TransitSubway, TransitTrain}}, []maps.TransitMode{maps.TransitModeSubway, maps.TransitModeTrain}, ""},
+			{Transit, Options{TransitPreference: FewerTransfers}, nil, maps.TransitRoutingPreferenceFewerTransfers},
+			{Drive, Options{TransitModes: []TransitMode{TransitBus}, TransitPreference: LessWalking}, nil, ""},
 		}
 
-		if *d != duration {
-			t.Fatalf("Unexpected duration returned, expected=%v, got=%v", duration, *d)
+		for idx, tt := range tests {
+			mc.distanceFn = func(c context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+				if !reflect.DeepEqual(r.TransitMode, tt.expectMode) {
+					t.Fatalf("[#%v] Unexpected TransitMode, expected=%v, got=%v", idx, tt.expectMode, r.TransitMode)
+				} else if r.TransitRoutingPreference != tt.expectPref {
+					t.Fatalf("[#%v] Unexpected TransitRoutingPreference, expected=%v, got=%v", idx, tt.expectPref, r.TransitRoutingPreference)
+				}
+
+				return &maps.DistanceMatrixResponse{
+					Rows: []maps.DistanceMatrixElementsRow{
+						{Elements: []*maps.DistanceMatrixElement{{Status: statusOk}}},
+					},
+				}, nil
+			}
+
+			if _, err := r.Duration("from", "to", tt.mode, tt.opts); err != nil {
+				t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+			}
 		}
 	}
 
@@ -93,7 +365,7 @@ func TestRouter_Duration(t *testing.T) {
 			return nil, e
 		}
 
-		_, err := r.Duration("", "", Drive)
+		_, err := r.Duration("", "", Drive, Options{})
 		if err != e {
 			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
 		}
@@ -115,7 +387,7 @@ func TestRouter_Duration(t *testing.T) {
 			}, nil
 		}
 
-		_, err := r.Duration("", "", Drive)
+		_, err := r.Duration("", "", Drive, Options{})
 		if err != ErrBadLocation {
 			t.Fatalf("Unexpected error returned, expected=%v, got=%v", ErrBadLocation, err)
 		}
@@ -127,7 +399,7 @@ func TestRouter_Duration(t *testing.T) {
 			return &maps.DistanceMatrixResponse{}, nil
 		}
 
-		_, err := r.Duration("", "", Drive)
+		_, err := r.Duration("", "", Drive, Options{})
 		if err != ErrUnavailable {
 			t.Fatalf("Unexpected error returned, expected=%v, got=%v", ErrUnavailable, err)
 		}
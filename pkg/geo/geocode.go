@@ -0,0 +1,71 @@
+package geo
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+// Place is a geocoded location.
+type Place struct {
+	// Name is the name of the place, such as a business or landmark, and is
+	// only available for places found by a search.
+	Name string
+	// Address is the canonical, formatted address of the location.
+	Address string
+	Lat     float64
+	Lng     float64
+}
+
+// String returns the name and address of the Place, such as
+// "CN Tower, 290 Bremner Blvd, Toronto", or just the address when
+// the Place is unnamed or the address already begins with its name.
+func (p Place) String() string {
+	if len(p.Name) == 0 || strings.HasPrefix(p.Address, p.Name) {
+		return p.Address
+	}
+
+	return p.Name + ", " + p.Address
+}
+
+// Geocode returns the places matching an address, most relevant first.
+//
+// ErrBadLocation is returned if no places match the address.
+func (r Router) Geocode(address string) ([]Place, error) {
+	res, err := r.client.Geocode(context.Background(), &maps.GeocodingRequest{Address: address})
+	if err != nil {
+		return nil, searchErr(err)
+	} else if len(res) == 0 {
+		return nil, ErrBadLocation
+	}
+
+	places := make([]Place, len(res))
+	for i, g := range res {
+		places[i] = Place{
+			Address: g.FormattedAddress,
+			Lat:     g.Geometry.Location.Lat,
+			Lng:     g.Geometry.Location.Lng,
+		}
+	}
+
+	return places, nil
+}
+
+// ReverseGeocode returns the most relevant place at a Latitude and Longitude.
+//
+// ErrBadLocation is returned if no places are found.
+func (r Router) ReverseGeocode(lat, lng float64) (*Place, error) {
+	res, err := r.client.ReverseGeocode(context.Background(), &maps.GeocodingRequest{LatLng: &maps.LatLng{Lat: lat, Lng: lng}})
+	if err != nil {
+		return nil, searchErr(err)
+	} else if len(res) == 0 {
+		return nil, ErrBadLocation
+	}
+
+	return &Place{
+		Address: res[0].FormattedAddress,
+		Lat:     res[0].Geometry.Location.Lat,
+		Lng:     res[0].Geometry.Location.Lng,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package geo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+func TestRouter_Geocode(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+
+	// Positive Case
+	{
+		mc.geocodeFn = func(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+			if r.Address != "123 Main St" {
+				t.Fatalf("Unexpected Address, expected=%v, got=%v", "123 Main St", r.Address)
+			}
+
+			return []maps.GeocodingResult{
+				{FormattedAddress: "123 Main St, Toronto, ON, Canada", Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 43.6, Lng: -79.3}}},
+				{FormattedAddress: "123 Main St, Buffalo, NY, USA", Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 42.8, Lng: -78.8}}},
+			}, nil
+		}
+
+		places, err := r.Geocode("123 Main St")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := []Place{
+			{Address: "123 Main St, Toronto, ON, Canada", Lat: 43.6, Lng: -79.3},
+			{Address: "123 Main St, Buffalo, NY, USA", Lat: 42.8, Lng: -78.8},
+		}
+		if !reflect.DeepEqual(places, expect) {
+			t.Fatalf("Unexpected Places, expected=%v, got=%v", expect, places)
+		}
+	}
+
+	// No results
+	{
+		tests := []struct {
+			res []maps.GeocodingResult
+			err error
+		}{
+			{nil, nil},
+			{nil, errors.New("maps: ZERO_RESULTS - ")},
+		}
+
+		for idx, tt := range tests {
+			mc.geocodeFn = func(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+				return tt.res, tt.err
+			}
+
+			if _, err := r.Geocode("nowhere"); err != ErrBadLocation {
+				t.Fatalf("[#%v] Unexpected error returned, expected=%v, got=%v", idx, ErrBadLocation, err)
+			}
+		}
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.geocodeFn = func(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+			return nil, e
+		}
+
+		if _, err := r.Geocode("123 Main St"); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+}
+
+func TestRouter_ReverseGeocode(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+
+	// Positive Case
+	{
+		mc.reverseFn = func(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+			if r.LatLng == nil || r.LatLng.Lat != 43.6 || r.LatLng.Lng != -79.3 {
+				t.Fatalf("Unexpected LatLng, expected=43.6,-79.3, got=%v", r.LatLng)
+			}
+
+			return []maps.GeocodingResult{
+				{FormattedAddress: "123 Main St, Toronto, ON, Canada", Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 43.61, Lng: -79.31}}},
+				{FormattedAddress: "Toronto, ON, Canada"},
+			}, nil
+		}
+
+		place, err := r.ReverseGeocode(43.6, -79.3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := Place{Address: "123 Main St, Toronto, ON, Canada", Lat: 43.61, Lng: -79.31}
+		if *place != expect {
+			t.Fatalf("Unexpected Place, expected=%v, got=%v", expect, *place)
+		}
+	}
+
+	// No results
+	{
+		mc.reverseFn = func(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+			return nil, errors.New("maps: ZERO_RESULTS - ")
+		}
+
+		if _, err := r.ReverseGeocode(0, 0); err != ErrBadLocation {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", ErrBadLocation, err)
+		}
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.reverseFn = func(c context.Context, r *maps.GeocodingRequest) ([]maps.GeocodingResult, error) {
+			return nil, e
+		}
+
+		if _, err := r.ReverseGeocode(0, 0); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+}
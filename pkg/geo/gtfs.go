@@ -0,0 +1,631 @@
+package geo
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// GTFSFeedSetting is the Provider setting containing the path of a GTFS feed, such as
+	// "/data/ttc.zip". Transit durations are estimated from its timetable, in place of the
+	// Provider, when it's set.
+	GTFSFeedSetting = "gtfs"
+
+	gtfsTimetableExtension = ".timetable"
+	gtfsDateLayout         = "20060102"
+	gtfsAddedService       = "1"
+	gtfsRemovedService     = "2"
+
+	// gtfsTransferDistance is the furthest apart two stops can be to walk between them,
+	// in meters.
+	gtfsTransferDistance = 300
+
+	// gtfsTimetableVersion identifies the format of saved timetables. Bump it when
+	// the timetable or its import changes, so that stale timetables are re-imported.
+	gtfsTimetableVersion = 1
+)
+
+var (
+	// ErrInvalidGTFS is returned when a GTFS feed is missing a required file or column,
+	// or a value can't be parsed.
+	ErrInvalidGTFS = errors.New("invalid GTFS feed")
+
+	// gtfsVehicles are the names of the vehicles of each basic route type.
+	gtfsVehicles = map[int]string{
+		0:  "Tram",
+		1:  "Subway",
+		2:  "Train",
+		3:  "Bus",
+		4:  "Ferry",
+		5:  "Cable Car",
+		6:  "Gondola",
+		7:  "Funicular",
+		11: "Trolleybus",
+		12: "Monorail",
+	}
+	// gtfsExtendedVehicles are the names of the vehicles of each hundred of the
+	// extended route types.
+	gtfsExtendedVehicles = map[int]string{
+		1:  "Train",
+		2:  "Bus",
+		4:  "Subway",
+		7:  "Bus",
+		8:  "Trolleybus",
+		9:  "Tram",
+		10: "Ferry",
+		13: "Gondola",
+		14: "Funicular",
+	}
+	// gtfsTransitModes are the TransitModes of each vehicle.
+	gtfsTransitModes = map[string]TransitMode{
+		"Tram":       TransitTram,
+		"Subway":     TransitSubway,
+		"Train":      TransitTrain,
+		"Bus":        TransitBus,
+		"Trolleybus": TransitBus,
+		"Monorail":   TransitTram,
+	}
+)
+
+// timetable is the stops and scheduled trips of a GTFS feed, with trips grouped
+// into patterns that visit the same sequence of stops. Fields are exported to be
+// encoded.
+type timetable struct {
+	// Version is the gtfsTimetableVersion the timetable was imported with.
+	Version int
+
+	// Zone is the time zone of the times in the timetable.
+	Zone string
+
+	Stops    []gtfsStop
+	Routes   []gtfsRoute
+	Patterns []gtfsPattern
+	Services map[string]*gtfsService
+
+	// StopPatterns are the patterns serving each stop, and Transfers are the stops
+	// within walking distance of each stop.
+	StopPatterns [][]int32
+	Transfers    [][]gtfsTransfer
+}
+
+// gtfsStop is a stop where vehicles pick up and drop off passengers.
+type gtfsStop struct {
+	Name     string
+	Lat, Lng float64
+}
+
+// gtfsRoute is a transit line.
+type gtfsRoute struct {
+	Line    string
+	Vehicle string
+}
+
+// gtfsPattern is a sequence of stops visited by the trips of a route, which are
+// ordered by their departure from the first stop.
+type gtfsPattern struct {
+	Route int32
+	Stops []int32
+	Trips []gtfsTrip
+}
+
+// gtfsTrip is a scheduled journey of a vehicle along a pattern, with the times it
+// arrives at and departs each stop, in seconds after midnight of its service day.
+type gtfsTrip struct {
+	Service    string
+	Headsign   string
+	Arrivals   []int32
+	Departures []int32
+}
+
+// gtfsService is the dates a service runs on.
+type gtfsService struct {
+	Weekdays   [7]bool
+	Start, End string
+	Added      map[string]bool
+	Removed    map[string]bool
+}
+
+// gtfsTransfer is a walk to a nearby stop.
+type gtfsTransfer struct {
+	Stop   int32
+	Meters float32
+}
+
+// runs returns true if the service runs on the date provided.
+func (s *gtfsService) runs(date time.Time) bool {
+	d := date.Format(gtfsDateLayout)
+	if s.Removed[d] {
+		return false
+	} else if s.Added[d] {
+		return true
+	}
+
+	return s.Weekdays[date.Weekday()] && d >= s.Start && d <= s.End
+}
+
+// loadTimetable returns the timetable of the GTFS feed at the path provided, reading
+// the saved timetable when it's newer than the feed and of the current version, and
+// importing and saving it otherwise.
+func loadTimetable(path string) (*timetable, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := path + gtfsTimetableExtension
+	if cacheInfo, err := os.Stat(cache); err == nil && !cacheInfo.ModTime().Before(info.ModTime()) {
+		if t, err := readTimetable(cache); err == nil && t.Version == gtfsTimetableVersion {
+			return t, nil
+		}
+	}
+
+	t, err := importGTFS(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The saved timetable only avoids importing the feed again, so the feed can
+	// still be used when its directory isn't writable.
+	t.save(cache)
+	return t, nil
+}
+
+// readTimetable reads a timetable previously written with save.
+func readTimetable(path string) (*timetable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var t timetable
+	if err := gob.NewDecoder(f).Decode(&t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// save writes the timetable to the path provided, so that it can be read without
+// importing the GTFS feed again.
+func (t *timetable) save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(f).Encode(t); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// gtfsFeed reads the files of a zipped GTFS feed.
+type gtfsFeed struct {
+	files map[string]*zip.File
+}
+
+// importGTFS imports the timetable of the zipped GTFS feed at the path provided.
+func importGTFS(p string) (*timetable, error) {
+	z, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	// Some feeds are zipped within a directory, so files are found by name alone.
+	feed := gtfsFeed{files: make(map[string]*zip.File)}
+	for _, f := range z.File {
+		feed.files[path.Base(f.Name)] = f
+	}
+
+	t := timetable{Version: gtfsTimetableVersion, Services: make(map[string]*gtfsService)}
+	if t.Zone, err = feed.zone(); err != nil {
+		return nil, err
+	}
+
+	stops, err := feed.stops(&t)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := feed.routes(&t)
+	if err != nil {
+		return nil, err
+	}
+	if err := feed.services(&t); err != nil {
+		return nil, err
+	}
+	trips, err := feed.trips(routes)
+	if err != nil {
+		return nil, err
+	}
+	if err := feed.stopTimes(&t, stops, trips); err != nil {
+		return nil, err
+	}
+
+	t.index()
+	return &t, nil
+}
+
+// read calls fn with each row of a file of the feed, by column name. A missing file
+// is an error unless it's optional.
+func (f *gtfsFeed) read(name string, optional bool, fn func(row map[string]string) error) error {
+	zf, ok := f.files[name]
+	if !ok && optional {
+		return nil
+	} else if !ok {
+		return fmt.Errorf("%v: missing %v", ErrInvalidGTFS, name)
+	}
+
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	r := csv.NewReader(rc)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("%v: %v: %v", ErrInvalidGTFS, name, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	row := make(map[string]string, len(header))
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%v: %v: %v", ErrInvalidGTFS, name, err)
+		}
+
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = ""
+			if i < len(record) {
+				row[strings.TrimSpace(column)] = strings.TrimSpace(record[i])
+			}
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+	}
+}
+
+// zone returns the time zone of the first agency, which all agencies of a feed share.
+func (f *gtfsFeed) zone() (string, error) {
+	var zone string
+	err := f.read("agency.txt", false, func(row map[string]string) error {
+		if len(zone) == 0 {
+			zone = row["agency_timezone"]
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	} else if _, err := time.LoadLocation(zone); err != nil || len(zone) == 0 {
+		return "", fmt.Errorf("%v: unknown agency_timezone %q", ErrInvalidGTFS, zone)
+	}
+
+	return zone, nil
+}
+
+// stops reads the stops of the feed, returning the index of each by id. Stations
+// and entrances are skipped, as vehicles only stop at their platforms.
+func (f *gtfsFeed) stops(t *timetable) (map[string]int32, error) {
+	ids := make(map[string]int32)
+	err := f.read("stops.txt", false, func(row map[string]string) error {
+		if lt := row["location_type"]; len(lt) > 0 && lt != "0" {
+			return nil
+		}
+
+		lat, err := strconv.ParseFloat(row["stop_lat"], 64)
+		if err != nil {
+			return ErrInvalidGTFS
+		}
+		lng, err := strconv.ParseFloat(row["stop_lon"], 64)
+		if err != nil {
+			return ErrInvalidGTFS
+		}
+
+		ids[row["stop_id"]] = int32(len(t.Stops))
+		t.Stops = append(t.Stops, gtfsStop{Name: row["stop_name"], Lat: lat, Lng: lng})
+		return nil
+	})
+
+	return ids, err
+}
+
+// routes reads the routes of the feed, returning the index of each by id.
+func (f *gtfsFeed) routes(t *timetable) (map[string]int32, error) {
+	ids := make(map[string]int32)
+	err := f.read("routes.txt", false, func(row map[string]string) error {
+		routeType, err := strconv.Atoi(row["route_type"])
+		if err != nil {
+			return ErrInvalidGTFS
+		}
+
+		vehicle, ok := gtfsVehicles[routeType]
+		if !ok {
+			vehicle = gtfsExtendedVehicles[routeType/100]
+		}
+
+		ids[row["route_id"]] = int32(len(t.Routes))
+		t.Routes = append(t.Routes, gtfsRoute{
+			Line:    strings.TrimSpace(row["route_short_name"] + " " + row["route_long_name"]),
+			Vehicle: vehicle,
+		})
+		return nil
+	})
+
+	return ids, err
+}
+
+// services reads the weekly schedules and exceptional dates of each service.
+func (f *gtfsFeed) services(t *timetable) error {
+	service := func(id string) *gtfsService {
+		s, ok := t.Services[id]
+		if !ok {
+			s = &gtfsService{Added: make(map[string]bool), Removed: make(map[string]bool)}
+			t.Services[id] = s
+		}
+		return s
+	}
+
+	days := []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+	err := f.read("calendar.txt", true, func(row map[string]string) error {
+		s := service(row["service_id"])
+		for i, day := range days {
+			s.Weekdays[i] = row[day] == "1"
+		}
+		s.Start, s.End = row["start_date"], row["end_date"]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.read("calendar_dates.txt", true, func(row map[string]string) error {
+		s := service(row["service_id"])
+		switch row["exception_type"] {
+		case gtfsAddedService:
+			s.Added[row["date"]] = true
+		case gtfsRemovedService:
+			s.Removed[row["date"]] = true
+		}
+		return nil
+	})
+}
+
+// gtfsTripInfo is a trip read from the feed, before its stop times are.
+type gtfsTripInfo struct {
+	route    int32
+	service  string
+	headsign string
+	stops    []gtfsStopTime
+}
+
+// gtfsStopTime is a stop of a trip read from the feed, where unknown times are -1.
+type gtfsStopTime struct {
+	sequence           int
+	stop               int32
+	arrival, departure int32
+}
+
+// trips reads the trips of the feed, by id.
+func (f *gtfsFeed) trips(routes map[string]int32) (map[string]*gtfsTripInfo, error) {
+	trips := make(map[string]*gtfsTripInfo)
+	err := f.read("trips.txt", false, func(row map[string]string) error {
+		route, ok := routes[row["route_id"]]
+		if !ok {
+			return ErrInvalidGTFS
+		}
+
+		trips[row["trip_id"]] = &gtfsTripInfo{
+			route:    route,
+			service:  row["service_id"],
+			headsign: row["trip_headsign"],
+		}
+		return nil
+	})
+
+	return trips, err
+}
+
+// stopTimes reads the stop times of each trip, and adds the trips to the patterns
+// of the timetable.
+func (f *gtfsFeed) stopTimes(t *timetable, stops map[string]int32, trips map[string]*gtfsTripInfo) error {
+	err := f.read("stop_times.txt", false, func(row map[string]string) error {
+		trip, ok := trips[row["trip_id"]]
+		if !ok {
+			return ErrInvalidGTFS
+		}
+		stop, ok := stops[row["stop_id"]]
+		if !ok {
+			return ErrInvalidGTFS
+		}
+		sequence, err := strconv.Atoi(row["stop_sequence"])
+		if err != nil {
+			return ErrInvalidGTFS
+		}
+		arrival, err := parseGTFSTime(row["arrival_time"])
+		if err != nil {
+			return err
+		}
+		departure, err := parseGTFSTime(row["departure_time"])
+		if err != nil {
+			return err
+		}
+
+		trip.stops = append(trip.stops, gtfsStopTime{sequence, stop, arrival, departure})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(trips))
+	for id := range trips {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	patterns := make(map[string]int32)
+	for _, id := range ids {
+		trip := trips[id]
+		if len(trip.stops) < 2 {
+			continue
+		}
+
+		sort.Sort(gtfsStopTimes(trip.stops))
+		if !interpolate(trip.stops) {
+			return fmt.Errorf("%v: trip %v has no times", ErrInvalidGTFS, id)
+		}
+
+		key := fmt.Sprint(trip.route)
+		patternStops := make([]int32, len(trip.stops))
+		gt := gtfsTrip{
+			Service:    trip.service,
+			Headsign:   trip.headsign,
+			Arrivals:   make([]int32, len(trip.stops)),
+			Departures: make([]int32, len(trip.stops)),
+		}
+		for i, st := range trip.stops {
+			key += fmt.Sprintf(",%v", st.stop)
+			patternStops[i] = st.stop
+			gt.Arrivals[i], gt.Departures[i] = st.arrival, st.departure
+		}
+
+		p, ok := patterns[key]
+		if !ok {
+			p = int32(len(t.Patterns))
+			patterns[key] = p
+			t.Patterns = append(t.Patterns, gtfsPattern{Route: trip.route, Stops: patternStops})
+		}
+		t.Patterns[p].Trips = append(t.Patterns[p].Trips, gt)
+	}
+
+	for _, p := range t.Patterns {
+		sort.Sort(gtfsTrips(p.Trips))
+	}
+	return nil
+}
+
+// interpolate fills in the unknown times of the stops of a trip, evenly spaced between
+// the known times around them, returning false if the trip has no known times.
+func interpolate(stops []gtfsStopTime) bool {
+	prev := -1
+	for i := range stops {
+		if stops[i].arrival < 0 {
+			stops[i].arrival = stops[i].departure
+		}
+		if stops[i].departure < 0 {
+			stops[i].departure = stops[i].arrival
+		}
+		if stops[i].arrival < 0 {
+			continue
+		}
+
+		if prev < 0 && i > 0 {
+			return false
+		} else if prev >= 0 && i-prev > 1 {
+			start, end := stops[prev].departure, stops[i].arrival
+			for j := prev + 1; j < i; j++ {
+				t := start + (end-start)*int32(j-prev)/int32(i-prev)
+				stops[j].arrival, stops[j].departure = t, t
+			}
+		}
+		prev = i
+	}
+
+	return prev == len(stops)-1
+}
+
+// parseGTFSTime parses a time of a GTFS feed, such as "25:35:00" for 1:35 AM the day
+// after the service day, into seconds. Empty times are unknown and returned as -1.
+func parseGTFSTime(s string) (int32, error) {
+	if len(s) == 0 {
+		return -1, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, ErrInvalidGTFS
+	}
+
+	var seconds int32
+	for _, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return 0, ErrInvalidGTFS
+		}
+		seconds = seconds*60 + int32(v)
+	}
+
+	return seconds, nil
+}
+
+// index finds the patterns serving each stop, and the stops within walking distance
+// of each other.
+func (t *timetable) index() {
+	t.StopPatterns = make([][]int32, len(t.Stops))
+	for p, pattern := range t.Patterns {
+		for _, s := range pattern.Stops {
+			if n := len(t.StopPatterns[s]); n == 0 || t.StopPatterns[s][n-1] != int32(p) {
+				t.StopPatterns[s] = append(t.StopPatterns[s], int32(p))
+			}
+		}
+	}
+
+	// Stops are bucketed into cells as wide as the transfer distance at the latitude
+	// of the feed, to only measure the distance to the stops nearby.
+	var maxLat float64
+	for _, s := range t.Stops {
+		maxLat = math.Max(maxLat, math.Abs(s.Lat))
+	}
+	cells := newGrid(gtfsTransferDistance, maxLat)
+	for i, s := range t.Stops {
+		cells.add(s.Lat, s.Lng, int32(i))
+	}
+
+	t.Transfers = make([][]gtfsTransfer, len(t.Stops))
+	for i, s := range t.Stops {
+		cells.near(s.Lat, s.Lng, func(j int32) {
+			if int(j) == i {
+				return
+			}
+
+			o := t.Stops[j]
+			if d := Haversine(s.Lat, s.Lng, o.Lat, o.Lng); d <= gtfsTransferDistance {
+				t.Transfers[i] = append(t.Transfers[i], gtfsTransfer{Stop: j, Meters: float32(d * DefaultDetour)})
+			}
+		})
+	}
+}
+
+// gtfsStopTimes sorts the stops of a trip by sequence.
+type gtfsStopTimes []gtfsStopTime
+
+func (s gtfsStopTimes) Len() int           { return len(s) }
+func (s gtfsStopTimes) Less(i, j int) bool { return s[i].sequence < s[j].sequence }
+func (s gtfsStopTimes) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// gtfsTrips sorts the trips of a pattern by their departure from the first stop.
+type gtfsTrips []gtfsTrip
+
+func (t gtfsTrips) Len() int           { return len(t) }
+func (t gtfsTrips) Less(i, j int) bool { return t[i].Departures[0] < t[j].Departures[0] }
+func (t gtfsTrips) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
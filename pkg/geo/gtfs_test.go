@@ -0,0 +1,386 @@
+package geo
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gtfsFeedZip zips the GTFS feed in testdata to a temporary file, with files replaced
+// by the contents provided, or omitted when empty, and returns its path and a function
+// to remove it.
+func gtfsFeedZip(t *testing.T, replace map[string]string) (string, func()) {
+	dir, err := ioutil.TempDir("", "gtfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "feed.zip")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z := zip.NewWriter(f)
+
+	files, err := filepath.Glob("testdata/gtfs/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		name := filepath.Base(file)
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s, ok := replace[name]; ok {
+			if len(s) == 0 {
+				continue
+			}
+			b = []byte(s)
+		}
+
+		// Feeds are sometimes zipped within a directory.
+		w, err := z.Create("feed/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestImportGTFS(t *testing.T) {
+	path, cleanup := gtfsFeedZip(t, nil)
+	defer cleanup()
+
+	tt, err := importGTFS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tt.Zone != "America/Toronto" {
+		t.Fatalf("Unexpected zone, expected=%v, got=%v", "America/Toronto", tt.Zone)
+	} else if len(tt.Stops) != 6 {
+		t.Fatalf("Unexpected number of stops, expected=%v, got=%v", 6, len(tt.Stops))
+	} else if len(tt.Patterns) != 2 {
+		t.Fatalf("Unexpected number of patterns, expected=%v, got=%v", 2, len(tt.Patterns))
+	}
+
+	expectRoutes := []gtfsRoute{{Line: "504 King", Vehicle: "Tram"}, {Line: "1 Yonge-University", Vehicle: "Subway"}}
+	if !reflect.DeepEqual(tt.Routes, expectRoutes) {
+		t.Fatalf("Unexpected routes, expected=%v, got=%v", expectRoutes, tt.Routes)
+	}
+
+	// Patterns are created in order of trip id, and their trips are ordered by
+	// departure, with unknown times interpolated.
+	king := tt.Patterns[1]
+	expectTrips := []gtfsTrip{
+		{Service: "WEEKDAY", Headsign: "Broadview Station", Arrivals: []int32{28800, 29100, 29400}, Departures: []int32{28800, 29100, 29400}},
+		{Service: "WEEKDAY", Headsign: "Broadview Station", Arrivals: []int32{29400, 29700, 30000}, Departures: []int32{29400, 29700, 30000}},
+		{Service: "WEEKDAY", Headsign: "Broadview Station", Arrivals: []int32{88200, 88500, 88800}, Departures: []int32{88200, 88500, 88800}},
+	}
+	if !reflect.DeepEqual(king.Trips, expectTrips) {
+		t.Fatalf("Unexpected trips, expected=%+v, got=%+v", expectTrips, king.Trips)
+	}
+
+	// Parliament St and Parliament Station are close enough to walk between.
+	if len(tt.Transfers[2]) != 1 || tt.Stops[tt.Transfers[2][0].Stop].Name != "Parliament Station Platform" {
+		t.Fatalf("Unexpected transfers, expected=%v, got=%v", "Parliament Station Platform", tt.Transfers[2])
+	}
+
+	tests := []struct {
+		replace map[string]string
+		err     string
+	}{
+		{map[string]string{"stop_times.txt": ""}, "missing stop_times.txt"},
+		{map[string]string{"agency.txt": "agency_name,agency_timezone\nTTC,Mars/Olympus_Mons\n"}, "unknown agency_timezone"},
+		{map[string]string{"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n504-0800,8am,8am,A,1\n"}, "stop_times.txt"},
+		{map[string]string{"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n504-0800,,,A,1\n504-0800,08:10:00,08:10:00,C,2\n"}, "trip 504-0800 has no times"},
+		{map[string]string{"trips.txt": "route_id,service_id,trip_id\n999,WEEKDAY,504-0800\n"}, "trips.txt"},
+	}
+
+	for idx, tt := range tests {
+		path, cleanup := gtfsFeedZip(t, tt.replace)
+		_, err := importGTFS(path)
+		cleanup()
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		}
+	}
+}
+
+func TestNewGTFS(t *testing.T) {
+	path, cleanup := gtfsFeedZip(t, nil)
+	defer cleanup()
+
+	imported, err := NewGTFS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The imported timetable is saved, and read instead of the feed from then on.
+	if err := ioutil.WriteFile(path, []byte("not a feed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path+gtfsTimetableExtension, time.Now().Add(time.Hour), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := NewGTFS(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(imported.timetable, saved.timetable) {
+		t.Fatalf("Unexpected saved timetable, expected=%+v, got=%+v", imported.timetable, saved.timetable)
+	}
+
+	// Timetables saved by another version are imported again.
+	stale := *imported.timetable
+	stale.Version = gtfsTimetableVersion - 1
+	if err := stale.save(path + gtfsTimetableExtension); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path+gtfsTimetableExtension, time.Now().Add(time.Hour), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewGTFS(path); err == nil {
+		t.Fatal("Expected the feed to be imported instead of a timetable saved by another version")
+	}
+
+	if _, err := NewGTFS(path + ".missing"); err == nil {
+		t.Fatal("Expected error for a missing feed")
+	}
+}
+
+func TestTimetable_index(t *testing.T) {
+	// Degrees of longitude are short this far north, so stops further apart in
+	// degrees than near the equator are within the transfer distance.
+	tt := timetable{Stops: []gtfsStop{
+		{Name: "Storgata", Lat: 69.65, Lng: 18.9499},
+		{Name: "Sjøgata", Lat: 69.65, Lng: 18.9571},
+		{Name: "Havnegata", Lat: 69.65, Lng: 18.9650},
+	}}
+	tt.index()
+
+	expect := [][]int32{{1}, {0}, nil}
+	for i, transfers := range tt.Transfers {
+		var stops []int32
+		for _, tr := range transfers {
+			stops = append(stops, tr.Stop)
+		}
+		if !reflect.DeepEqual(stops, expect[i]) {
+			t.Fatalf("[#%v] Unexpected transfers, expected=%v, got=%v", i, expect[i], stops)
+		}
+	}
+}
+
+func TestGTFS_Duration_nextDay(t *testing.T) {
+	stopTimes, err := ioutil.ReadFile("testdata/gtfs/stop_times.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trips, err := ioutil.ReadFile("testdata/gtfs/trips.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first streetcar of the day runs shortly after midnight.
+	path, cleanup := gtfsFeedZip(t, map[string]string{
+		"stop_times.txt": string(stopTimes) + "504-0005,00:05:00,00:05:00,A,1\n504-0005,00:10:00,00:10:00,B,2\n504-0005,00:15:00,00:15:00,C,3\n",
+		"trips.txt":      string(trips) + "504,WEEKDAY,504-0005,Broadview Station\n",
+	})
+	defer cleanup()
+
+	g, err := NewGTFS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		depart   time.Time
+		duration time.Duration
+		steps    []string
+	}{
+		// Tuesday's first streetcar is faster than walking from late on Monday.
+		{time.Date(2017, time.May, 1, 23, 59, 0, 0, toronto), 11*time.Minute + 13*time.Second, []string{
+			"Walk to King St West at Bay St", "Tram towards Broadview Station", "Walk to destination",
+		}},
+		// Service doesn't run on Saturdays.
+		{time.Date(2017, time.May, 5, 23, 59, 0, 0, toronto), 12*time.Minute + 33*time.Second, []string{"Walk to destination"}},
+	}
+
+	for idx, tt := range tests {
+		e, err := g.Duration("43.6501,-79.3801", "43.6501,-79.3701", Transit, Options{DepartAt: tt.depart})
+		if err != nil {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, nil, err)
+		} else if e.Duration != tt.duration {
+			t.Fatalf("[#%v] Unexpected duration, expected=%v, got=%v", idx, tt.duration, e.Duration)
+		}
+
+		steps := make([]string, len(e.Steps))
+		for i, s := range e.Steps {
+			steps[i] = s.Instructions
+		}
+		if !reflect.DeepEqual(steps, tt.steps) {
+			t.Fatalf("[#%v] Unexpected steps, expected=%v, got=%v", idx, tt.steps, steps)
+		}
+	}
+}
+
+func TestParseGTFSTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		seconds int32
+		err     error
+	}{
+		{"08:05:30", 8*60*60 + 5*60 + 30, nil},
+		{"8:05:30", 8*60*60 + 5*60 + 30, nil},
+		{"25:35:00", 25*60*60 + 35*60, nil},
+		{"", -1, nil},
+		{"08:05", 0, ErrInvalidGTFS},
+		{"08:-5:00", 0, ErrInvalidGTFS},
+	}
+
+	for idx, tt := range tests {
+		seconds, err := parseGTFSTime(tt.in)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if seconds != tt.seconds {
+			t.Fatalf("[#%v] Unexpected seconds, expected=%v, got=%v", idx, tt.seconds, seconds)
+		}
+	}
+}
+
+func TestGTFS_Duration(t *testing.T) {
+	path, cleanup := gtfsFeedZip(t, nil)
+	defer cleanup()
+
+	g, err := NewGTFS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2017, time.May, day, hour, min, 0, 0, toronto)
+	}
+
+	defer func() { now = time.Now }()
+	now = func() time.Time { return at(1, 7, 58) }
+
+	const (
+		bay        = "43.6501,-79.3801"
+		church     = "43.6501,-79.3701"
+		parliament = "43.6501,-79.3601"
+		bloor      = "43.6701,-79.3601"
+	)
+
+	tests := []struct {
+		from, to  string
+		tm        TravelMode
+		opts      Options
+		duration  time.Duration
+		transfers int
+		steps     []string
+		err       error
+	}{
+		// Transferring from the streetcar to the subway.
+		{bay, bloor, Transit, Options{}, 22*time.Minute + 13*time.Second, 1, []string{
+			"Walk to King St West at Bay St", "Tram towards Broadview Station", "Walk to Parliament Station Platform", "Subway towards Bloor", "Walk to destination",
+		}, nil},
+		{bay, bloor, Transit, Options{DepartAt: at(1, 8, 5)}, 25*time.Minute + 13*time.Second, 1, nil, nil},
+		// The latest departure that arrives in time.
+		{bay, bloor, Transit, Options{ArriveBy: at(1, 8, 30)}, 21*time.Minute + 13*time.Second, 1, nil, nil},
+		{bay, bloor, Transit, Options{ArriveBy: at(1, 8, 20)}, 0, 0, nil, ErrNoTransitRoute},
+		{bay, bloor, Transit, Options{TransitModes: []TransitMode{TransitRail}}, 22*time.Minute + 13*time.Second, 1, nil, nil},
+		{bay, bloor, Transit, Options{TransitModes: []TransitMode{TransitBus}}, 0, 0, nil, ErrNoTransitRoute},
+		// Service doesn't run on holidays.
+		{bay, bloor, Transit, Options{DepartAt: at(22, 7, 58)}, 0, 0, nil, ErrNoTransitRoute},
+		// The last streetcar of Monday's service runs after midnight.
+		{bay, parliament, Transit, Options{DepartAt: at(2, 0, 20)}, 20*time.Minute + 13*time.Second, 0, []string{
+			"Walk to King St West at Bay St", "Tram towards Broadview Station", "Walk to destination",
+		}, nil},
+		{bay, church, Transit, Options{}, 7*time.Minute + 13*time.Second, 0, nil, nil},
+		// Walking is faster when there's no service.
+		{bay, church, Transit, Options{DepartAt: at(1, 12, 0)}, 12*time.Minute + 33*time.Second, 0, []string{"Walk to destination"}, nil},
+		{bay, bloor, Drive, Options{}, 0, 0, nil, ErrUnsupported},
+		{"Bay St", bloor, Transit, Options{}, 0, 0, nil, ErrBadLocation},
+	}
+
+	for idx, tt := range tests {
+		e, err := g.Duration(tt.from, tt.to, tt.tm, tt.opts)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if e.Duration != tt.duration {
+			t.Fatalf("[#%v] Unexpected duration, expected=%v, got=%v", idx, tt.duration, e.Duration)
+		} else if e.Transfers != tt.transfers {
+			t.Fatalf("[#%v] Unexpected transfers, expected=%v, got=%v", idx, tt.transfers, e.Transfers)
+		}
+
+		var duration time.Duration
+		var distance int
+		for _, s := range e.Steps {
+			duration += s.Duration
+			distance += s.Distance
+		}
+		if duration > e.Duration {
+			t.Fatalf("[#%v] Unexpected step durations, expected at most=%v, got=%v", idx, e.Duration, duration)
+		} else if distance != e.Distance {
+			t.Fatalf("[#%v] Unexpected distance, expected=%v, got=%v", idx, distance, e.Distance)
+		}
+
+		if tt.steps == nil {
+			continue
+		}
+		steps := make([]string, len(e.Steps))
+		for i, s := range e.Steps {
+			steps[i] = s.Instructions
+		}
+		if !reflect.DeepEqual(steps, tt.steps) {
+			t.Fatalf("[#%v] Unexpected steps, expected=%v, got=%v", idx, tt.steps, steps)
+		}
+	}
+
+	e, err := g.Duration(bay, bloor, Transit, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := TransitDetails{
+		Line:          "1 Yonge-University",
+		Vehicle:       "Subway",
+		Headsign:      "Bloor",
+		DepartureStop: "Parliament Station Platform",
+		DepartureTime: at(1, 8, 12),
+		ArrivalStop:   "Bloor Station",
+		ArrivalTime:   at(1, 8, 20),
+		NumStops:      2,
+	}
+	if got := e.Steps[3].Transit; got == nil || got.DepartureTime.Unix() != expect.DepartureTime.Unix() ||
+		got.ArrivalTime.Unix() != expect.ArrivalTime.Unix() || got.Line != expect.Line || got.Vehicle != expect.Vehicle ||
+		got.Headsign != expect.Headsign || got.DepartureStop != expect.DepartureStop || got.ArrivalStop != expect.ArrivalStop ||
+		got.NumStops != expect.NumStops {
+		t.Fatalf("Unexpected transit details, expected=%+v, got=%+v", expect, got)
+	}
+}
@@ -0,0 +1,75 @@
+package geo
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// MapboxProvider is the name of the Mapbox Provider.
+	MapboxProvider = "mapbox"
+
+	// MapboxTokenSetting is the Provider setting containing the Mapbox access token.
+	MapboxTokenSetting = "token"
+
+	mapboxURL              = "https://api.mapbox.com"
+	mapboxDirectionService = "directions/v5/mapbox"
+	mapboxMatrixService    = "directions-matrix/v1/mapbox"
+	// mapboxMaxMatrixSize and mapboxMaxTrafficMatrixSize are the most coordinates in a
+	// Matrix API request, and in one with the driving-traffic profile.
+	mapboxMaxMatrixSize        = 25
+	mapboxMaxTrafficMatrixSize = 10
+)
+
+var (
+	// ErrMapboxTokenMissing is returned when creating a Mapbox Provider without an access token.
+	ErrMapboxTokenMissing = errors.New("missing Mapbox token provider setting")
+
+	mapboxProfiles = map[TravelMode]string{
+		Drive: "driving-traffic",
+		Walk:  "walking",
+		Bike:  "cycling",
+	}
+)
+
+// Mapbox estimates durations using the Mapbox Directions and Matrix APIs, which
+// are compatible with the OSRM route and table services.
+//
+// Driving durations account for live traffic, or the traffic expected at the
+// departure time. Mapbox doesn't support transit, and locations must be provided
+// as "lat,lng" coordinates.
+type Mapbox struct {
+	OSRM
+}
+
+// NewMapbox initializes and returns a Mapbox with an access token.
+func NewMapbox(token string) *Mapbox {
+	return &Mapbox{OSRM{
+		name:            MapboxProvider,
+		url:             mapboxURL,
+		profiles:        mapboxProfiles,
+		routeService:    mapboxDirectionService,
+		tableService:    mapboxMatrixService,
+		params:          url.Values{"access_token": {token}},
+		traffic:         true,
+		excludeDefault:  true,
+		maxTable:        mapboxMaxMatrixSize,
+		maxTrafficTable: mapboxMaxTrafficMatrixSize,
+		client:          http.DefaultClient,
+	}}
+}
+
+// newMapboxProvider creates a Mapbox Provider from the token setting.
+func newMapboxProvider(c ProviderConfig) (Provider, error) {
+	token := c.Settings[MapboxTokenSetting]
+	if len(token) == 0 {
+		return nil, ErrMapboxTokenMissing
+	}
+
+	m := NewMapbox(token)
+	if c.Client != nil {
+		m.client = c.Client
+	}
+	return m, nil
+}
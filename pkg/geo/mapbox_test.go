@@ -0,0 +1,189 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mapboxServer returns a local server replaying a recorded Mapbox response, and
+// the Mapbox pointed at it.
+func mapboxServer(t *testing.T, path, query, fixture string) (*httptest.Server, *Mapbox) {
+	body, err := ioutil.ReadFile("testdata/" + fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := osrmServer(t, path, query, http.StatusOK, string(body))
+
+	m := NewMapbox("pk.token")
+	m.url = s.URL
+	return s, m
+}
+
+func TestNewMapbox(t *testing.T) {
+	if _, err := NewProvider(MapboxProvider, ProviderConfig{}); err != ErrMapboxTokenMissing {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrMapboxTokenMissing, err)
+	}
+
+	p, err := NewProvider(MapboxProvider, ProviderConfig{Settings: map[string]string{MapboxTokenSetting: "pk.token"}})
+	if err != nil {
+		t.Fatal(err)
+	} else if m, ok := p.(*Mapbox); !ok {
+		t.Fatalf("Unexpected Provider, expected=*Mapbox, got=%T", p)
+	} else if m.params.Get("access_token") != "pk.token" {
+		t.Fatalf("Unexpected access token, expected=%v, got=%v", "pk.token", m.params.Get("access_token"))
+	}
+}
+
+func TestMapbox_Duration(t *testing.T) {
+	at := time.Date(2017, time.May, 1, 8, 15, 0, 0, time.FixedZone("EDT", -4*60*60))
+
+	tests := []struct {
+		tm      TravelMode
+		opts    Options
+		path    string
+		query   string
+		traffic bool
+	}{
+		{Drive, Options{}, "/directions/v5/mapbox/driving-traffic/-79.38,43.645;-79.35,43.676", "access_token=pk.token&overview=false", true},
+		{Drive, Options{DepartAt: at, Avoid: []Avoid{AvoidTolls}}, "/directions/v5/mapbox/driving-traffic/-79.38,43.645;-79.35,43.676", "access_token=pk.token&depart_at=2017-05-01T12%3A15Z&exclude=toll&overview=false", true},
+		// Mapbox profiles can exclude the default features to avoid.
+		{Drive, Options{Avoid: []Avoid{AvoidTolls}, DefaultAvoid: true}, "/directions/v5/mapbox/driving-traffic/-79.38,43.645;-79.35,43.676", "access_token=pk.token&exclude=toll&overview=false", true},
+		{Walk, Options{DepartAt: at}, "/directions/v5/mapbox/walking/-79.38,43.645;-79.35,43.676", "access_token=pk.token&overview=false", false},
+		{Bike, Options{}, "/directions/v5/mapbox/cycling/-79.38,43.645;-79.35,43.676", "access_token=pk.token&overview=false", false},
+	}
+
+	for idx, tt := range tests {
+		s, m := mapboxServer(t, tt.path, tt.query, "mapbox_directions.json")
+
+		e, err := m.Duration("43.645,-79.38", "43.676,-79.35", tt.tm, tt.opts)
+		s.Close()
+		if err != nil {
+			t.Fatalf("[#%v] %v", idx, err)
+		}
+
+		if e.Duration != time.Duration(1248.633*float64(time.Second)) {
+			t.Fatalf("[#%v] Unexpected Duration, got=%v", idx, e.Duration)
+		} else if e.Distance != 9317 {
+			t.Fatalf("[#%v] Unexpected Distance, expected=%v, got=%v", idx, 9317, e.Distance)
+		} else if e.Traffic != tt.traffic {
+			t.Fatalf("[#%v] Unexpected Traffic, expected=%v, got=%v", idx, tt.traffic, e.Traffic)
+		}
+	}
+
+	// Transit is unsupported.
+	if _, err := NewMapbox("pk.token").Duration("43.645,-79.38", "43.676,-79.35", Transit, Options{}); err != ErrUnsupported {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrUnsupported, err)
+	}
+}
+
+func TestMapbox_Duration_errors(t *testing.T) {
+	path := "/directions/v5/mapbox/driving-traffic/-79.38,43.645;-79.35,43.676"
+	query := "access_token=pk.token&overview=false"
+
+	s := osrmServer(t, path, query, http.StatusUnauthorized, `{"message":"Not Authorized - Invalid Token"}`)
+	m := NewMapbox("pk.token")
+	m.url = s.URL
+	_, err := m.Duration("43.645,-79.38", "43.676,-79.35", Drive, Options{})
+	s.Close()
+	if err == nil || err.Error() != "mapbox: Not Authorized - Invalid Token" {
+		t.Fatalf("Unexpected error, got=%v", err)
+	}
+
+	// The access token isn't exposed by request errors.
+	m.url = "http://invalid.localhost:0"
+	_, err = m.Duration("43.645,-79.38", "43.676,-79.35", Drive, Options{})
+	if err == nil {
+		t.Fatal("Expected request error")
+	} else if strings.Contains(err.Error(), "pk.token") {
+		t.Fatalf("Unexpected access token in error, got=%v", err)
+	}
+}
+
+func TestMapbox_Matrix(t *testing.T) {
+	s, m := mapboxServer(t, "/directions-matrix/v1/mapbox/walking/-79.38,43.645;-79.385,43.652;-79.35,43.676;-79.394,43.65",
+		"access_token=pk.token&annotations=duration%2Cdistance&destinations=2%3B3&sources=0%3B1", "mapbox_matrix.json")
+	defer s.Close()
+
+	res, err := m.Matrix([]string{"43.645,-79.38", "43.652,-79.385"}, []string{"43.676,-79.35", "43.65,-79.394"}, Walk, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res[0][0] == nil || res[0][0].Distance != 9317 || res[0][1].Duration != time.Duration(612.4*float64(time.Second)) {
+		t.Fatalf("Unexpected Estimates, got=%+v", res[0])
+	} else if res[1][0] == nil || res[1][0].Distance != 8890 {
+		t.Fatalf("Unexpected Estimate, got=%+v", res[1][0])
+	} else if res[1][1] != nil {
+		t.Fatalf("Unexpected Estimate, expected=nil, got=%+v", res[1][1])
+	}
+}
+
+func TestMapbox_Matrix_chunks(t *testing.T) {
+	// Each duration is the latitude of the source and the longitude of the destination,
+	// so that the chunks can be checked against the coordinates requested.
+	var requests int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		coordinates := strings.Split(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], ";")
+		if len(coordinates) > mapboxMaxTrafficMatrixSize {
+			t.Errorf("Unexpected coordinates, expected<=%v, got=%v", mapboxMaxTrafficMatrixSize, len(coordinates))
+		}
+
+		position := func(i string) []string {
+			n, _ := strconv.Atoi(i)
+			return strings.Split(coordinates[n], ",")
+		}
+		var durations [][]string
+		for _, src := range strings.Split(r.URL.Query().Get("sources"), ";") {
+			var row []string
+			for _, dst := range strings.Split(r.URL.Query().Get("destinations"), ";") {
+				row = append(row, position(src)[1]+position(dst)[0])
+			}
+			durations = append(durations, row)
+		}
+
+		b, _ := json.Marshal(durations)
+		fmt.Fprintf(w, `{"code":"Ok","durations":%v}`, strings.Replace(string(b), `"`, "", -1))
+	}))
+	defer s.Close()
+
+	m := NewMapbox("pk.token")
+	m.url = s.URL
+
+	var from, to []string
+	for i := 1; i <= 6; i++ {
+		from = append(from, fmt.Sprintf("%v,0", i))
+		to = append(to, fmt.Sprintf("0,%v", i))
+	}
+
+	res, err := m.Matrix(from, to, Drive, Options{})
+	if err != nil {
+		t.Fatal(err)
+	} else if requests != 4 {
+		t.Fatalf("Unexpected requests, expected=%v, got=%v", 4, requests)
+	}
+
+	for i := range from {
+		for j := range to {
+			expect := time.Duration((i+1)*10+j+1) * time.Second
+			if res[i][j] == nil || res[i][j].Duration != expect {
+				t.Fatalf("[#%v,%v] Unexpected Estimate, expected=%v, got=%+v", i, j, expect, res[i][j])
+			}
+		}
+	}
+}
+
+func TestMapbox_Capabilities(t *testing.T) {
+	expect := Capabilities{Traffic: true, Matrix: true}
+	if c := NewMapbox("pk.token").Capabilities(); c != expect {
+		t.Fatalf("Unexpected Capabilities, expected=%+v, got=%+v", expect, c)
+	}
+}
@@ -0,0 +1,92 @@
+package geo
+
+import (
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+const (
+	// maxMatrixDimension is the maximum number of origins or destinations
+	// allowed in a single DistanceMatrix request.
+	maxMatrixDimension = 25
+	// maxMatrixElements is the maximum number of origin and destination
+	// pairs allowed in a single DistanceMatrix request.
+	maxMatrixElements = 100
+)
+
+// Matrix returns the estimated time it will take to travel between each of the
+// From and To addresses, departing or arriving at the time specified by the Options.
+//
+// The Estimates are indexed by From and then To address, and an Estimate is nil when
+// no route is available between a pair. Requests are split into chunks as necessary
+// to stay within the limits of the Google Maps API.
+//
+// Driving estimates account for traffic when a departure time is requested.
+func (r Router) Matrix(from, to []string, tm TravelMode, o Options) ([][]*Estimate, error) {
+	estimates := make([][]*Estimate, len(from))
+	for i := range estimates {
+		estimates[i] = make([]*Estimate, len(to))
+	}
+	if len(from) == 0 || len(to) == 0 {
+		return estimates, nil
+	}
+
+	traffic := tm == Drive && !o.DepartAt.IsZero()
+	fromSize, toSize := matrixChunks(len(from), len(to))
+
+	for fromStart := 0; fromStart < len(from); fromStart += fromSize {
+		fromEnd := minInt(fromStart+fromSize, len(from))
+
+		for toStart := 0; toStart < len(to); toStart += toSize {
+			toEnd := minInt(toStart+toSize, len(to))
+
+			req := matrixRequest(from[fromStart:fromEnd], to[toStart:toEnd], tm, o)
+			if traffic {
+				req.TrafficModel = maps.TrafficModelBestGuess
+			}
+
+			res, err := r.client.DistanceMatrix(context.Background(), &req)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, row := range res.Rows {
+				for j, el := range row.Elements {
+					if fromStart+i >= fromEnd || toStart+j >= toEnd || el.Status != statusOk {
+						continue
+					}
+
+					e := Estimate{Duration: el.Duration, Distance: el.Distance.Meters, Avoided: avoided(tm, o)}
+					if traffic {
+						e.Duration = inTraffic(el)
+						e.Traffic = el.DurationInTraffic > 0
+					}
+					estimates[fromStart+i][toStart+j] = &e
+				}
+			}
+		}
+	}
+
+	return estimates, nil
+}
+
+// matrixChunks returns the number of origins and destinations to include in each
+// DistanceMatrix request, staying within the dimension and element limits.
+func matrixChunks(from, to int) (fromSize, toSize int) {
+	fromSize = minInt(from, maxMatrixDimension)
+	toSize = minInt(to, maxMatrixDimension)
+	if fromSize*toSize > maxMatrixElements {
+		toSize = minInt(toSize, maxMatrixElements/fromSize)
+	}
+
+	return
+}
+
+// minInt returns the smaller of two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
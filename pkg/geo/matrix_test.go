@@ -0,0 +1,170 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+func TestRouter_Matrix(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+
+	addresses := func(prefix string, n int) []string {
+		a := make([]string, n)
+		for i := range a {
+			a[i] = fmt.Sprintf("%v%v", prefix, i)
+		}
+		return a
+	}
+
+	// duration encodes the origin and destination indexes of an address pair
+	// so that the placement of each Estimate can be verified.
+	duration := func(from, to int) time.Duration {
+		return time.Duration(from*1000+to) * time.Second
+	}
+
+	// Chunking
+	tests := []struct {
+		from   int
+		to     int
+		expect int
+	}{
+		{1, 1, 1},
+		{5, 20, 1},
+		{10, 10, 1},
+		{10, 11, 2},
+		{25, 25, 7},
+		{30, 30, 16},
+		{1, 60, 3},
+	}
+
+	for idx, tt := range tests {
+		var requests int
+		mc.distanceFn = func(c context.Context, req *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+			requests++
+			if len(req.Origins) > maxMatrixDimension || len(req.Destinations) > maxMatrixDimension {
+				t.Fatalf("[#%v] Unexpected request dimensions, got=%vx%v", idx, len(req.Origins), len(req.Destinations))
+			} else if len(req.Origins)*len(req.Destinations) > maxMatrixElements {
+				t.Fatalf("[#%v] Unexpected request elements, got=%v", idx, len(req.Origins)*len(req.Destinations))
+			}
+
+			var res maps.DistanceMatrixResponse
+			for _, o := range req.Origins {
+				var row maps.DistanceMatrixElementsRow
+				for _, d := range req.Destinations {
+					from, _ := strconv.Atoi(o[1:])
+					to, _ := strconv.Atoi(d[1:])
+					row.Elements = append(row.Elements, &maps.DistanceMatrixElement{Status: statusOk, Duration: duration(from, to)})
+				}
+				res.Rows = append(res.Rows, row)
+			}
+			return &res, nil
+		}
+
+		estimates, err := r.Matrix(addresses("o", tt.from), addresses("d", tt.to), Walk, Options{})
+		if err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		}
+
+		if requests != tt.expect {
+			t.Fatalf("[#%v] Unexpected number of requests, expected=%v, got=%v", idx, tt.expect, requests)
+		}
+
+		if len(estimates) != tt.from {
+			t.Fatalf("[#%v] Unexpected number of rows, expected=%v, got=%v", idx, tt.from, len(estimates))
+		}
+		for i := range estimates {
+			if len(estimates[i]) != tt.to {
+				t.Fatalf("[#%v] Unexpected number of columns, expected=%v, got=%v", idx, tt.to, len(estimates[i]))
+			}
+			for j, e := range estimates[i] {
+				if e == nil || e.Duration != duration(i, j) {
+					t.Fatalf("[#%v] [%v, %v] Unexpected Estimate, expected=%v, got=%+v", idx, i, j, duration(i, j), e)
+				}
+			}
+		}
+	}
+
+	// Traffic and unavailable elements
+	{
+		departAt := time.Date(2017, time.May, 1, 8, 15, 0, 0, time.UTC)
+		mc.distanceFn = func(c context.Context, req *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+			if req.TrafficModel != maps.TrafficModelBestGuess {
+				t.Fatalf("Unexpected TrafficModel, expected=%v, got=%v", maps.TrafficModelBestGuess, req.TrafficModel)
+			} else if req.Avoid != maps.AvoidTolls {
+				t.Fatalf("Unexpected Avoid, expected=%v, got=%v", maps.AvoidTolls, req.Avoid)
+			}
+
+			return &maps.DistanceMatrixResponse{
+				Rows: []maps.DistanceMatrixElementsRow{
+					{Elements: []*maps.DistanceMatrixElement{
+						{Status: statusOk, Duration: time.Minute, DurationInTraffic: time.Minute * 2, Distance: maps.Distance{Meters: 1000}},
+						{Status: "ZERO_RESULTS"},
+					}},
+				},
+			}, nil
+		}
+
+		estimates, err := r.Matrix([]string{"from"}, []string{"to1", "to2"}, Drive, Options{DepartAt: departAt, Avoid: []Avoid{AvoidTolls}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if e := estimates[0][0]; e == nil || e.Duration != time.Minute*2 || !e.Traffic || e.Distance != 1000 {
+			t.Fatalf("Unexpected Estimate, got=%+v", e)
+		} else if estimates[0][1] != nil {
+			t.Fatalf("Unexpected Estimate, expected=nil, got=%+v", estimates[0][1])
+		}
+	}
+
+	// Empty
+	{
+		estimates, err := r.Matrix(nil, []string{"to"}, Drive, Options{})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(estimates) != 0 {
+			t.Fatalf("Unexpected Estimates, expected=[], got=%v", estimates)
+		}
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.distanceFn = func(c context.Context, req *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+			return nil, e
+		}
+
+		if _, err := r.Matrix([]string{"from"}, []string{"to"}, Drive, Options{}); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+}
+
+func TestMatrixChunks(t *testing.T) {
+	tests := []struct {
+		from, to             int
+		expectFrom, expectTo int
+	}{
+		{1, 1, 1, 1},
+		{10, 10, 10, 10},
+		{10, 25, 10, 10},
+		{25, 25, 25, 4},
+		{100, 1, 25, 1},
+		{1, 100, 1, 25},
+	}
+
+	for idx, tt := range tests {
+		from, to := matrixChunks(tt.from, tt.to)
+		if from != tt.expectFrom || to != tt.expectTo {
+			t.Fatalf("[#%v] Unexpected chunks, expected=%vx%v, got=%vx%v", idx, tt.expectFrom, tt.expectTo, from, to)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// OfflineProvider is the name of the Offline Provider.
+	OfflineProvider = "offline"
+
+	// OfflineDetourSetting is the Provider setting containing the detour factor, which
+	// is the ratio of the distance travelled to the straight line distance.
+	OfflineDetourSetting = "detour"
+	// OfflineDriveSpeedSetting, OfflineWalkSpeedSetting, OfflineBikeSpeedSetting and
+	// OfflineTransitSpeedSetting are the Provider settings containing the average speed
+	// of each TravelMode, in kilometers per hour.
+	OfflineDriveSpeedSetting   = "drive-speed"
+	OfflineWalkSpeedSetting    = "walk-speed"
+	OfflineBikeSpeedSetting    = "bike-speed"
+	OfflineTransitSpeedSetting = "transit-speed"
+
+	// DefaultDetour is the detour factor used when none is configured.
+	DefaultDetour = 1.3
+
+	metersPerKilometer = 1000
+)
+
+var (
+	// ErrInvalidOfflineSetting is returned when the detour factor or a speed of the Offline
+	// Provider isn't a positive number.
+	ErrInvalidOfflineSetting = errors.New("invalid offline provider setting, expected a positive number")
+
+	// DefaultSpeeds are the average speeds of each TravelMode used when none are
+	// configured, in kilometers per hour.
+	DefaultSpeeds = map[TravelMode]float64{
+		Drive:   40,
+		Walk:    5,
+		Bike:    15,
+		Transit: 20,
+	}
+
+	offlineSpeedSettings = map[TravelMode]string{
+		Drive:   OfflineDriveSpeedSetting,
+		Walk:    OfflineWalkSpeedSetting,
+		Bike:    OfflineBikeSpeedSetting,
+		Transit: OfflineTransitSpeedSetting,
+	}
+)
+
+// Offline estimates durations without a network connection, from the great-circle
+// distance between two coordinates, a detour factor and the average speed of each
+// TravelMode.
+//
+// Estimates are approximate, and are marked as Offline. Locations must be provided
+// as "lat,lng" coordinates.
+type Offline struct {
+	detour float64
+	speeds map[TravelMode]float64
+}
+
+// NewOffline initializes and returns an Offline with a detour factor and the average
+// speeds of each TravelMode, in kilometers per hour. The defaults are used for a
+// detour factor that isn't positive, and for any TravelMode without a speed.
+func NewOffline(detour float64, speeds map[TravelMode]float64) *Offline {
+	o := Offline{
+		detour: detour,
+		speeds: make(map[TravelMode]float64),
+	}
+	if o.detour <= 0 {
+		o.detour = DefaultDetour
+	}
+	for tm, s := range DefaultSpeeds {
+		o.speeds[tm] = s
+	}
+	for tm, s := range speeds {
+		o.speeds[tm] = s
+	}
+
+	return &o
+}
+
+// newOfflineProvider creates an Offline Provider from the detour and speed settings.
+func newOfflineProvider(c ProviderConfig) (Provider, error) {
+	parse := func(setting string) (float64, error) {
+		v, ok := c.Settings[setting]
+		if !ok {
+			return 0, nil
+		}
+
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 {
+			return 0, ErrInvalidOfflineSetting
+		}
+		return f, nil
+	}
+
+	detour, err := parse(OfflineDetourSetting)
+	if err != nil {
+		return nil, err
+	}
+
+	speeds := make(map[TravelMode]float64)
+	for tm, setting := range offlineSpeedSettings {
+		s, err := parse(setting)
+		if err != nil {
+			return nil, err
+		} else if s > 0 {
+			speeds[tm] = s
+		}
+	}
+
+	return NewOffline(detour, speeds), nil
+}
+
+// Duration returns the estimated time it will take to travel between the From and To
+// coordinates, at the average speed of the TravelMode over the straight line distance
+// lengthened by the detour factor.
+func (o *Offline) Duration(from, to string, tm TravelMode, opts Options) (*Estimate, error) {
+	speed, ok := o.speeds[tm]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	f, err := parsePosition(from)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parsePosition(to)
+	if err != nil {
+		return nil, err
+	}
+
+	meters := Haversine(f.Lat, f.Lng, t.Lat, t.Lng) * o.detour
+	seconds := meters / metersPerKilometer / speed * time.Hour.Seconds()
+
+	return &Estimate{
+		Duration: time.Duration(math.Floor(seconds+0.5)) * time.Second,
+		Distance: int(math.Floor(meters + 0.5)),
+		Offline:  true,
+	}, nil
+}
+
+// CurrentLocation is not supported offline.
+func (o *Offline) CurrentLocation() (*Position, error) {
+	return nil, ErrUnsupported
+}
+
+// Capabilities returns the Capabilities of Offline, which only supports estimating
+// durations between coordinates, including for transit.
+func (o *Offline) Capabilities() Capabilities {
+	return Capabilities{Transit: true}
+}
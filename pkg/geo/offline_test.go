@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewOffline(t *testing.T) {
+	o := NewOffline(0, map[TravelMode]float64{Bike: 20})
+	if o.detour != DefaultDetour {
+		t.Fatalf("Unexpected detour, expected=%v, got=%v", DefaultDetour, o.detour)
+	} else if o.speeds[Bike] != 20 || o.speeds[Drive] != DefaultSpeeds[Drive] {
+		t.Fatalf("Unexpected speeds, got=%v", o.speeds)
+	}
+
+	p, err := NewProvider(OfflineProvider, ProviderConfig{Settings: map[string]string{
+		OfflineDetourSetting:     "1.5",
+		OfflineWalkSpeedSetting:  "4.5",
+		OSRMDriveProfileSetting:  "car",
+		OfflineDriveSpeedSetting: "60",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	} else if o, ok := p.(*Offline); !ok {
+		t.Fatalf("Unexpected Provider, expected=*Offline, got=%T", p)
+	} else if o.detour != 1.5 || o.speeds[Walk] != 4.5 || o.speeds[Drive] != 60 || o.speeds[Transit] != DefaultSpeeds[Transit] {
+		t.Fatalf("Unexpected Offline, got=%+v", o)
+	}
+
+	for _, v := range []string{"fast", "0", "-5"} {
+		if _, err := NewProvider(OfflineProvider, ProviderConfig{Settings: map[string]string{OfflineBikeSpeedSetting: v}}); err != ErrInvalidOfflineSetting {
+			t.Fatalf("Unexpected error for '%v', expected=%v, got=%v", v, ErrInvalidOfflineSetting, err)
+		}
+	}
+}
+
+func TestOffline_Duration(t *testing.T) {
+	// One degree of latitude is roughly 111.2 km.
+	o := NewOffline(1.25, map[TravelMode]float64{Drive: 50})
+
+	tests := []struct {
+		tm       TravelMode
+		distance int
+		duration time.Duration
+	}{
+		{Drive, 138994, 2*time.Hour + 46*time.Minute + 48*time.Second},
+		{Walk, 138994, 27*time.Hour + 47*time.Minute + 55*time.Second},
+		{Transit, 138994, 6*time.Hour + 56*time.Minute + 59*time.Second},
+	}
+
+	for idx, tt := range tests {
+		e, err := o.Duration("43.0,-79.0", "44.0, -79.0", tt.tm, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if e.Distance != tt.distance {
+			t.Fatalf("[#%v] Unexpected Distance, expected=%v, got=%v", idx, tt.distance, e.Distance)
+		} else if e.Duration != tt.duration {
+			t.Fatalf("[#%v] Unexpected Duration, expected=%v, got=%v", idx, tt.duration, e.Duration)
+		} else if !e.Offline || e.Traffic {
+			t.Fatalf("[#%v] Unexpected Estimate, expected Offline without Traffic, got=%+v", idx, e)
+		}
+	}
+
+	if _, err := o.Duration("123 Main St", "44.0,-79.0", Drive, Options{}); err != ErrBadLocation {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrBadLocation, err)
+	} else if _, err := o.Duration("43.0,-79.0", "north", Drive, Options{}); err != ErrBadLocation {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrBadLocation, err)
+	} else if _, err := o.Duration("43.0,-79.0", "44.0,-79.0", TravelMode("flying"), Options{}); err != ErrUnsupported {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrUnsupported, err)
+	}
+}
+
+func TestOffline_Capabilities(t *testing.T) {
+	o := NewOffline(0, nil)
+	if c := o.Capabilities(); c != (Capabilities{Transit: true}) {
+		t.Fatalf("Unexpected Capabilities, got=%+v", c)
+	} else if _, err := o.CurrentLocation(); err != ErrUnsupported {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrUnsupported, err)
+	}
+}
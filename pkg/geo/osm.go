@@ -0,0 +1,349 @@
+package geo
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// OSMProvider is the name of the OSM Provider.
+	OSMProvider = "osm"
+
+	// OSMFileSetting is the Provider setting containing the path of the OpenStreetMap
+	// extract to route on, in PBF (".pbf") or XML (".osm") format.
+	OSMFileSetting = "file"
+
+	// OSMMaxSnapDistance is the furthest a location can be from a road accessible to
+	// the TravelMode, in meters.
+	OSMMaxSnapDistance = 1000
+
+	osmPBFExtension   = ".pbf"
+	osmGraphExtension = ".graph"
+	metersPerSecond   = 1 / 3.6
+
+	// osmGraphVersion is the format of the saved roadGraph, which is incremented when
+	// the roadGraph or the way it's imported changes, so that graphs saved by earlier
+	// versions are imported again.
+	osmGraphVersion = 1
+)
+
+var (
+	// ErrOSMFileMissing is returned when creating an OSM Provider without an extract.
+	ErrOSMFileMissing = errors.New("missing OpenStreetMap extract file provider setting")
+	// ErrNoRoute is returned when the locations aren't connected by roads accessible
+	// to the TravelMode.
+	ErrNoRoute = errors.New("no route found between the provided locations")
+
+	osmAvoidFlags = map[Avoid]uint8{
+		AvoidTolls:    osmFlagToll,
+		AvoidHighways: osmFlagMotorway,
+	}
+)
+
+// OSM estimates drive, walk and bike durations without a network connection, by
+// routing on a road graph imported from an OpenStreetMap extract.
+//
+// Locations must be provided as "lat,lng" coordinates, and are snapped to the nearest
+// road accessible to the TravelMode, which is walked to at the start and end of the
+// route.
+type OSM struct {
+	graph *roadGraph
+
+	// maxSpeeds are the fastest speeds of each TravelMode in the graph, which bound
+	// the remaining duration of a route.
+	maxSpeeds osmSpeeds
+
+	// snappable are the features to avoid that each node can still be snapped to
+	// with, by TravelMode, as a bit for each combination of osmAvoidFlags.
+	snappable [][osmModes]uint8
+	// grids index the nodes that can be snapped to by each TravelMode.
+	grids [osmModes]grid
+}
+
+// NewOSM initializes and returns an OSM routing on the OpenStreetMap extract at the
+// path provided.
+//
+// Importing an extract is slow, so the road graph is saved alongside it with the
+// ".graph" extension and reused until the extract changes.
+func NewOSM(path string) (*OSM, error) {
+	g, err := loadRoadGraph(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOSM(g), nil
+}
+
+// newOSM initializes and returns an OSM routing on the graph provided.
+func newOSM(g *roadGraph) *OSM {
+	o := OSM{graph: g}
+	for _, speeds := range g.Speeds {
+		for m, s := range speeds {
+			if s > o.maxSpeeds[m] {
+				o.maxSpeeds[m] = s
+			}
+		}
+	}
+	o.index()
+
+	return &o
+}
+
+// index finds the nodes that can be snapped to by each TravelMode, and buckets them
+// into grids with cells as wide as the furthest snap, so that snapping only measures
+// the distance to nodes nearby.
+//
+// Nodes that are only entered by a usable edge can be snapped to as well, such as
+// the end of a oneway street.
+func (o *OSM) index() {
+	g := o.graph
+
+	o.snappable = make([][osmModes]uint8, len(g.Lat))
+	for n := range g.Lat {
+		for e := g.First[n]; e < g.First[n+1]; e++ {
+			for m := 0; m < osmModes; m++ {
+				for avoid := uint8(0); avoid <= osmFlagToll|osmFlagMotorway; avoid++ {
+					if o.usable(e, m, avoid) {
+						o.snappable[n][m] |= 1 << avoid
+						o.snappable[g.To[e]][m] |= 1 << avoid
+					}
+				}
+			}
+		}
+	}
+
+	var maxLat float64
+	for _, lat := range g.Lat {
+		maxLat = math.Max(maxLat, math.Abs(lat))
+	}
+	for m := range o.grids {
+		o.grids[m] = newGrid(OSMMaxSnapDistance, maxLat)
+	}
+	for n := range g.Lat {
+		for m := range o.grids {
+			if o.snappable[n][m] != 0 {
+				o.grids[m].add(g.Lat[n], g.Lng[n], int32(n))
+			}
+		}
+	}
+}
+
+// newOSMProvider creates an OSM Provider from the extract file setting.
+func newOSMProvider(c ProviderConfig) (Provider, error) {
+	path := c.Settings[OSMFileSetting]
+	if len(path) == 0 {
+		return nil, ErrOSMFileMissing
+	}
+
+	return NewOSM(path)
+}
+
+// loadRoadGraph returns the road graph of the extract at the path provided, reading
+// the saved graph when it's newer than the extract and of the current version, and
+// importing and saving it otherwise.
+func loadRoadGraph(path string) (*roadGraph, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := path + osmGraphExtension
+	if cacheInfo, err := os.Stat(cache); err == nil && !cacheInfo.ModTime().Before(info.ModTime()) {
+		if g, err := readRoadGraph(cache); err == nil && g.Version == osmGraphVersion {
+			return g, nil
+		}
+	}
+
+	g, err := importOSM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// The saved graph only avoids importing the extract again, so the extract can
+	// still be used when its directory isn't writable.
+	g.save(cache)
+	return g, nil
+}
+
+// importOSM imports the road graph of the extract at the path provided.
+func importOSM(path string) (*roadGraph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var x *osmExtract
+	if strings.HasSuffix(path, osmPBFExtension) {
+		x, err = readOSMPBF(f)
+	} else {
+		x, err = readOSMXML(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return x.graph()
+}
+
+// Duration returns the estimated time it will take to travel between the From and To
+// coordinates along the fastest route accessible to the TravelMode, avoiding tolls and
+// highways when driving if requested. Other features to avoid are ignored, and aren't
+// included in the Avoided features of the Estimate.
+func (o *OSM) Duration(from, to string, tm TravelMode, opts Options) (*Estimate, error) {
+	mode, ok := osmModeIndexes[tm]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	f, err := parsePosition(from)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parsePosition(to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the features flagged in the graph can be avoided, so the others aren't
+	// reported as avoided.
+	var avoid uint8
+	var applied []Avoid
+	for _, a := range avoided(tm, opts) {
+		if flag, ok := osmAvoidFlags[a]; ok {
+			avoid |= flag
+			applied = append(applied, a)
+		}
+	}
+
+	start, startMeters, ok := o.snap(f, mode, avoid)
+	if !ok {
+		return nil, ErrBadLocation
+	}
+	end, endMeters, ok := o.snap(t, mode, avoid)
+	if !ok {
+		return nil, ErrBadLocation
+	}
+
+	seconds, meters, ok := o.route(start, end, mode, avoid)
+	if !ok {
+		return nil, ErrNoRoute
+	}
+	meters += startMeters + endMeters
+	seconds += (startMeters + endMeters) / (osmWalkSpeed * metersPerSecond)
+
+	return &Estimate{
+		Duration: time.Duration(math.Floor(seconds+0.5)) * time.Second,
+		Distance: int(math.Floor(meters + 0.5)),
+		Avoided:  applied,
+	}, nil
+}
+
+// CurrentLocation is not supported offline.
+func (o *OSM) CurrentLocation() (*Position, error) {
+	return nil, ErrUnsupported
+}
+
+// Capabilities returns the Capabilities of OSM, which only supports estimating drive,
+// walk and bike durations between coordinates.
+func (o *OSM) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// usable returns true if an edge is accessible to the mode, and has none of the
+// features to avoid.
+func (o *OSM) usable(edge int32, mode int, avoid uint8) bool {
+	return o.graph.Speeds[edge][mode] > 0 && o.graph.Flags[edge]&avoid == 0
+}
+
+// snap returns the nearest node to the Position that has a usable edge, and the
+// distance to it in meters.
+func (o *OSM) snap(p Position, mode int, avoid uint8) (int32, float64, bool) {
+	g := o.graph
+
+	nearest, best := int32(-1), math.Inf(1)
+	o.grids[mode].near(p.Lat, p.Lng, func(n int32) {
+		if o.snappable[n][mode]&(1<<avoid) == 0 {
+			return
+		}
+
+		// Ties go to the first node, regardless of the cell it's in.
+		if d := Haversine(p.Lat, p.Lng, g.Lat[n], g.Lng[n]); d < best || d == best && n < nearest {
+			nearest, best = n, d
+		}
+	})
+
+	return nearest, best, nearest >= 0 && best <= OSMMaxSnapDistance
+}
+
+// route returns the duration in seconds and distance in meters of the fastest route
+// between two nodes, searching with A* guided by the straight line distance to the
+// end travelled at the fastest speed of the mode.
+func (o *OSM) route(start, end int32, mode int, avoid uint8) (float64, float64, bool) {
+	g := o.graph
+	maxSpeed := float64(o.maxSpeeds[mode]) * metersPerSecond
+	remaining := func(n int32) float64 {
+		return Haversine(g.Lat[n], g.Lng[n], g.Lat[end], g.Lng[end]) / maxSpeed
+	}
+
+	seconds := make([]float64, len(g.Lat))
+	meters := make([]float64, len(g.Lat))
+	for i := range seconds {
+		seconds[i] = math.Inf(1)
+	}
+	done := make([]bool, len(g.Lat))
+
+	seconds[start] = 0
+	open := &osmQueue{{node: start, estimate: remaining(start)}}
+	for open.Len() > 0 {
+		n := heap.Pop(open).(osmQueued).node
+		if n == end {
+			return seconds[n], meters[n], true
+		} else if done[n] {
+			continue
+		}
+		done[n] = true
+
+		for e := g.First[n]; e < g.First[n+1]; e++ {
+			if !o.usable(e, mode, avoid) {
+				continue
+			}
+
+			to := g.To[e]
+			s := seconds[n] + float64(g.Meters[e])/(float64(g.Speeds[e][mode])*metersPerSecond)
+			if s < seconds[to] {
+				seconds[to] = s
+				meters[to] = meters[n] + float64(g.Meters[e])
+				heap.Push(open, osmQueued{node: to, estimate: s + remaining(to)})
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// osmQueued is a node queued to be searched, with the estimated duration of the
+// route through it.
+type osmQueued struct {
+	node     int32
+	estimate float64
+}
+
+// osmQueue is a priority queue of nodes, ordered by the estimated duration of the
+// route through them.
+type osmQueue []osmQueued
+
+func (q osmQueue) Len() int            { return len(q) }
+func (q osmQueue) Less(i, j int) bool  { return q[i].estimate < q[j].estimate }
+func (q osmQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *osmQueue) Push(x interface{}) { *q = append(*q, x.(osmQueued)) }
+func (q *osmQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
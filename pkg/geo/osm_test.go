@@ -0,0 +1,373 @@
+package geo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const osmExtractFixture = "testdata/osm_extract.osm"
+
+func TestNewOSM(t *testing.T) {
+	if _, err := NewProvider(OSMProvider, ProviderConfig{}); err != ErrOSMFileMissing {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrOSMFileMissing, err)
+	}
+
+	dir, err := ioutil.TempDir("", "osm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := ioutil.ReadFile(osmExtractFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "extract.osm")
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewProvider(OSMProvider, ProviderConfig{Settings: map[string]string{OSMFileSetting: path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, ok := p.(*OSM)
+	if !ok {
+		t.Fatalf("Unexpected Provider, expected=*OSM, got=%T", p)
+	}
+
+	// The imported graph is saved, and read instead of the extract from then on.
+	if _, err := os.Stat(path + osmGraphExtension); err != nil {
+		t.Fatalf("Expected road graph to be saved, got=%v", err)
+	}
+	if err := ioutil.WriteFile(path, []byte("not an extract"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path+osmGraphExtension, time.Now().Add(time.Hour), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := NewOSM(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(imported, saved) {
+		t.Fatalf("Unexpected saved OSM, expected=%+v, got=%+v", imported, saved)
+	}
+
+	// Graphs saved by another version are imported again.
+	stale := *imported.graph
+	stale.Version = osmGraphVersion - 1
+	if err := stale.save(path + osmGraphExtension); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path+osmGraphExtension, time.Now().Add(time.Hour), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewOSM(path); err == nil {
+		t.Fatal("Expected the extract to be imported instead of a graph saved by another version")
+	}
+
+	if _, err := NewOSM(filepath.Join(dir, "missing.osm")); err == nil {
+		t.Fatal("Expected error for a missing extract")
+	}
+}
+
+func TestOSM_Duration(t *testing.T) {
+	g, err := importOSM(osmExtractFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := newOSM(g)
+
+	tests := []struct {
+		from, to string
+		tm       TravelMode
+		opts     Options
+		duration time.Duration
+		distance int
+		err      error
+	}{
+		// The toll motorway is faster than the living street.
+		{"43.65,-79.38", "43.65,-79.36", Drive, Options{}, 4*time.Minute + 31*time.Second, 3833, nil},
+		{"43.65,-79.38", "43.65,-79.36", Drive, Options{Avoid: []Avoid{AvoidTolls}}, 9*time.Minute + 39*time.Second, 1609, nil},
+		{"43.65,-79.38", "43.65,-79.36", Drive, Options{Avoid: []Avoid{AvoidHighways}}, 9*time.Minute + 39*time.Second, 1609, nil},
+		{"43.65,-79.38", "43.65,-79.36", Walk, Options{}, 19*time.Minute + 19*time.Second, 1609, nil},
+		// The living street is oneway, except for bikes.
+		{"43.65,-79.36", "43.65,-79.38", Bike, Options{}, 6*time.Minute + 26*time.Second, 1609, nil},
+		{"43.65,-79.36", "43.65,-79.38", Drive, Options{}, 0, 0, ErrNoRoute},
+		// Locations are walked to from the nearest road accessible to the mode.
+		{"43.649,-79.37", "43.65,-79.38", Drive, Options{}, 6*time.Minute + 10*time.Second, 916, nil},
+		{"43.649,-79.37", "43.65,-79.38", Walk, Options{}, 10*time.Minute + 59*time.Second, 916, nil},
+		{"43.6501,-79.38", "43.65,-79.37", Walk, Options{}, 9*time.Minute + 47*time.Second, 816, nil},
+		{"43.65,-79.38", "43.65,-79.38", Walk, Options{}, 0, 0, nil},
+		// Buildings aren't roads.
+		{"43.70,-79.40", "43.65,-79.38", Walk, Options{}, 0, 0, ErrBadLocation},
+		{"Toronto", "43.65,-79.38", Walk, Options{}, 0, 0, ErrBadLocation},
+		{"43.65,-79.38", "43.65,-79.36", Transit, Options{}, 0, 0, ErrUnsupported},
+	}
+
+	for idx, tt := range tests {
+		e, err := o.Duration(tt.from, tt.to, tt.tm, tt.opts)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if err != nil {
+			continue
+		}
+
+		if e.Duration != tt.duration {
+			t.Fatalf("[#%v] Unexpected duration, expected=%v, got=%v", idx, tt.duration, e.Duration)
+		} else if e.Distance != tt.distance {
+			t.Fatalf("[#%v] Unexpected distance, expected=%v, got=%v", idx, tt.distance, e.Distance)
+		} else if !reflect.DeepEqual(e.Avoided, avoided(tt.tm, tt.opts)) {
+			t.Fatalf("[#%v] Unexpected avoided features, expected=%v, got=%v", idx, avoided(tt.tm, tt.opts), e.Avoided)
+		}
+	}
+
+	// Ferries can't be avoided, so they aren't reported as avoided.
+	e, err := o.Duration("43.65,-79.38", "43.65,-79.36", Drive, Options{Avoid: []Avoid{AvoidFerries, AvoidTolls}})
+	if err != nil {
+		t.Fatal(err)
+	} else if expect := []Avoid{AvoidTolls}; !reflect.DeepEqual(e.Avoided, expect) {
+		t.Fatalf("Unexpected avoided features, expected=%v, got=%v", expect, e.Avoided)
+	} else if e.Distance != 1609 {
+		t.Fatalf("Unexpected distance, expected=%v, got=%v", 1609, e.Distance)
+	}
+
+	e, err = o.Duration("43.65,-79.38", "43.65,-79.36", Drive, Options{Avoid: []Avoid{AvoidFerries}})
+	if err != nil {
+		t.Fatal(err)
+	} else if e.Avoided != nil {
+		t.Fatalf("Unexpected avoided features, expected=%v, got=%v", nil, e.Avoided)
+	}
+}
+
+func TestOSMWayRules(t *testing.T) {
+	tests := []struct {
+		tags     map[string]string
+		forward  osmSpeeds
+		backward osmSpeeds
+		flags    uint8
+	}{
+		{map[string]string{"highway": "residential"}, osmSpeeds{30, 5, 15}, osmSpeeds{30, 5, 15}, 0},
+		{map[string]string{"highway": "residential", "maxspeed": "25 mph"}, osmSpeeds{40, 5, 15}, osmSpeeds{40, 5, 15}, 0},
+		{map[string]string{"highway": "residential", "maxspeed": "signals"}, osmSpeeds{30, 5, 15}, osmSpeeds{30, 5, 15}, 0},
+		{map[string]string{"highway": "residential", "oneway": "-1"}, osmSpeeds{0, 5, 0}, osmSpeeds{30, 5, 15}, 0},
+		{map[string]string{"highway": "primary", "junction": "roundabout"}, osmSpeeds{65, 5, 15}, osmSpeeds{0, 5, 0}, 0},
+		{map[string]string{"highway": "motorway", "oneway": "no", "toll": "yes"}, osmSpeeds{100, 0, 0}, osmSpeeds{100, 0, 0}, osmFlagToll | osmFlagMotorway},
+		{map[string]string{"highway": "service", "access": "private"}, osmSpeeds{}, osmSpeeds{}, 0},
+		{map[string]string{"highway": "service", "access": "no", "foot": "yes"}, osmSpeeds{0, 5, 0}, osmSpeeds{0, 5, 0}, 0},
+		{map[string]string{"highway": "track", "motor_vehicle": "destination"}, osmSpeeds{10, 5, 15}, osmSpeeds{10, 5, 15}, 0},
+		{map[string]string{"highway": "footway", "bicycle": "designated"}, osmSpeeds{0, 5, 15}, osmSpeeds{0, 5, 15}, 0},
+		{map[string]string{"highway": "cycleway", "foot": "no"}, osmSpeeds{0, 0, 15}, osmSpeeds{0, 0, 15}, 0},
+	}
+
+	for idx, tt := range tests {
+		forward, backward, flags := osmWayRules(tt.tags)
+		if forward != tt.forward {
+			t.Fatalf("[#%v] Unexpected forward speeds, expected=%v, got=%v", idx, tt.forward, forward)
+		} else if backward != tt.backward {
+			t.Fatalf("[#%v] Unexpected backward speeds, expected=%v, got=%v", idx, tt.backward, backward)
+		} else if flags != tt.flags {
+			t.Fatalf("[#%v] Unexpected flags, expected=%v, got=%v", idx, tt.flags, flags)
+		}
+	}
+}
+
+func TestReadOSMPBF(t *testing.T) {
+	f, err := os.Open(osmExtractFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	x, err := readOSMXML(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the nodes of highways are kept, and not those of the building, or those
+	// outside of the extract.
+	var ids []int64
+	for id := range x.nodes {
+		ids = append(ids, id)
+	}
+	sort.Sort(int64s(ids))
+	if expect := []int64{1, 2, 3, 4, 5, 8}; !reflect.DeepEqual(ids, expect) {
+		t.Fatalf("Unexpected nodes, expected=%v, got=%v", expect, ids)
+	}
+
+	expected, err := x.graph()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pbf, err := readOSMPBF(bytes.NewReader(encodePBF(x, "OsmSchema-V0.6", "DenseNodes")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := pbf.graph()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(pbf.nodes) != len(x.nodes) {
+		t.Fatalf("Unexpected nodes, expected=%v, got=%v", len(x.nodes), len(pbf.nodes))
+	}
+
+	if !reflect.DeepEqual(g.First, expected.First) || !reflect.DeepEqual(g.To, expected.To) ||
+		!reflect.DeepEqual(g.Speeds, expected.Speeds) || !reflect.DeepEqual(g.Flags, expected.Flags) {
+		t.Fatalf("Unexpected road graph, expected=%+v, got=%+v", *expected, *g)
+	}
+	for i := range g.Lat {
+		if math.Abs(g.Lat[i]-expected.Lat[i]) > 1e-7 || math.Abs(g.Lng[i]-expected.Lng[i]) > 1e-7 {
+			t.Fatalf("[#%v] Unexpected position, expected=%v,%v, got=%v,%v", i, expected.Lat[i], expected.Lng[i], g.Lat[i], g.Lng[i])
+		}
+	}
+
+	_, err = readOSMPBF(bytes.NewReader(encodePBF(x, "OsmSchema-V0.6", "HistoricalInformation")))
+	if err == nil || !strings.Contains(err.Error(), "HistoricalInformation") {
+		t.Fatalf("Unexpected error, expected unsupported feature, got=%v", err)
+	}
+
+	truncated := encodePBF(x, "OsmSchema-V0.6")
+	if _, err := readOSMPBF(bytes.NewReader(truncated[:len(truncated)-10])); err == nil {
+		t.Fatal("Expected error for a truncated extract")
+	}
+
+	if _, err := newOSMExtract().graph(); err != ErrEmptyGraph {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrEmptyGraph, err)
+	}
+}
+
+// encodePBF encodes the extract in the PBF format, with a header requiring the features provided.
+func encodePBF(x *osmExtract, features ...string) []byte {
+	var header []byte
+	for _, f := range features {
+		header = append(header, pbField(4, []byte(f))...)
+	}
+
+	strs := []string{""}
+	index := map[string]uint64{"": 0}
+	str := func(s string) uint64 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		index[s] = uint64(len(strs))
+		strs = append(strs, s)
+		return index[s]
+	}
+
+	var ids []int64
+	for id := range x.nodes {
+		ids = append(ids, id)
+	}
+	sort.Sort(int64s(ids))
+
+	var idDeltas, latDeltas, lngDeltas []int64
+	var prevID, prevLat, prevLng int64
+	for _, id := range ids {
+		lat := int64(math.Floor(x.nodes[id].Lat/1e-7 + 0.5))
+		lng := int64(math.Floor(x.nodes[id].Lng/1e-7 + 0.5))
+		idDeltas = append(idDeltas, id-prevID)
+		latDeltas = append(latDeltas, lat-prevLat)
+		lngDeltas = append(lngDeltas, lng-prevLng)
+		prevID, prevLat, prevLng = id, lat, lng
+	}
+	dense := append(pbPackedSints(1, idDeltas), pbPackedSints(8, latDeltas)...)
+	dense = append(dense, pbPackedSints(9, lngDeltas)...)
+
+	var ways []byte
+	for i, w := range x.ways {
+		var keys []string
+		for k := range w.tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var ks, vs []uint64
+		for _, k := range keys {
+			ks = append(ks, str(k))
+			vs = append(vs, str(w.tags[k]))
+		}
+
+		var refs []int64
+		var prev int64
+		for _, ref := range w.refs {
+			refs = append(refs, ref-prev)
+			prev = ref
+		}
+
+		way := append(pbVarintField(1, uint64(i)), pbPacked(2, ks)...)
+		way = append(way, pbPacked(3, vs)...)
+		way = append(way, pbPackedSints(8, refs)...)
+		ways = append(ways, pbField(3, way)...)
+	}
+
+	var table []byte
+	for _, s := range strs {
+		table = append(table, pbField(1, []byte(s))...)
+	}
+	block := append(pbField(1, table), pbField(2, pbField(2, dense))...)
+	block = append(block, pbField(2, ways)...)
+
+	var compressed bytes.Buffer
+	z := zlib.NewWriter(&compressed)
+	z.Write(block)
+	z.Close()
+
+	var b bytes.Buffer
+	writeBlob := func(blobType string, blob []byte) {
+		h := append(pbField(1, []byte(blobType)), pbVarintField(3, uint64(len(blob)))...)
+		binary.Write(&b, binary.BigEndian, uint32(len(h)))
+		b.Write(h)
+		b.Write(blob)
+	}
+	writeBlob(pbfHeaderBlob, append(pbField(1, header), pbVarintField(2, uint64(len(header)))...))
+	writeBlob(pbfDataBlob, append(pbField(3, compressed.Bytes()), pbVarintField(2, uint64(len(block)))...))
+
+	return b.Bytes()
+}
+
+type int64s []int64
+
+func (s int64s) Len() int           { return len(s) }
+func (s int64s) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func pbEncodeVarint(v uint64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, v)]
+}
+
+func pbVarintField(field int, v uint64) []byte {
+	return append(pbEncodeVarint(uint64(field)<<3|pbVarint), pbEncodeVarint(v)...)
+}
+
+func pbField(field int, value []byte) []byte {
+	b := append(pbEncodeVarint(uint64(field)<<3|pbBytes), pbEncodeVarint(uint64(len(value)))...)
+	return append(b, value...)
+}
+
+func pbPacked(field int, vals []uint64) []byte {
+	var b []byte
+	for _, v := range vals {
+		b = append(b, pbEncodeVarint(v)...)
+	}
+	return pbField(field, b)
+}
+
+func pbPackedSints(field int, vals []int64) []byte {
+	zigzag := make([]uint64, len(vals))
+	for i, v := range vals {
+		zigzag[i] = uint64((v << 1) ^ (v >> 63))
+	}
+	return pbPacked(field, zigzag)
+}
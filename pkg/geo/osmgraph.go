@@ -0,0 +1,344 @@
+package geo
+
+import (
+	"encoding/gob"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	osmDrive = iota
+	osmWalk
+	osmBike
+	osmModes
+)
+
+const (
+	osmFlagToll uint8 = 1 << iota
+	osmFlagMotorway
+)
+
+const (
+	osmWalkSpeed  = 5
+	osmBikeSpeed  = 15
+	osmStepsSpeed = 3
+	// osmAccessSpeed is the speed of a road that's open to vehicles by its access
+	// tags, but not by its class, such as a track.
+	osmAccessSpeed = 10
+	osmMaxSpeed    = math.MaxUint8
+	kmhPerMph      = 1.609344
+)
+
+var (
+	// ErrEmptyGraph is returned when an OpenStreetMap extract contains no roads.
+	ErrEmptyGraph = errors.New("no roads found in the OpenStreetMap extract")
+
+	osmModeIndexes = map[TravelMode]int{
+		Drive: osmDrive,
+		Walk:  osmWalk,
+		Bike:  osmBike,
+	}
+
+	// osmHighways are the speeds of each class of highway, in kilometers per hour, by
+	// TravelMode. Zero speeds aren't accessible to the TravelMode.
+	osmHighways = map[string]osmSpeeds{
+		"motorway":       {100, 0, 0},
+		"motorway_link":  {60, 0, 0},
+		"trunk":          {80, 0, 0},
+		"trunk_link":     {50, 0, 0},
+		"primary":        {65, osmWalkSpeed, osmBikeSpeed},
+		"primary_link":   {40, osmWalkSpeed, osmBikeSpeed},
+		"secondary":      {55, osmWalkSpeed, osmBikeSpeed},
+		"secondary_link": {40, osmWalkSpeed, osmBikeSpeed},
+		"tertiary":       {45, osmWalkSpeed, osmBikeSpeed},
+		"tertiary_link":  {35, osmWalkSpeed, osmBikeSpeed},
+		"unclassified":   {35, osmWalkSpeed, osmBikeSpeed},
+		"residential":    {30, osmWalkSpeed, osmBikeSpeed},
+		"road":           {30, osmWalkSpeed, osmBikeSpeed},
+		"living_street":  {10, osmWalkSpeed, osmBikeSpeed},
+		"service":        {15, osmWalkSpeed, osmBikeSpeed},
+		"track":          {0, osmWalkSpeed, osmBikeSpeed},
+		"cycleway":       {0, osmWalkSpeed, osmBikeSpeed},
+		"path":           {0, osmWalkSpeed, osmBikeSpeed},
+		"bridleway":      {0, osmWalkSpeed, 0},
+		"footway":        {0, osmWalkSpeed, 0},
+		"pedestrian":     {0, osmWalkSpeed, 0},
+		"steps":          {0, osmStepsSpeed, 0},
+	}
+
+	// osmAccessKeys are the access tags that apply to each TravelMode, from the most
+	// to the least specific.
+	osmAccessKeys = [osmModes][]string{
+		osmDrive: {"motorcar", "motor_vehicle", "vehicle", "access"},
+		osmWalk:  {"foot", "access"},
+		osmBike:  {"bicycle", "vehicle", "access"},
+	}
+	osmAccessDenied = map[string]bool{
+		"no":      true,
+		"private": true,
+	}
+	osmAccessAllowed = map[string]bool{
+		"yes":         true,
+		"designated":  true,
+		"permissive":  true,
+		"destination": true,
+	}
+	osmDefaultSpeeds = osmSpeeds{osmDrive: osmAccessSpeed, osmWalk: osmWalkSpeed, osmBike: osmBikeSpeed}
+)
+
+// osmSpeeds are the speeds of each TravelMode along an edge, in kilometers per hour.
+type osmSpeeds [osmModes]uint8
+
+// osmWay is a way of an OpenStreetMap extract, with the nodes it passes through.
+type osmWay struct {
+	refs []int64
+	tags map[string]string
+}
+
+// osmExtract is the highways read from an OpenStreetMap extract, and the nodes they
+// pass through.
+//
+// Most nodes of an extract aren't on a highway, and nodes precede the ways that pass
+// through them, so extracts are read twice: first for the highways, and then for
+// only the nodes they reference.
+type osmExtract struct {
+	nodes map[int64]Position
+	ways  []osmWay
+
+	// refs are the nodes the highways pass through.
+	refs map[int64]bool
+}
+
+// newOSMExtract initializes and returns an empty osmExtract.
+func newOSMExtract() *osmExtract {
+	return &osmExtract{
+		nodes: make(map[int64]Position),
+		refs:  make(map[int64]bool),
+	}
+}
+
+// addWay adds the way to the extract when it's a highway, discarding other ways
+// such as buildings.
+func (x *osmExtract) addWay(refs []int64, tags map[string]string) {
+	if _, ok := osmHighways[tags["highway"]]; !ok || tags["area"] == "yes" || len(refs) < 2 {
+		return
+	}
+
+	x.ways = append(x.ways, osmWay{refs: refs, tags: tags})
+	for _, ref := range refs {
+		x.refs[ref] = true
+	}
+}
+
+// addNode adds the node to the extract when a highway passes through it, discarding
+// other nodes such as the corners of buildings.
+func (x *osmExtract) addNode(id int64, p Position) {
+	if x.refs[id] {
+		x.nodes[id] = p
+	}
+}
+
+// roadGraph is a compact road graph imported from an OpenStreetMap extract, with the
+// speed of each TravelMode along each directed edge.
+//
+// Edges are stored in compressed sparse row format, where the edges leaving node i
+// are those from First[i] up to First[i+1]. Fields are exported to be encoded.
+type roadGraph struct {
+	// Version is the osmGraphVersion the graph was imported with.
+	Version int
+
+	Lat, Lng []float64
+	First    []int32
+
+	To     []int32
+	Meters []float32
+	Speeds []osmSpeeds
+	Flags  []uint8
+}
+
+// graph builds the roadGraph of the highways in the extract, keeping only the nodes
+// they pass through.
+func (x *osmExtract) graph() (*roadGraph, error) {
+	type edge struct {
+		from, to int32
+		meters   float32
+		speeds   osmSpeeds
+		flags    uint8
+	}
+
+	g := roadGraph{Version: osmGraphVersion}
+	index := make(map[int64]int32)
+	node := func(id int64) (int32, bool) {
+		if i, ok := index[id]; ok {
+			return i, true
+		}
+		p, ok := x.nodes[id]
+		if !ok {
+			return 0, false
+		}
+
+		i := int32(len(g.Lat))
+		index[id] = i
+		g.Lat = append(g.Lat, p.Lat)
+		g.Lng = append(g.Lng, p.Lng)
+		return i, true
+	}
+
+	var edges []edge
+	for _, w := range x.ways {
+		forward, backward, flags := osmWayRules(w.tags)
+		if forward == (osmSpeeds{}) && backward == (osmSpeeds{}) {
+			continue
+		}
+
+		prev, ok := node(w.refs[0])
+		for _, ref := range w.refs[1:] {
+			// Ways are clipped at the boundary of an extract, so skip segments
+			// that leave it.
+			next, nextOk := node(ref)
+			if ok && nextOk && prev != next {
+				meters := float32(Haversine(g.Lat[prev], g.Lng[prev], g.Lat[next], g.Lng[next]))
+				if forward != (osmSpeeds{}) {
+					edges = append(edges, edge{prev, next, meters, forward, flags})
+				}
+				if backward != (osmSpeeds{}) {
+					edges = append(edges, edge{next, prev, meters, backward, flags})
+				}
+			}
+			prev, ok = next, nextOk
+		}
+	}
+	if len(edges) == 0 {
+		return nil, ErrEmptyGraph
+	}
+
+	// Counting sort the edges by the node they leave.
+	g.First = make([]int32, len(g.Lat)+1)
+	for _, e := range edges {
+		g.First[e.from+1]++
+	}
+	for i := 1; i < len(g.First); i++ {
+		g.First[i] += g.First[i-1]
+	}
+
+	g.To = make([]int32, len(edges))
+	g.Meters = make([]float32, len(edges))
+	g.Speeds = make([]osmSpeeds, len(edges))
+	g.Flags = make([]uint8, len(edges))
+	next := make([]int32, len(g.Lat))
+	copy(next, g.First)
+	for _, e := range edges {
+		i := next[e.from]
+		next[e.from]++
+
+		g.To[i] = e.to
+		g.Meters[i] = e.meters
+		g.Speeds[i] = e.speeds
+		g.Flags[i] = e.flags
+	}
+
+	return &g, nil
+}
+
+// osmWayRules returns the speeds of each TravelMode along a highway in the forward
+// and backward directions, according to its class, access, speed limit and oneway
+// tags, and the route features it has.
+func osmWayRules(tags map[string]string) (forward, backward osmSpeeds, flags uint8) {
+	highway := tags["highway"]
+	speeds := osmHighways[highway]
+
+	for m := 0; m < osmModes; m++ {
+		for _, key := range osmAccessKeys[m] {
+			v := tags[key]
+			if osmAccessDenied[v] {
+				speeds[m] = 0
+				break
+			} else if osmAccessAllowed[v] {
+				if speeds[m] == 0 {
+					speeds[m] = osmDefaultSpeeds[m]
+				}
+				break
+			}
+		}
+	}
+	if maxspeed, ok := parseMaxSpeed(tags["maxspeed"]); ok && speeds[osmDrive] > 0 {
+		speeds[osmDrive] = maxspeed
+	}
+
+	forward, backward = speeds, speeds
+	oneway := tags["oneway"]
+	if len(oneway) == 0 && (tags["junction"] == "roundabout" || highway == "motorway") {
+		oneway = "yes"
+	}
+	switch oneway {
+	case "yes", "true", "1":
+		backward[osmDrive] = 0
+		if tags["oneway:bicycle"] != "no" {
+			backward[osmBike] = 0
+		}
+	case "-1", "reverse":
+		forward[osmDrive] = 0
+		if tags["oneway:bicycle"] != "no" {
+			forward[osmBike] = 0
+		}
+	}
+
+	if tags["toll"] == "yes" {
+		flags |= osmFlagToll
+	}
+	if highway == "motorway" || highway == "motorway_link" {
+		flags |= osmFlagMotorway
+	}
+
+	return forward, backward, flags
+}
+
+// parseMaxSpeed parses a speed limit, such as "50" or "30 mph", into kilometers
+// per hour.
+func parseMaxSpeed(s string) (uint8, bool) {
+	factor := 1.0
+	if strings.HasSuffix(s, "mph") {
+		factor = kmhPerMph
+		s = strings.TrimSuffix(s, "mph")
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+
+	return uint8(math.Min(math.Floor(v*factor+0.5), osmMaxSpeed)), true
+}
+
+// readRoadGraph reads a roadGraph previously written with save.
+func readRoadGraph(path string) (*roadGraph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var g roadGraph
+	if err := gob.NewDecoder(f).Decode(&g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// save writes the roadGraph to the path provided, so that it can be read without
+// importing the OpenStreetMap extract again.
+func (g *roadGraph) save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(f).Encode(g); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
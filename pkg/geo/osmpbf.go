@@ -0,0 +1,472 @@
+package geo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	pbfMaxHeaderSize = 64 * 1024
+	pbfMaxBlobSize   = 32 * 1024 * 1024
+
+	pbfHeaderBlob = "OSMHeader"
+	pbfDataBlob   = "OSMData"
+
+	pbfDefaultGranularity = 100
+	pbfCoordinateScale    = 1e-9
+
+	// Protocol buffer wire types.
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+	pbFixed32 = 5
+)
+
+var (
+	// ErrInvalidPBF is returned when an OpenStreetMap PBF extract is malformed.
+	ErrInvalidPBF = errors.New("invalid OpenStreetMap PBF extract")
+
+	// pbfFeatures are the required features of a PBF extract that can be read.
+	pbfFeatures = map[string]bool{
+		"OsmSchema-V0.6": true,
+		"DenseNodes":     true,
+	}
+)
+
+// readOSMPBF reads the highways of an OpenStreetMap PBF extract and the nodes they
+// pass through. Extracts are a sequence of blobs each containing a zlib compressed
+// block of elements.
+func readOSMPBF(r io.ReadSeeker) (*osmExtract, error) {
+	x := newOSMExtract()
+	if err := x.readPBF(r, false); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := x.readPBF(r, true); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// readPBF reads the nodes of an OpenStreetMap PBF extract, or its ways when nodes
+// is false.
+func (x *osmExtract) readPBF(r io.Reader, nodes bool) error {
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		} else if size > pbfMaxHeaderSize {
+			return ErrInvalidPBF
+		}
+
+		blobType, blobSize, err := readPBFBlobHeader(r, size)
+		if err != nil {
+			return err
+		} else if blobSize > pbfMaxBlobSize {
+			return ErrInvalidPBF
+		}
+
+		block, err := readPBFBlob(r, blobSize)
+		if err != nil {
+			return err
+		}
+
+		switch blobType {
+		case pbfHeaderBlob:
+			err = checkPBFHeader(block)
+		case pbfDataBlob:
+			err = x.readPBFBlock(block, nodes)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readPBFBlobHeader reads a BlobHeader, returning the type and size of the blob that follows.
+func readPBFBlobHeader(r io.Reader, size uint32) (string, uint32, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", 0, err
+	}
+
+	var blobType string
+	var blobSize uint64
+	m := pbMessage(b)
+	for m.next() {
+		switch m.field {
+		case 1:
+			blobType = string(m.bytes())
+		case 3:
+			blobSize = m.varint()
+		}
+	}
+
+	return blobType, uint32(blobSize), m.err
+}
+
+// readPBFBlob reads a Blob, returning its decompressed contents.
+func readPBFBlob(r io.Reader, size uint32) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+
+	var raw, compressed []byte
+	m := pbMessage(b)
+	for m.next() {
+		switch m.field {
+		case 1:
+			raw = m.bytes()
+		case 3:
+			compressed = m.bytes()
+		case 4, 6, 7:
+			return nil, fmt.Errorf("%v: unsupported compression", ErrInvalidPBF)
+		}
+	}
+	if m.err != nil {
+		return nil, m.err
+	} else if raw != nil {
+		return raw, nil
+	}
+
+	z, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	return ioutil.ReadAll(z)
+}
+
+// checkPBFHeader returns an error if the HeaderBlock requires features that can't be read.
+func checkPBFHeader(b []byte) error {
+	m := pbMessage(b)
+	for m.next() {
+		if m.field != 4 {
+			continue
+		}
+
+		if feature := string(m.bytes()); !pbfFeatures[feature] {
+			return fmt.Errorf("%v: unsupported feature %v", ErrInvalidPBF, feature)
+		}
+	}
+
+	return m.err
+}
+
+// pbfBlock is the string table and coordinate encoding shared by the elements of a
+// PrimitiveBlock.
+type pbfBlock struct {
+	strings     []string
+	granularity int64
+	latOffset   int64
+	lngOffset   int64
+}
+
+// position returns the Position of encoded coordinates.
+func (p *pbfBlock) position(lat, lng int64) Position {
+	return Position{
+		Lat: pbfCoordinateScale * float64(p.latOffset+p.granularity*lat),
+		Lng: pbfCoordinateScale * float64(p.lngOffset+p.granularity*lng),
+	}
+}
+
+// str returns the string at an index of the string table.
+func (p *pbfBlock) str(i uint64) string {
+	if i >= uint64(len(p.strings)) {
+		return ""
+	}
+	return p.strings[i]
+}
+
+// readPBFBlock reads the nodes of a PrimitiveBlock, or its ways when nodes is false.
+func (x *osmExtract) readPBFBlock(b []byte, nodes bool) error {
+	block := pbfBlock{granularity: pbfDefaultGranularity}
+	var groups [][]byte
+
+	m := pbMessage(b)
+	for m.next() {
+		switch m.field {
+		case 1:
+			st := pbMessage(m.bytes())
+			for st.next() {
+				if st.field == 1 {
+					block.strings = append(block.strings, string(st.bytes()))
+				}
+			}
+			if st.err != nil {
+				return st.err
+			}
+		case 2:
+			groups = append(groups, m.bytes())
+		case 17:
+			block.granularity = int64(m.varint())
+		case 19:
+			block.latOffset = int64(m.varint())
+		case 20:
+			block.lngOffset = int64(m.varint())
+		}
+	}
+	if m.err != nil {
+		return m.err
+	}
+
+	// Groups are read once the whole block has been, as the string table and
+	// coordinate encoding may follow them.
+	for _, group := range groups {
+		g := pbMessage(group)
+		for g.next() {
+			var err error
+			switch {
+			case g.field == 1 && nodes:
+				err = x.readPBFNode(&block, g.bytes())
+			case g.field == 2 && nodes:
+				err = x.readPBFDenseNodes(&block, g.bytes())
+			case g.field == 3 && !nodes:
+				err = x.readPBFWay(&block, g.bytes())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if g.err != nil {
+			return g.err
+		}
+	}
+
+	return nil
+}
+
+// readPBFNode reads a Node.
+func (x *osmExtract) readPBFNode(block *pbfBlock, b []byte) error {
+	var id, lat, lng int64
+
+	m := pbMessage(b)
+	for m.next() {
+		switch m.field {
+		case 1:
+			id = m.sint()
+		case 8:
+			lat = m.sint()
+		case 9:
+			lng = m.sint()
+		}
+	}
+	if m.err != nil {
+		return m.err
+	}
+
+	x.addNode(id, block.position(lat, lng))
+	return nil
+}
+
+// readPBFDenseNodes reads DenseNodes, whose ids and coordinates are delta encoded.
+func (x *osmExtract) readPBFDenseNodes(block *pbfBlock, b []byte) error {
+	var ids, lats, lngs []int64
+
+	m := pbMessage(b)
+	for m.next() {
+		switch m.field {
+		case 1:
+			ids = m.packedSints()
+		case 8:
+			lats = m.packedSints()
+		case 9:
+			lngs = m.packedSints()
+		}
+	}
+	if m.err != nil {
+		return m.err
+	} else if len(lats) != len(ids) || len(lngs) != len(ids) {
+		return ErrInvalidPBF
+	}
+
+	var id, lat, lng int64
+	for i := range ids {
+		id += ids[i]
+		lat += lats[i]
+		lng += lngs[i]
+		x.addNode(id, block.position(lat, lng))
+	}
+
+	return nil
+}
+
+// readPBFWay reads a Way, whose node references are delta encoded.
+func (x *osmExtract) readPBFWay(block *pbfBlock, b []byte) error {
+	var keys, vals []uint64
+	var refs []int64
+
+	m := pbMessage(b)
+	for m.next() {
+		switch m.field {
+		case 2:
+			keys = m.packedVarints()
+		case 3:
+			vals = m.packedVarints()
+		case 8:
+			refs = m.packedSints()
+		}
+	}
+	if m.err != nil {
+		return m.err
+	} else if len(keys) != len(vals) {
+		return ErrInvalidPBF
+	}
+
+	tags := make(map[string]string, len(keys))
+	for i := range keys {
+		tags[block.str(keys[i])] = block.str(vals[i])
+	}
+
+	var ref int64
+	for i := range refs {
+		ref += refs[i]
+		refs[i] = ref
+	}
+
+	x.addWay(refs, tags)
+	return nil
+}
+
+// pbReader decodes the fields of a protocol buffer message in order. The first error
+// encountered stops decoding, and is stored in err.
+type pbReader struct {
+	b   []byte
+	err error
+
+	field int
+	wire  int
+	value []byte
+	num   uint64
+}
+
+// pbMessage returns a pbReader of the encoded message.
+func pbMessage(b []byte) *pbReader {
+	return &pbReader{b: b}
+}
+
+// next reads the next field, returning false at the end of the message or on error.
+func (m *pbReader) next() bool {
+	if m.err != nil || len(m.b) == 0 {
+		return false
+	}
+
+	key, ok := m.readVarint()
+	if !ok {
+		return false
+	}
+	m.field, m.wire = int(key>>3), int(key&7)
+	m.value, m.num = nil, 0
+
+	switch m.wire {
+	case pbVarint:
+		m.num, ok = m.readVarint()
+	case pbFixed64:
+		ok = m.skip(8)
+	case pbFixed32:
+		ok = m.skip(4)
+	case pbBytes:
+		var n uint64
+		if n, ok = m.readVarint(); ok && n <= uint64(len(m.b)) {
+			m.value, m.b = m.b[:n], m.b[n:]
+		} else {
+			ok = false
+		}
+	default:
+		ok = false
+	}
+
+	if !ok {
+		m.err = ErrInvalidPBF
+	}
+	return ok
+}
+
+// readVarint reads a varint from the remaining message.
+func (m *pbReader) readVarint() (uint64, bool) {
+	v, n := binary.Uvarint(m.b)
+	if n <= 0 {
+		m.err = ErrInvalidPBF
+		return 0, false
+	}
+
+	m.b = m.b[n:]
+	return v, true
+}
+
+// skip discards n bytes of the remaining message.
+func (m *pbReader) skip(n int) bool {
+	if len(m.b) < n {
+		return false
+	}
+
+	m.b = m.b[n:]
+	return true
+}
+
+// varint returns the value of the current varint field.
+func (m *pbReader) varint() uint64 {
+	return m.num
+}
+
+// sint returns the value of the current zigzag encoded varint field.
+func (m *pbReader) sint() int64 {
+	return unzigzag(m.num)
+}
+
+// bytes returns the value of the current length delimited field.
+func (m *pbReader) bytes() []byte {
+	return m.value
+}
+
+// packedVarints returns the values of the current packed varint field, or of a
+// single varint when the field isn't packed.
+func (m *pbReader) packedVarints() []uint64 {
+	if m.wire == pbVarint {
+		return []uint64{m.num}
+	}
+
+	var vals []uint64
+	b := m.value
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			m.err = ErrInvalidPBF
+			return nil
+		}
+
+		vals = append(vals, v)
+		b = b[n:]
+	}
+
+	return vals
+}
+
+// packedSints returns the values of the current packed zigzag encoded varint field.
+func (m *pbReader) packedSints() []int64 {
+	vals := m.packedVarints()
+
+	sints := make([]int64, len(vals))
+	for i, v := range vals {
+		sints[i] = unzigzag(v)
+	}
+	return sints
+}
+
+// unzigzag decodes a zigzag encoded signed integer.
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
@@ -0,0 +1,94 @@
+package geo
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// osmXMLNode is a node element of an OpenStreetMap XML extract.
+type osmXMLNode struct {
+	ID  int64   `xml:"id,attr"`
+	Lat float64 `xml:"lat,attr"`
+	Lng float64 `xml:"lon,attr"`
+}
+
+// osmXMLWay is a way element of an OpenStreetMap XML extract.
+type osmXMLWay struct {
+	Refs []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []struct {
+		Key   string `xml:"k,attr"`
+		Value string `xml:"v,attr"`
+	} `xml:"tag"`
+}
+
+// readOSMXML reads the highways of an OpenStreetMap XML extract and the nodes they
+// pass through, decoding one element at a time so that the whole document isn't held
+// in memory.
+func readOSMXML(r io.ReadSeeker) (*osmExtract, error) {
+	x := newOSMExtract()
+	if err := x.readXML(r, "way"); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := x.readXML(r, "node"); err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// readXML reads the elements of an OpenStreetMap XML extract with the name provided,
+// skipping the others.
+func (x *osmExtract) readXML(r io.Reader, element string) error {
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		} else if start.Name.Local != element {
+			if start.Name.Local == "node" || start.Name.Local == "way" {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		switch element {
+		case "node":
+			var n osmXMLNode
+			if err := d.DecodeElement(&n, &start); err != nil {
+				return err
+			}
+			x.addNode(n.ID, Position{Lat: n.Lat, Lng: n.Lng})
+
+		case "way":
+			var w osmXMLWay
+			if err := d.DecodeElement(&w, &start); err != nil {
+				return err
+			}
+
+			refs := make([]int64, len(w.Refs))
+			for i, nd := range w.Refs {
+				refs[i] = nd.Ref
+			}
+			tags := make(map[string]string, len(w.Tags))
+			for _, t := range w.Tags {
+				tags[t.Key] = t.Value
+			}
+			x.addWay(refs, tags)
+		}
+	}
+}
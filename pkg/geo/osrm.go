@@ -0,0 +1,393 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// OSRMProvider is the name of the OSRM Provider.
+	OSRMProvider = "osrm"
+
+	// OSRMURLSetting is the Provider setting containing the base URL of the OSRM server,
+	// such as "http://localhost:5000".
+	OSRMURLSetting = "url"
+	// OSRMDriveProfileSetting, OSRMWalkProfileSetting and OSRMBikeProfileSetting are the
+	// Provider settings containing the name of the OSRM profile used for each TravelMode.
+	OSRMDriveProfileSetting = "drive-profile"
+	OSRMWalkProfileSetting  = "walk-profile"
+	OSRMBikeProfileSetting  = "bike-profile"
+
+	osrmRouteService = "route/v1"
+	osrmTableService = "table/v1"
+	// osrmMaxTableSize is the most coordinates in a table request accepted by an OSRM
+	// server with the default --max-table-size.
+	osrmMaxTableSize = 100
+	osrmCodeOk       = "Ok"
+	// osrmCodeInvalidValue is the response code returned for invalid parameters, such
+	// as excluding a class of road the profile doesn't define.
+	osrmCodeInvalidValue = "InvalidValue"
+)
+
+var (
+	// ErrOSRMURLMissing is returned when creating an OSRM Provider without the URL of the server.
+	ErrOSRMURLMissing = errors.New("missing OSRM server url provider setting")
+	// ErrOSRMAvoidUnsupported is returned when the OSRM server's profile can't exclude the route features to avoid.
+	ErrOSRMAvoidUnsupported = errors.New("avoid not supported by this OSRM server")
+
+	osrmDefaultProfiles = map[TravelMode]string{
+		Drive: "driving",
+		Walk:  "walking",
+		Bike:  "cycling",
+	}
+	osrmProfileSettings = map[TravelMode]string{
+		Drive: OSRMDriveProfileSetting,
+		Walk:  OSRMWalkProfileSetting,
+		Bike:  OSRMBikeProfileSetting,
+	}
+	osrmExcludes = map[Avoid]string{
+		AvoidTolls:    "toll",
+		AvoidHighways: "motorway",
+		AvoidFerries:  "ferry",
+	}
+	// osrmBadLocationCodes are the response codes returned when a coordinate can't be
+	// matched to the road network.
+	osrmBadLocationCodes = map[string]bool{
+		"NoSegment": true,
+	}
+)
+
+// OSRM estimates durations using the route and table services of an OSRM server.
+//
+// OSRM doesn't support traffic, transit or geocoding, so locations must be
+// provided as "lat,lng" coordinates.
+type OSRM struct {
+	name     string
+	url      string
+	profiles map[TravelMode]string
+
+	// routeService and tableService are the paths of the services, which are
+	// followed by the profile and coordinates, and params are included in every request.
+	routeService string
+	tableService string
+	params       url.Values
+
+	// traffic indicates that the Drive profile accounts for traffic, and
+	// supports departure times.
+	traffic bool
+	// excludeDefault indicates that the Drive profile can exclude each route feature,
+	// so that the default features to avoid are excluded as well as those requested.
+	// Stock OSRM profiles can't exclude them all, so only requested features are.
+	excludeDefault bool
+
+	// maxTable is the most coordinates, sources and destinations together, in a
+	// table request, and maxTrafficTable is the most when driving with traffic.
+	maxTable, maxTrafficTable int
+
+	client *http.Client
+}
+
+// NewOSRM initializes and returns an OSRM with the base URL of the server and the
+// names of the profiles to use for each TravelMode. The default profiles, "driving",
+// "walking" and "cycling", are used for any TravelMode without a profile.
+func NewOSRM(baseURL string, profiles map[TravelMode]string) *OSRM {
+	o := OSRM{
+		name:         OSRMProvider,
+		url:          strings.TrimRight(baseURL, "/"),
+		profiles:     make(map[TravelMode]string),
+		routeService: osrmRouteService,
+		tableService: osrmTableService,
+		maxTable:     osrmMaxTableSize,
+		client:       http.DefaultClient,
+	}
+	for tm, p := range osrmDefaultProfiles {
+		o.profiles[tm] = p
+	}
+	for tm, p := range profiles {
+		o.profiles[tm] = p
+	}
+
+	return &o
+}
+
+// newOSRMProvider creates an OSRM Provider from the url and profile settings.
+func newOSRMProvider(c ProviderConfig) (Provider, error) {
+	baseURL := c.Settings[OSRMURLSetting]
+	if len(baseURL) == 0 {
+		return nil, ErrOSRMURLMissing
+	}
+
+	profiles := make(map[TravelMode]string)
+	for tm, setting := range osrmProfileSettings {
+		if p := c.Settings[setting]; len(p) > 0 {
+			profiles[tm] = p
+		}
+	}
+
+	o := NewOSRM(baseURL, profiles)
+	if c.Client != nil {
+		o.client = c.Client
+	}
+	return o, nil
+}
+
+// Duration returns the estimated time it will take to travel between the From and
+// To coordinates, using the OSRM route service.
+func (o *OSRM) Duration(from, to string, tm TravelMode, opts Options) (*Estimate, error) {
+	u, err := o.serviceURL(o.routeService, []string{from, to}, tm, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Routes []struct {
+			Duration float64 `json:"duration"`
+			Distance float64 `json:"distance"`
+		} `json:"routes"`
+	}
+	if err := o.get(u, &res); err != nil {
+		return nil, err
+	} else if len(res.Routes) == 0 {
+		return nil, ErrUnavailable
+	}
+
+	route := res.Routes[0]
+	return &Estimate{
+		Duration: osrmDuration(route.Duration),
+		Distance: int(math.Floor(route.Distance + 0.5)),
+		Traffic:  o.traffic && tm == Drive,
+		Avoided:  o.avoided(tm, opts),
+	}, nil
+}
+
+// Matrix returns the estimated time it will take to travel between each of the From
+// and To coordinates, using the OSRM table service.
+//
+// The Estimates are indexed by From and then To coordinates, and an Estimate is nil
+// when no route is available between a pair. Requests are split into chunks as
+// necessary to stay within the table size limit of the server.
+func (o *OSRM) Matrix(from, to []string, tm TravelMode, opts Options) ([][]*Estimate, error) {
+	estimates := make([][]*Estimate, len(from))
+	for i := range estimates {
+		estimates[i] = make([]*Estimate, len(to))
+	}
+	if len(from) == 0 || len(to) == 0 {
+		return estimates, nil
+	}
+
+	limit := o.maxTable
+	if o.traffic && tm == Drive {
+		limit = o.maxTrafficTable
+	}
+	fromSize, toSize := osrmTableChunks(len(from), len(to), limit)
+
+	for fromStart := 0; fromStart < len(from); fromStart += fromSize {
+		fromEnd := minInt(fromStart+fromSize, len(from))
+
+		for toStart := 0; toStart < len(to); toStart += toSize {
+			toEnd := minInt(toStart+toSize, len(to))
+
+			chunk, err := o.table(from[fromStart:fromEnd], to[toStart:toEnd], tm, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, row := range chunk {
+				copy(estimates[fromStart+i][toStart:toEnd], row)
+			}
+		}
+	}
+
+	return estimates, nil
+}
+
+// osrmTableChunks returns the number of sources and destinations to include in each
+// table request, staying within the limit of coordinates in a request, if any.
+func osrmTableChunks(from, to, limit int) (fromSize, toSize int) {
+	if limit <= 0 || from+to <= limit {
+		return from, to
+	}
+
+	// Sources get half of the limit, or more when there are fewer destinations.
+	fromSize = minInt(from, limit/2)
+	if to < limit-fromSize {
+		fromSize = minInt(from, limit-to)
+	}
+	return fromSize, minInt(to, limit-fromSize)
+}
+
+// table returns the Estimates between each of the From and To coordinates, with a
+// single request to the OSRM table service.
+func (o *OSRM) table(from, to []string, tm TravelMode, opts Options) ([][]*Estimate, error) {
+	estimates := make([][]*Estimate, len(from))
+	for i := range estimates {
+		estimates[i] = make([]*Estimate, len(to))
+	}
+
+	u, err := o.serviceURL(o.tableService, append(append([]string{}, from...), to...), tm, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]string, len(from))
+	for i := range from {
+		sources[i] = strconv.Itoa(i)
+	}
+	destinations := make([]string, len(to))
+	for i := range to {
+		destinations[i] = strconv.Itoa(len(from) + i)
+	}
+	q := u.Query()
+	q.Set("sources", strings.Join(sources, ";"))
+	q.Set("destinations", strings.Join(destinations, ";"))
+	q.Set("annotations", "duration,distance")
+	u.RawQuery = q.Encode()
+
+	var res struct {
+		Durations [][]*float64 `json:"durations"`
+		Distances [][]*float64 `json:"distances"`
+	}
+	if err := o.get(u, &res); err != nil {
+		return nil, err
+	}
+
+	for i, row := range res.Durations {
+		for j, d := range row {
+			if i >= len(from) || j >= len(to) || d == nil {
+				continue
+			}
+
+			e := Estimate{Duration: osrmDuration(*d), Traffic: o.traffic && tm == Drive, Avoided: o.avoided(tm, opts)}
+			if i < len(res.Distances) && j < len(res.Distances[i]) && res.Distances[i][j] != nil {
+				e.Distance = int(math.Floor(*res.Distances[i][j] + 0.5))
+			}
+			estimates[i][j] = &e
+		}
+	}
+
+	return estimates, nil
+}
+
+// CurrentLocation is not supported by OSRM.
+func (o *OSRM) CurrentLocation() (*Position, error) {
+	return nil, ErrUnsupported
+}
+
+// Capabilities returns the Capabilities of OSRM, which only supports estimating
+// durations between coordinates, one pair at a time or as a matrix.
+func (o *OSRM) Capabilities() Capabilities {
+	return Capabilities{Traffic: o.traffic, Matrix: true}
+}
+
+// serviceURL returns the URL of an OSRM service request between the coordinates
+// provided, using the profile of the TravelMode and excluding any features to avoid.
+//
+// When the Drive profile accounts for traffic, the departure time is included.
+func (o *OSRM) serviceURL(service string, coordinates []string, tm TravelMode, opts Options) (*url.URL, error) {
+	profile, ok := o.profiles[tm]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	lngLats := make([]string, len(coordinates))
+	for i, c := range coordinates {
+		p, err := parsePosition(c)
+		if err != nil {
+			return nil, err
+		}
+		lngLats[i] = strconv.FormatFloat(p.Lng, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lat, 'f', -1, 64)
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%v/%v/%v/%v", o.url, service, profile, strings.Join(lngLats, ";")))
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	for k, v := range o.params {
+		q[k] = v
+	}
+	if service == o.routeService {
+		q.Set("overview", "false")
+	}
+	if o.traffic && tm == Drive && !opts.DepartAt.IsZero() {
+		q.Set("depart_at", opts.DepartAt.UTC().Format("2006-01-02T15:04Z"))
+	}
+	var exclude []string
+	for _, a := range o.avoided(tm, opts) {
+		exclude = append(exclude, osrmExcludes[a])
+	}
+	if len(exclude) > 0 {
+		q.Set("exclude", strings.Join(exclude, ","))
+	}
+	u.RawQuery = q.Encode()
+
+	return u, nil
+}
+
+// avoided returns the route features excluded from a route, which are those to avoid
+// unless they're the default and the profile may not be able to exclude them.
+func (o *OSRM) avoided(tm TravelMode, opts Options) []Avoid {
+	if opts.DefaultAvoid && !o.excludeDefault {
+		return nil
+	}
+
+	return avoided(tm, opts)
+}
+
+// get performs a request to an OSRM service and decodes the response into v,
+// returning an error when the response code isn't "Ok".
+//
+// The query is removed from the URL of request errors, so that access tokens
+// aren't exposed.
+func (o *OSRM) get(u *url.URL, v interface{}) error {
+	resp, err := o.client.Get(u.String())
+	if err != nil {
+		if uerr, ok := err.(*url.Error); ok {
+			redacted := *u
+			redacted.RawQuery = ""
+			uerr.URL = redacted.String()
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var status struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	decoder := json.NewDecoder(resp.Body)
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	} else if err := json.Unmarshal(raw, &status); err != nil {
+		return err
+	}
+
+	switch {
+	case status.Code == osrmCodeOk:
+		return json.Unmarshal(raw, v)
+	case osrmBadLocationCodes[status.Code]:
+		return ErrBadLocation
+	case status.Code == osrmCodeInvalidValue && len(u.Query().Get("exclude")) > 0:
+		return ErrOSRMAvoidUnsupported
+	case len(status.Code) == 0 && len(status.Message) == 0:
+		return ErrUnavailable
+	case len(status.Code) == 0:
+		return fmt.Errorf("%v: %v", o.name, status.Message)
+	}
+
+	return fmt.Errorf("%v: %v: %v", o.name, status.Code, status.Message)
+}
+
+// osrmDuration converts a duration in seconds, as returned by OSRM, to a Duration.
+func osrmDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,204 @@
+package geo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// osrmServer returns a stand-in OSRM server that expects requests to the path and
+// query provided, and responds with the status code and body.
+func osrmServer(t *testing.T, path, query string, code int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("Unexpected path, expected=%v, got=%v", path, r.URL.Path)
+		} else if r.URL.RawQuery != query {
+			t.Errorf("Unexpected query, expected=%v, got=%v", query, r.URL.RawQuery)
+		}
+
+		w.WriteHeader(code)
+		fmt.Fprint(w, body)
+	}))
+}
+
+func TestNewOSRM(t *testing.T) {
+	o := NewOSRM("http://localhost:5000/", map[TravelMode]string{Bike: "bike"})
+
+	if o.url != "http://localhost:5000" {
+		t.Fatalf("Unexpected url, expected=%v, got=%v", "http://localhost:5000", o.url)
+	} else if o.profiles[Drive] != "driving" || o.profiles[Walk] != "walking" || o.profiles[Bike] != "bike" {
+		t.Fatalf("Unexpected profiles, got=%v", o.profiles)
+	}
+
+	if _, err := NewProvider(OSRMProvider, ProviderConfig{}); err != ErrOSRMURLMissing {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrOSRMURLMissing, err)
+	}
+
+	p, err := NewProvider(OSRMProvider, ProviderConfig{Settings: map[string]string{
+		OSRMURLSetting:          "http://osrm",
+		OSRMDriveProfileSetting: "car",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	} else if o, ok := p.(*OSRM); !ok {
+		t.Fatalf("Unexpected Provider, expected=*OSRM, got=%T", p)
+	} else if o.url != "http://osrm" || o.profiles[Drive] != "car" || o.profiles[Walk] != "walking" {
+		t.Fatalf("Unexpected OSRM, got=%+v", o)
+	}
+}
+
+func TestOSRM_Duration(t *testing.T) {
+	tests := []struct {
+		tm      TravelMode
+		opts    Options
+		path    string
+		query   string
+		avoided []Avoid
+	}{
+		{Drive, Options{}, "/route/v1/driving/-79.38,43.64;-79.4,43.66", "overview=false", nil},
+		{Walk, Options{}, "/route/v1/walking/-79.38,43.64;-79.4,43.66", "overview=false", nil},
+		{Bike, Options{Avoid: []Avoid{AvoidTolls}}, "/route/v1/cycling/-79.38,43.64;-79.4,43.66", "overview=false", nil},
+		{Drive, Options{Avoid: []Avoid{AvoidTolls, AvoidFerries}}, "/route/v1/driving/-79.38,43.64;-79.4,43.66", "exclude=toll%2Cferry&overview=false", []Avoid{AvoidTolls, AvoidFerries}},
+		// Stock profiles may not be able to exclude the default features to avoid.
+		{Drive, Options{Avoid: []Avoid{AvoidTolls}, DefaultAvoid: true}, "/route/v1/driving/-79.38,43.64;-79.4,43.66", "overview=false", nil},
+	}
+
+	for idx, tt := range tests {
+		s := osrmServer(t, tt.path, tt.query, http.StatusOK, `{"code":"Ok","routes":[{"duration":1260.4,"distance":5432.6}],"waypoints":[]}`)
+
+		e, err := NewOSRM(s.URL, nil).Duration("43.64,-79.38", "43.66, -79.4", tt.tm, tt.opts)
+		s.Close()
+		if err != nil {
+			t.Fatalf("[#%v] %v", idx, err)
+		}
+
+		if e.Duration != time.Duration(1260.4*float64(time.Second)) {
+			t.Fatalf("[#%v] Unexpected Duration, got=%v", idx, e.Duration)
+		} else if e.Distance != 5433 {
+			t.Fatalf("[#%v] Unexpected Distance, expected=%v, got=%v", idx, 5433, e.Distance)
+		} else if e.Traffic {
+			t.Fatalf("[#%v] Unexpected Traffic", idx)
+		} else if !reflect.DeepEqual(e.Avoided, tt.avoided) {
+			t.Fatalf("[#%v] Unexpected Avoided, expected=%v, got=%v", idx, tt.avoided, e.Avoided)
+		}
+	}
+}
+
+func TestOSRM_Duration_errors(t *testing.T) {
+	tests := []struct {
+		code   int
+		body   string
+		expect error
+	}{
+		{http.StatusOK, `{"code":"Ok","routes":[]}`, ErrUnavailable},
+		{http.StatusBadRequest, `{"code":"NoSegment","message":"Could not find a matching segment for coordinate 0"}`, ErrBadLocation},
+		{http.StatusBadRequest, `{"code":"NoRoute","message":"Impossible route between points"}`, fmt.Errorf("osrm: NoRoute: Impossible route between points")},
+		{http.StatusBadGateway, `{}`, ErrUnavailable},
+	}
+
+	for idx, tt := range tests {
+		s := osrmServer(t, "/route/v1/driving/-79.38,43.64;-79.4,43.66", "overview=false", tt.code, tt.body)
+
+		_, err := NewOSRM(s.URL, nil).Duration("43.64,-79.38", "43.66,-79.4", Drive, Options{})
+		s.Close()
+		if err == nil || err.Error() != tt.expect.Error() {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.expect, err)
+		}
+	}
+
+	// Invalid values are only reported as unsupported features to avoid when excluding
+	// features, as the profile may not define them.
+	invalid := `{"code":"InvalidValue","message":"Exclude flag combination is not supported."}`
+	s := osrmServer(t, "/route/v1/driving/-79.38,43.64;-79.4,43.66", "exclude=toll&overview=false", http.StatusBadRequest, invalid)
+	_, err := NewOSRM(s.URL, nil).Duration("43.64,-79.38", "43.66,-79.4", Drive, Options{Avoid: []Avoid{AvoidTolls}})
+	s.Close()
+	if err != ErrOSRMAvoidUnsupported {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrOSRMAvoidUnsupported, err)
+	}
+
+	s = osrmServer(t, "/route/v1/driving/-79.38,43.64;-79.4,43.66", "overview=false", http.StatusBadRequest, invalid)
+	_, err = NewOSRM(s.URL, nil).Duration("43.64,-79.38", "43.66,-79.4", Drive, Options{})
+	s.Close()
+	if expect := "osrm: InvalidValue: Exclude flag combination is not supported."; err == nil || err.Error() != expect {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", expect, err)
+	}
+
+	// Requests that can't be made to OSRM are rejected without a request.
+	o := NewOSRM("http://invalid.localhost", nil)
+	if _, err := o.Duration("123 Main St", "43.66,-79.4", Drive, Options{}); err != ErrBadLocation {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrBadLocation, err)
+	} else if _, err := o.Duration("43.64,-79.38", "43.66,-79.4", Transit, Options{}); err != ErrUnsupported {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrUnsupported, err)
+	}
+}
+
+func TestOSRM_Matrix(t *testing.T) {
+	s := osrmServer(t, "/table/v1/driving/-79.38,43.64;-79.39,43.65;-79.4,43.66;-79.41,43.67;-79.42,43.68",
+		"annotations=duration%2Cdistance&destinations=2%3B3%3B4&sources=0%3B1", http.StatusOK,
+		`{"code":"Ok","durations":[[600,null,900],[300,1200,60]],"distances":[[5000,null,7000.4],[2000,9000,null]]}`)
+	defer s.Close()
+
+	from := []string{"43.64,-79.38", "43.65,-79.39"}
+	to := []string{"43.66,-79.4", "43.67,-79.41", "43.68,-79.42"}
+
+	res, err := NewOSRM(s.URL, nil).Matrix(from, to, Drive, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := [][]*Estimate{
+		{{Duration: 10 * time.Minute, Distance: 5000}, nil, {Duration: 15 * time.Minute, Distance: 7000}},
+		{{Duration: 5 * time.Minute, Distance: 2000}, {Duration: 20 * time.Minute, Distance: 9000}, {Duration: time.Minute}},
+	}
+	for i := range expect {
+		for j := range expect[i] {
+			e, got := expect[i][j], res[i][j]
+			if (e == nil) != (got == nil) {
+				t.Fatalf("[%v][%v] Unexpected Estimate, expected=%v, got=%v", i, j, e, got)
+			} else if e != nil && (e.Duration != got.Duration || e.Distance != got.Distance) {
+				t.Fatalf("[%v][%v] Unexpected Estimate, expected=%+v, got=%+v", i, j, *e, *got)
+			}
+		}
+	}
+
+	// Empty matrices don't make a request.
+	if res, err := NewOSRM("http://invalid.localhost", nil).Matrix(from, nil, Drive, Options{}); err != nil {
+		t.Fatal(err)
+	} else if len(res) != len(from) {
+		t.Fatalf("Unexpected number of rows, expected=%v, got=%v", len(from), len(res))
+	}
+}
+
+func TestOSRM_Capabilities(t *testing.T) {
+	o := NewOSRM("http://localhost:5000", nil)
+	if c := o.Capabilities(); c != (Capabilities{Matrix: true}) {
+		t.Fatalf("Unexpected Capabilities, expected=%+v, got=%+v", Capabilities{Matrix: true}, c)
+	} else if _, err := o.CurrentLocation(); err != ErrUnsupported {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrUnsupported, err)
+	}
+}
+
+func TestOSRMTableChunks(t *testing.T) {
+	tests := []struct {
+		from, to, limit  int
+		fromSize, toSize int
+	}{
+		{2, 3, 10, 2, 3},
+		{5, 5, 10, 5, 5},
+		{30, 30, 10, 5, 5},
+		{3, 30, 10, 3, 7},
+		{30, 3, 10, 7, 3},
+		{30, 30, 25, 12, 13},
+		{150, 150, 0, 150, 150},
+	}
+
+	for idx, tt := range tests {
+		fromSize, toSize := osrmTableChunks(tt.from, tt.to, tt.limit)
+		if fromSize != tt.fromSize || toSize != tt.toSize {
+			t.Fatalf("[#%v] Unexpected chunks, expected=%vx%v, got=%vx%v", idx, tt.fromSize, tt.toSize, fromSize, toSize)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package geo
+
+import (
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+// Search returns the places matching a free text query, such as a business name
+// or partial address, most relevant first.
+//
+// ErrBadLocation is returned if no places match the query.
+func (r Router) Search(query string) ([]Place, error) {
+	res, err := r.client.TextSearch(context.Background(), &maps.TextSearchRequest{Query: query})
+	if err != nil {
+		return nil, searchErr(err)
+	} else if len(res.Results) == 0 {
+		return nil, ErrBadLocation
+	}
+
+	return newPlaces(res.Results), nil
+}
+
+// Nearby returns the places matching a keyword, such as "pharmacy" or "gas station",
+// nearest to a Latitude and Longitude first.
+//
+// ErrBadLocation is returned if no places match the keyword.
+func (r Router) Nearby(lat, lng float64, keyword string) ([]Place, error) {
+	res, err := r.client.NearbySearch(context.Background(), &maps.NearbySearchRequest{
+		Location: &maps.LatLng{Lat: lat, Lng: lng},
+		Keyword:  keyword,
+		RankBy:   maps.RankByDistance,
+	})
+	if err != nil {
+		return nil, searchErr(err)
+	} else if len(res.Results) == 0 {
+		return nil, ErrBadLocation
+	}
+
+	return newPlaces(res.Results), nil
+}
+
+// newPlaces converts Places API search results into Places.
+func newPlaces(res []maps.PlacesSearchResult) []Place {
+	places := make([]Place, len(res))
+	for i, p := range res {
+		address := p.FormattedAddress
+		if len(address) == 0 {
+			address = p.Vicinity
+		}
+
+		places[i] = Place{
+			Name:    p.Name,
+			Address: address,
+			Lat:     p.Geometry.Location.Lat,
+			Lng:     p.Geometry.Location.Lng,
+		}
+	}
+
+	return places
+}
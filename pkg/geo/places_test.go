@@ -0,0 +1,148 @@
+package geo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+func TestRouter_Search(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+
+	// Positive Case
+	{
+		mc.searchFn = func(c context.Context, r *maps.TextSearchRequest) (maps.PlacesSearchResponse, error) {
+			if r.Query != "cn tower" {
+				t.Fatalf("Unexpected Query, expected=%v, got=%v", "cn tower", r.Query)
+			}
+
+			return maps.PlacesSearchResponse{Results: []maps.PlacesSearchResult{
+				{Name: "CN Tower", FormattedAddress: "290 Bremner Blvd, Toronto, ON", Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 43.64, Lng: -79.38}}},
+				{Name: "CN Tower Parking", Vicinity: "Bremner Blvd, Toronto", Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 43.64, Lng: -79.39}}},
+			}}, nil
+		}
+
+		places, err := r.Search("cn tower")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := []Place{
+			{Name: "CN Tower", Address: "290 Bremner Blvd, Toronto, ON", Lat: 43.64, Lng: -79.38},
+			{Name: "CN Tower Parking", Address: "Bremner Blvd, Toronto", Lat: 43.64, Lng: -79.39},
+		}
+		if !reflect.DeepEqual(places, expect) {
+			t.Fatalf("Unexpected Places, expected=%v, got=%v", expect, places)
+		}
+	}
+
+	// No results
+	{
+		tests := []struct {
+			res maps.PlacesSearchResponse
+			err error
+		}{
+			{maps.PlacesSearchResponse{}, nil},
+			{maps.PlacesSearchResponse{}, errors.New("maps: ZERO_RESULTS - ")},
+		}
+
+		for idx, tt := range tests {
+			mc.searchFn = func(c context.Context, r *maps.TextSearchRequest) (maps.PlacesSearchResponse, error) {
+				return tt.res, tt.err
+			}
+
+			if _, err := r.Search("nowhere"); err != ErrBadLocation {
+				t.Fatalf("[#%v] Unexpected error returned, expected=%v, got=%v", idx, ErrBadLocation, err)
+			}
+		}
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.searchFn = func(c context.Context, r *maps.TextSearchRequest) (maps.PlacesSearchResponse, error) {
+			return maps.PlacesSearchResponse{}, e
+		}
+
+		if _, err := r.Search("cn tower"); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+}
+
+func TestRouter_Nearby(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+
+	// Positive Case
+	{
+		mc.nearbyFn = func(c context.Context, r *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
+			expect := maps.NearbySearchRequest{Location: &maps.LatLng{Lat: 43.6, Lng: -79.3}, Keyword: "pharmacy", RankBy: maps.RankByDistance}
+			if !reflect.DeepEqual(*r, expect) {
+				t.Fatalf("Unexpected NearbySearchRequest, expected=%+v, got=%+v", expect, *r)
+			}
+
+			return maps.PlacesSearchResponse{Results: []maps.PlacesSearchResult{
+				{Name: "Pharmacy", Vicinity: "1 King St, Toronto", Geometry: maps.AddressGeometry{Location: maps.LatLng{Lat: 43.61, Lng: -79.31}}},
+			}}, nil
+		}
+
+		places, err := r.Nearby(43.6, -79.3, "pharmacy")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expect := []Place{{Name: "Pharmacy", Address: "1 King St, Toronto", Lat: 43.61, Lng: -79.31}}
+		if !reflect.DeepEqual(places, expect) {
+			t.Fatalf("Unexpected Places, expected=%v, got=%v", expect, places)
+		}
+	}
+
+	// No results
+	{
+		mc.nearbyFn = func(c context.Context, r *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
+			return maps.PlacesSearchResponse{}, errors.New("maps: ZERO_RESULTS - ")
+		}
+
+		if _, err := r.Nearby(0, 0, "pharmacy"); err != ErrBadLocation {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", ErrBadLocation, err)
+		}
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.nearbyFn = func(c context.Context, r *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error) {
+			return maps.PlacesSearchResponse{}, e
+		}
+
+		if _, err := r.Nearby(0, 0, "pharmacy"); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+}
+
+func TestPlace_String(t *testing.T) {
+	tests := []struct {
+		p      Place
+		expect string
+	}{
+		{Place{Address: "123 Main St"}, "123 Main St"},
+		{Place{Name: "CN Tower", Address: "290 Bremner Blvd"}, "CN Tower, 290 Bremner Blvd"},
+		{Place{Name: "123 Main St", Address: "123 Main St, Toronto"}, "123 Main St, Toronto"},
+	}
+
+	for idx, tt := range tests {
+		if s := tt.p.String(); s != tt.expect {
+			t.Fatalf("[#%v] Unexpected String, expected=%v, got=%v", idx, tt.expect, s)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	earthRadius = 6371000
+
+	// gridMaxLat is the furthest latitude from the equator grid cells are widened
+	// to reach, as degrees of longitude vanish at the poles.
+	gridMaxLat = 89
+)
+
+// Position is a Latitude and Longitude, such as the current location of the device.
+type Position struct {
+	Lat float64
+	Lng float64
+
+	// Accuracy is the radius of uncertainty of the Position, in meters.
+	Accuracy float64
+}
+
+// String returns the "lat,lng" representation of a Position, as accepted
+// in place of an address.
+func (p Position) String() string {
+	return fmt.Sprintf("%v,%v", p.Lat, p.Lng)
+}
+
+// parsePosition parses a "lat,lng" string into a Position.
+func parsePosition(s string) (Position, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Position{}, ErrBadLocation
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return Position{}, ErrBadLocation
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return Position{}, ErrBadLocation
+	}
+
+	return Position{Lat: lat, Lng: lng}, nil
+}
+
+// Haversine returns the great-circle distance between two coordinates, in meters.
+func Haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(deg float64) float64 {
+		return deg * math.Pi / 180
+	}
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// grid buckets indexes by the coordinates they're at, into cells at least a distance
+// across, so that the indexes within that distance of a point are in its cell or one
+// of the eight around it.
+type grid struct {
+	// lat and lng are the size of the cells, in degrees.
+	lat, lng float64
+	cells    map[gridCell][]int32
+}
+
+// gridCell is the row and column of a grid cell.
+type gridCell struct{ lat, lng int }
+
+// newGrid initializes and returns an empty grid with cells at least the distance
+// provided across, in meters, up to the furthest latitude from the equator provided.
+//
+// Degrees of longitude shrink away from the equator, so cells are made wider the
+// further they need to reach.
+func newGrid(meters, maxLat float64) grid {
+	lat := meters / (earthRadius * math.Pi / 180)
+
+	// Points within the distance can be a cell closer to the pole than maxLat.
+	reach := math.Min(math.Abs(maxLat)+lat, gridMaxLat)
+	return grid{
+		lat:   lat,
+		lng:   lat / math.Cos(reach*math.Pi/180),
+		cells: make(map[gridCell][]int32),
+	}
+}
+
+// cell returns the cell containing the coordinates.
+func (g grid) cell(lat, lng float64) gridCell {
+	return gridCell{int(math.Floor(lat / g.lat)), int(math.Floor(lng / g.lng))}
+}
+
+// add adds the index at the coordinates to the grid.
+func (g grid) add(lat, lng float64, i int32) {
+	c := g.cell(lat, lng)
+	g.cells[c] = append(g.cells[c], i)
+}
+
+// near calls fn with each index in the cell of the coordinates and the eight around it.
+func (g grid) near(lat, lng float64, fn func(i int32)) {
+	c := g.cell(lat, lng)
+	for lat := c.lat - 1; lat <= c.lat+1; lat++ {
+		for lng := c.lng - 1; lng <= c.lng+1; lng++ {
+			for _, i := range g.cells[gridCell{lat, lng}] {
+				fn(i)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPosition_String(t *testing.T) {
+	tests := []struct {
+		p      Position
+		expect string
+	}{
+		{Position{Lat: 43.6532, Lng: -79.3832}, "43.6532,-79.3832"},
+		{Position{Lat: 0, Lng: 0, Accuracy: 50}, "0,0"},
+	}
+
+	for idx, tt := range tests {
+		if s := tt.p.String(); s != tt.expect {
+			t.Fatalf("[#%v] Unexpected String, expected=%v, got=%v", idx, tt.expect, s)
+		}
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		lat1, lng1, lat2, lng2 float64
+		expect                 float64
+	}{
+		{43.6532, -79.3832, 43.6532, -79.3832, 0},
+		// Toronto to Montreal
+		{43.6532, -79.3832, 45.5017, -73.5673, 504000},
+		// One degree of latitude
+		{0, 0, 1, 0, 111195},
+	}
+
+	for idx, tt := range tests {
+		d := Haversine(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+		if math.Abs(d-tt.expect) > tt.expect*0.005+1 {
+			t.Fatalf("[#%v] Unexpected distance, expected=%v, got=%v", idx, tt.expect, d)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		meters, maxLat         float64
+		lat1, lng1, lat2, lng2 float64
+		expect                 bool
+	}{
+		{1000, 0, 0, 0, 0, 0, true},
+		{1000, 0, 0, 0, 0, 0.0089, true},
+		{1000, 0, -0.0001, 0.0001, 0.006, -0.006, true},
+		// Cells are wider away from the equator, where degrees of longitude are shorter.
+		{300, 70, 70, 10, 70, 10.0078, true},
+		{300, 70, 70.0015, -10.0039, 70, -10.009, true},
+		{300, -60, -60, 150, -60.0015, 150.003, true},
+		{300, 0, 0, 0, 1, 1, false},
+	}
+
+	for idx, tt := range tests {
+		if d := Haversine(tt.lat1, tt.lng1, tt.lat2, tt.lng2); tt.expect && d > tt.meters {
+			t.Fatalf("[#%v] Unexpected distance, expected<=%v, got=%v", idx, tt.meters, d)
+		}
+
+		g := newGrid(tt.meters, tt.maxLat)
+		g.add(tt.lat2, tt.lng2, 7)
+
+		var found bool
+		g.near(tt.lat1, tt.lng1, func(i int32) {
+			found = found || i == 7
+		})
+		if found != tt.expect {
+			t.Fatalf("[#%v] Unexpected found, expected=%v, got=%v", idx, tt.expect, found)
+		}
+	}
+}
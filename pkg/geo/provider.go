@@ -0,0 +1,205 @@
+package geo
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+const (
+	// GoogleProvider is the name of the Google Maps Provider, which is used when
+	// no Provider is selected.
+	GoogleProvider = "google"
+)
+
+var (
+	// ErrUnknownProvider is returned when creating a Provider that hasn't been registered.
+	ErrUnknownProvider = errors.New("unknown routing provider")
+	// ErrUnsupported is returned by a Provider asked to do something it has no capability for.
+	ErrUnsupported = errors.New("not supported by the routing provider")
+
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFunc{
+		GoogleProvider: func(c ProviderConfig) (Provider, error) {
+			r, err := NewRouterWithClient(c.APIKey, c.Client)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		},
+		OSRMProvider:    newOSRMProvider,
+		MapboxProvider:  newMapboxProvider,
+		OfflineProvider: newOfflineProvider,
+		OSMProvider:     newOSMProvider,
+	}
+)
+
+// Capabilities describes what a Provider supports beyond estimating the duration
+// of driving, walking and biking commutes between coordinates.
+type Capabilities struct {
+	// Traffic indicates that driving durations account for traffic, and
+	// TrafficRange that optimistic and pessimistic durations are supported.
+	Traffic      bool
+	TrafficRange bool
+	// Transit indicates that the Transit TravelMode is supported.
+	Transit bool
+	// Geocoding indicates that addresses are supported in place of "lat,lng" coordinates.
+	Geocoding bool
+	// Location indicates that the current location can be determined.
+	Location bool
+	// Directions indicates that step by step directions, including stops along the
+	// way, alternative routes and elevation profiles, are supported.
+	Directions bool
+	// Matrix indicates that the durations between many origins and destinations can
+	// be estimated at once.
+	Matrix bool
+	// Places indicates that places near a location can be searched for.
+	Places bool
+}
+
+// Provider is a routing backend that can estimate the duration between two
+// locations, and describe its Capabilities.
+type Provider interface {
+	Duration(from, to string, tm TravelMode, o Options) (*Estimate, error)
+	CurrentLocation() (*Position, error)
+	Capabilities() Capabilities
+}
+
+// ProviderConfig is the configuration a Provider is created with.
+type ProviderConfig struct {
+	// APIKey is the Google Maps API key.
+	APIKey string
+	// Settings are the Provider specific settings, such as the URL of a server.
+	Settings map[string]string
+	// Client is the http.Client used to make requests, or nil for the default.
+	Client *http.Client
+}
+
+// ProviderFunc creates a Provider from a ProviderConfig.
+type ProviderFunc func(ProviderConfig) (Provider, error)
+
+// RegisterProvider makes a Provider available by name, replacing any Provider
+// previously registered with the same name.
+func RegisterProvider(name string, fn ProviderFunc) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	providers[name] = fn
+}
+
+// NewProvider creates the Provider registered with the name provided, or the
+// Google Maps Provider when the name is empty.
+func NewProvider(name string, c ProviderConfig) (Provider, error) {
+	if len(name) == 0 {
+		name = GoogleProvider
+	}
+
+	providersMu.RLock()
+	fn, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	return fn(c)
+}
+
+// WithTransit returns a Provider that estimates transit durations with the transit
+// Provider, such as a GTFS, and everything else with the Provider p.
+//
+// Locations are provided to both as coordinates, so the returned Provider doesn't
+// support geocoding.
+func WithTransit(p, transit Provider) Provider {
+	return transitProvider{Provider: p, transit: transit}
+}
+
+// transitProvider is a Provider that estimates transit durations with another Provider.
+type transitProvider struct {
+	Provider
+	transit Provider
+}
+
+// matrixProvider is a Provider that can estimate the durations between many origins
+// and destinations at once.
+type matrixProvider interface {
+	Matrix(from, to []string, tm TravelMode, o Options) ([][]*Estimate, error)
+}
+
+// Duration returns the estimated time it will take to travel between the From and To
+// locations, using the transit Provider for the Transit TravelMode.
+func (t transitProvider) Duration(from, to string, tm TravelMode, o Options) (*Estimate, error) {
+	if tm == Transit {
+		return t.transit.Duration(from, to, tm, o)
+	}
+
+	return t.Provider.Duration(from, to, tm, o)
+}
+
+// Matrix returns the estimated time it will take to travel between each of the From
+// and To locations, using the Matrix of the Provider, or for the Transit TravelMode,
+// the transit Provider for each pair in turn.
+//
+// The Estimates are indexed by From and then To location, and an Estimate is nil when
+// no transit route is available between a pair.
+func (t transitProvider) Matrix(from, to []string, tm TravelMode, o Options) ([][]*Estimate, error) {
+	if tm != Transit {
+		m, ok := t.Provider.(matrixProvider)
+		if !ok {
+			return nil, ErrUnsupported
+		}
+		return m.Matrix(from, to, tm, o)
+	}
+
+	estimates := make([][]*Estimate, len(from))
+	for i, f := range from {
+		estimates[i] = make([]*Estimate, len(to))
+		for j, d := range to {
+			e, err := t.transit.Duration(f, d, tm, o)
+			if err == ErrNoTransitRoute {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			estimates[i][j] = e
+		}
+	}
+
+	return estimates, nil
+}
+
+// Capabilities returns the Capabilities of the Provider, with transit.
+func (t transitProvider) Capabilities() Capabilities {
+	c := t.Provider.Capabilities()
+	c.Transit = true
+	c.Geocoding = false
+	return c
+}
+
+// Providers returns the sorted names of the registered Providers.
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Capabilities returns the Capabilities of the Google Maps Router, which supports everything.
+func (r Router) Capabilities() Capabilities {
+	return Capabilities{
+		Traffic:      true,
+		TrafficRange: true,
+		Transit:      true,
+		Geocoding:    true,
+		Location:     true,
+		Directions:   true,
+		Matrix:       true,
+		Places:       true,
+	}
+}
@@ -0,0 +1,112 @@
+package geo
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type mockProvider struct {
+	Router
+}
+
+func TestNewProvider(t *testing.T) {
+	// Google Maps is used by default.
+	for _, name := range []string{"", GoogleProvider} {
+		p, err := NewProvider(name, ProviderConfig{APIKey: "key"})
+		if err != nil {
+			t.Fatal(err)
+		} else if _, ok := p.(*Router); !ok {
+			t.Fatalf("Unexpected Provider for name '%v', expected=*Router, got=%T", name, p)
+		}
+	}
+
+	if _, err := NewProvider(GoogleProvider, ProviderConfig{}); err == nil {
+		t.Fatal("Expected error for empty API key")
+	}
+
+	if _, err := NewProvider("unknown", ProviderConfig{APIKey: "key"}); err != ErrUnknownProvider {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrUnknownProvider, err)
+	}
+}
+
+func TestRegisterProvider(t *testing.T) {
+	defer func() {
+		providersMu.Lock()
+		delete(providers, "mock")
+		providersMu.Unlock()
+	}()
+
+	var config ProviderConfig
+	RegisterProvider("mock", func(c ProviderConfig) (Provider, error) {
+		config = c
+		return &mockProvider{}, nil
+	})
+
+	expectConfig := ProviderConfig{Settings: map[string]string{"url": "http://localhost:5000"}}
+	p, err := NewProvider("mock", expectConfig)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, ok := p.(*mockProvider); !ok {
+		t.Fatalf("Unexpected Provider, expected=*mockProvider, got=%T", p)
+	} else if !reflect.DeepEqual(config, expectConfig) {
+		t.Fatalf("Unexpected ProviderConfig, expected=%v, got=%v", expectConfig, config)
+	}
+
+	names := Providers()
+	if !sort.StringsAreSorted(names) {
+		t.Fatalf("Expected Providers to be sorted, got=%v", names)
+	}
+	var found bool
+	for _, name := range names {
+		found = found || name == "mock"
+	}
+	if !found {
+		t.Fatalf("Expected mock in Providers, got=%v", names)
+	}
+}
+
+func TestRouter_Capabilities(t *testing.T) {
+	expect := Capabilities{Traffic: true, TrafficRange: true, Transit: true, Geocoding: true, Location: true, Directions: true, Matrix: true, Places: true}
+	if c := (Router{}).Capabilities(); c != expect {
+		t.Fatalf("Unexpected Capabilities, expected=%+v, got=%+v", expect, c)
+	}
+}
+
+func TestWithTransit(t *testing.T) {
+	p := WithTransit(NewOffline(0, nil), NewOffline(2, nil))
+
+	expect := Capabilities{Transit: true}
+	if c := p.Capabilities(); c != expect {
+		t.Fatalf("Unexpected Capabilities, expected=%+v, got=%+v", expect, c)
+	}
+
+	tests := []struct {
+		tm       TravelMode
+		distance int
+	}{
+		{Drive, 1446},
+		{Transit, 2224},
+	}
+
+	for idx, tt := range tests {
+		e, err := p.Duration("43.65,-79.38", "43.66,-79.38", tt.tm, Options{})
+		if err != nil {
+			t.Fatal(err)
+		} else if e.Distance != tt.distance {
+			t.Fatalf("[#%v] Unexpected distance, expected=%v, got=%v", idx, tt.distance, e.Distance)
+		}
+	}
+
+	// Transit matrices are estimated a pair at a time, and others by the Provider.
+	m := p.(matrixProvider)
+	res, err := m.Matrix([]string{"43.65,-79.38"}, []string{"43.66,-79.38", "43.67,-79.38"}, Transit, Options{})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(res) != 1 || len(res[0]) != 2 || res[0][0].Distance != 2224 {
+		t.Fatalf("Unexpected Matrix, got=%+v", res)
+	}
+	if _, err := m.Matrix([]string{"43.65,-79.38"}, []string{"43.66,-79.38"}, Drive, Options{}); err != ErrUnsupported {
+		t.Fatalf("Unexpected error, expected=%v, got=%v", ErrUnsupported, err)
+	}
+}
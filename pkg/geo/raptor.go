@@ -0,0 +1,510 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// GTFSMaxWalkDistance is the furthest a location can be from a stop to walk to it,
+	// in meters of straight line distance.
+	GTFSMaxWalkDistance = 1000
+	// GTFSMaxTransfers is the most transfers between transit lines a journey can have.
+	GTFSMaxTransfers = 4
+
+	// gtfsArriveByWindow is how long before the arrival time departures are searched
+	// for, to arrive by a time.
+	gtfsArriveByWindow = 4 * time.Hour
+	// gtfsNextDayWindow is how long after the departure time trips of the next day
+	// are searched, so that a journey late in the evening can continue past midnight
+	// without searching a whole day ahead when there's no service.
+	gtfsNextDayWindow = 4 * time.Hour
+	secondsPerDay     = 24 * 60 * 60
+	unreached         = math.MaxInt32
+)
+
+const (
+	raptorAccess = iota
+	raptorTransit
+	raptorWalk
+)
+
+var (
+	// ErrNoTransitRoute is returned when no transit journey is found within walking
+	// distance of the locations.
+	ErrNoTransitRoute = errors.New("no transit route found between the provided locations")
+
+	// now returns the current time, and is replaced in tests.
+	now = time.Now
+)
+
+// GTFS estimates transit durations without a network connection, with a RAPTOR
+// search of the scheduled trips of a GTFS feed, walking to, from and between stops.
+//
+// Locations must be provided as "lat,lng" coordinates.
+type GTFS struct {
+	timetable *timetable
+	zone      *time.Location
+}
+
+// NewGTFS initializes and returns a GTFS estimating transit durations from the zipped
+// GTFS feed at the path provided.
+//
+// Importing a feed is slow, so its timetable is saved alongside it with the ".timetable"
+// extension and reused until the feed changes.
+func NewGTFS(path string) (*GTFS, error) {
+	t, err := loadTimetable(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGTFS(t)
+}
+
+// newGTFS initializes and returns a GTFS searching the timetable provided.
+func newGTFS(t *timetable) (*GTFS, error) {
+	zone, err := time.LoadLocation(t.Zone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GTFS{timetable: t, zone: zone}, nil
+}
+
+// Duration returns the estimated time it will take to travel by transit between the
+// From and To coordinates, departing or arriving at the time specified by the Options,
+// or departing now. The Estimate includes the number of transfers and the walking and
+// transit Steps of the journey.
+func (g *GTFS) Duration(from, to string, tm TravelMode, opts Options) (*Estimate, error) {
+	if tm != Transit {
+		return nil, ErrUnsupported
+	}
+
+	f, err := parsePosition(from)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parsePosition(to)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.ArriveBy.IsZero() {
+		return g.arriveBy(f, t, opts)
+	}
+
+	depart := opts.DepartAt
+	if depart.IsZero() {
+		depart = now()
+	}
+
+	j, ok := g.search(f, t, depart, opts.TransitModes)
+	if !ok {
+		return nil, ErrNoTransitRoute
+	}
+	return j.estimate(depart), nil
+}
+
+// arriveBy returns the Estimate of the journey departing as late as possible to
+// arrive by the time requested, searching departures to the minute. A later departure
+// never arrives earlier, so the latest departure is found with a binary search.
+func (g *GTFS) arriveBy(from, to Position, opts Options) (*Estimate, error) {
+	latest := opts.ArriveBy.Truncate(time.Minute)
+	earliest := latest.Add(-gtfsArriveByWindow)
+
+	arrives := func(depart time.Time) (*raptorJourney, bool) {
+		j, ok := g.search(from, to, depart, opts.TransitModes)
+		return j, ok && !j.arrival.After(opts.ArriveBy)
+	}
+
+	best, ok := arrives(earliest)
+	if !ok {
+		return nil, ErrNoTransitRoute
+	}
+	depart := earliest
+
+	lo, hi := 1, int(gtfsArriveByWindow/time.Minute)
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		d := earliest.Add(time.Duration(mid) * time.Minute)
+		if j, ok := arrives(d); ok {
+			best, depart = j, d
+			lo = mid + 1
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	return best.estimate(depart), nil
+}
+
+// CurrentLocation is not supported offline.
+func (g *GTFS) CurrentLocation() (*Position, error) {
+	return nil, ErrUnsupported
+}
+
+// Capabilities returns the Capabilities of GTFS, which only supports estimating transit
+// durations between coordinates.
+func (g *GTFS) Capabilities() Capabilities {
+	return Capabilities{Transit: true}
+}
+
+// raptorLabel describes how a stop was reached in a round of the search.
+type raptorLabel struct {
+	kind int
+	// from is the stop walked from, or boarded at.
+	from int32
+	// pattern, trip and the positions of the stops boarded at and alighted from are
+	// set when the stop was reached by transit.
+	pattern       int32
+	trip          *raptorTrip
+	board, alight int
+	// walkMeters, departed and arrive describe the walk when the stop was walked to.
+	walkMeters       float64
+	departed, arrive int32
+}
+
+// raptorTrip is a trip running on a date, with its times in seconds after midnight
+// of the date searched.
+type raptorTrip struct {
+	trip   *gtfsTrip
+	offset int32
+}
+
+// arrival returns the time the trip arrives at the stop at a position of its pattern.
+func (t *raptorTrip) arrival(i int) int32 {
+	return t.trip.Arrivals[i] + t.offset
+}
+
+// departure returns the time the trip departs the stop at a position of its pattern.
+func (t *raptorTrip) departure(i int) int32 {
+	return t.trip.Departures[i] + t.offset
+}
+
+// raptorJourney is the fastest journey found by a search.
+type raptorJourney struct {
+	arrival time.Time
+	steps   []Step
+	legs    int
+}
+
+// estimate returns the Estimate of the journey, departing at the time provided.
+func (j *raptorJourney) estimate(depart time.Time) *Estimate {
+	e := Estimate{
+		Duration: j.arrival.Sub(depart),
+		Steps:    j.steps,
+	}
+	if j.legs > 1 {
+		e.Transfers = j.legs - 1
+	}
+	for _, s := range j.steps {
+		e.Distance += s.Distance
+	}
+
+	return &e
+}
+
+// search returns the journey that arrives earliest at the destination, departing the
+// origin at the time provided, using the RAPTOR algorithm.
+//
+// Each round of the search finds the earliest arrival at each stop with one more
+// transit leg than the last, by scanning the patterns serving the stops improved in the
+// previous round, and then walking to nearby stops. Trips of the day before are included,
+// as they may still be running after midnight, and so are those of the day after departing
+// shortly after the start, for journeys that continue past midnight.
+func (g *GTFS) search(from, to Position, depart time.Time, modes []TransitMode) (*raptorJourney, bool) {
+	tt := g.timetable
+	local := depart.In(g.zone)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, g.zone)
+	start := int32(local.Sub(day) / time.Second)
+	walkSpeed := DefaultSpeeds[Walk] * metersPerSecond
+
+	walkSeconds := func(meters float32) int32 {
+		return int32(math.Floor(float64(meters)/walkSpeed + 0.5))
+	}
+	walk := func(a, b Position) (float64, int32) {
+		meters := Haversine(a.Lat, a.Lng, b.Lat, b.Lng) * DefaultDetour
+		return meters, walkSeconds(float32(meters))
+	}
+
+	rounds := GTFSMaxTransfers + 2
+	arrivals := make([][]int32, rounds)
+	labels := make([][]raptorLabel, rounds)
+	for k := range arrivals {
+		arrivals[k] = make([]int32, len(tt.Stops))
+		labels[k] = make([]raptorLabel, len(tt.Stops))
+		for s := range arrivals[k] {
+			arrivals[k][s] = unreached
+		}
+	}
+	best := make([]int32, len(tt.Stops))
+	var egress []gtfsTransfer
+	var marked []int32
+
+	for s, stop := range tt.Stops {
+		p := Position{Lat: stop.Lat, Lng: stop.Lng}
+		if Haversine(from.Lat, from.Lng, p.Lat, p.Lng) <= GTFSMaxWalkDistance {
+			meters, seconds := walk(from, p)
+			arrivals[0][s] = start + seconds
+			labels[0][s] = raptorLabel{kind: raptorAccess, walkMeters: meters, departed: start, arrive: start + seconds}
+			marked = append(marked, int32(s))
+		}
+		if Haversine(to.Lat, to.Lng, p.Lat, p.Lng) <= GTFSMaxWalkDistance {
+			meters, _ := walk(p, to)
+			egress = append(egress, gtfsTransfer{Stop: int32(s), Meters: float32(meters)})
+		}
+	}
+	copy(best, arrivals[0])
+
+	trips := g.running(day, start, modes)
+	for k := 1; k < rounds && len(marked) > 0; k++ {
+		// Find the earliest position of an improved stop along each pattern.
+		queue := make(map[int32]int)
+		for _, s := range marked {
+			for _, p := range tt.StopPatterns[s] {
+				for i, ps := range tt.Patterns[p].Stops {
+					if ps != s {
+						continue
+					}
+					if pos, ok := queue[p]; !ok || i < pos {
+						queue[p] = i
+					}
+					break
+				}
+			}
+		}
+		patterns := make([]int, 0, len(queue))
+		for p := range queue {
+			patterns = append(patterns, int(p))
+		}
+		sort.Ints(patterns)
+
+		var improved []int32
+		for _, pi := range patterns {
+			p := int32(pi)
+			stops := tt.Patterns[p].Stops
+
+			var trip *raptorTrip
+			board := 0
+			for i := queue[p]; i < len(stops); i++ {
+				s := stops[i]
+				if trip != nil {
+					if arr := trip.arrival(i); arr < best[s] {
+						if arrivals[k][s] == unreached {
+							improved = append(improved, s)
+						}
+						arrivals[k][s], best[s] = arr, arr
+						labels[k][s] = raptorLabel{kind: raptorTransit, from: stops[board], pattern: p, trip: trip, board: board, alight: i}
+					}
+				}
+
+				// Board an earlier trip when the stop was reached before it departs.
+				if prev := arrivals[k-1][s]; prev != unreached && (trip == nil || prev <= trip.departure(i)) {
+					if t := earliestTrip(trips[p], i, prev); t != nil && (trip == nil || t.departure(i) < trip.departure(i)) {
+						trip, board = t, i
+					}
+				}
+			}
+		}
+
+		// Walk from the stops reached by transit, but not those reached by walking.
+		marked = improved
+		for _, s := range improved {
+			for _, tr := range tt.Transfers[s] {
+				if arr := arrivals[k][s] + walkSeconds(tr.Meters); arr < best[tr.Stop] {
+					if arrivals[k][tr.Stop] == unreached {
+						marked = append(marked, tr.Stop)
+					}
+					arrivals[k][tr.Stop], best[tr.Stop] = arr, arr
+					labels[k][tr.Stop] = raptorLabel{kind: raptorWalk, from: s, walkMeters: float64(tr.Meters), departed: arrivals[k][s], arrive: arr}
+				}
+			}
+		}
+	}
+
+	// Find the earliest arrival at the destination, preferring fewer transit legs,
+	// and walking all the way when it's no slower.
+	bestRound, bestStop, bestArrival := -1, int32(0), int32(unreached)
+	if Haversine(from.Lat, from.Lng, to.Lat, to.Lng) <= GTFSMaxWalkDistance {
+		_, seconds := walk(from, to)
+		bestRound, bestArrival = 0, start+seconds
+	}
+	for k := 1; k < rounds; k++ {
+		for _, e := range egress {
+			if arr := arrivals[k][e.Stop]; arr != unreached && arr+walkSeconds(e.Meters) < bestArrival {
+				bestRound, bestStop, bestArrival = k, e.Stop, arr+walkSeconds(e.Meters)
+			}
+		}
+	}
+	at := func(seconds int32) time.Time {
+		return day.Add(time.Duration(seconds) * time.Second)
+	}
+
+	switch bestRound {
+	case -1:
+		return nil, false
+	case 0:
+		meters, seconds := walk(from, to)
+		return &raptorJourney{
+			arrival: at(bestArrival),
+			steps: []Step{{
+				Instructions: "Walk to destination",
+				Distance:     int(math.Floor(meters + 0.5)),
+				Duration:     time.Duration(seconds) * time.Second,
+			}},
+		}, true
+	}
+
+	j := raptorJourney{arrival: at(bestArrival)}
+
+	stopPosition := func(s int32) Position {
+		return Position{Lat: tt.Stops[s].Lat, Lng: tt.Stops[s].Lng}
+	}
+	egressMeters, egressSeconds := walk(stopPosition(bestStop), to)
+	steps := []Step{{
+		Instructions: "Walk to destination",
+		Distance:     int(math.Floor(egressMeters + 0.5)),
+		Duration:     time.Duration(egressSeconds) * time.Second,
+	}}
+
+	for k, s := bestRound, bestStop; ; {
+		l := labels[k][s]
+		switch l.kind {
+		case raptorAccess:
+			steps = append(steps, g.walkStep(s, l))
+			reverseSteps(steps)
+			j.steps = steps
+			return &j, true
+		case raptorWalk:
+			steps = append(steps, g.walkStep(s, l))
+			s = l.from
+		case raptorTransit:
+			steps = append(steps, g.transitStep(l, at))
+			j.legs++
+			s = l.from
+			k--
+		}
+	}
+}
+
+// running returns the trips of each pattern that run on the day provided, the day
+// before, or the day after within gtfsNextDayWindow of the start, with their times
+// relative to the day. Patterns with none of the TransitModes requested are excluded.
+func (g *GTFS) running(day time.Time, start int32, modes []TransitMode) [][]*raptorTrip {
+	tt := g.timetable
+	days := []struct {
+		date   time.Time
+		offset int32
+	}{
+		{day.AddDate(0, 0, -1), -secondsPerDay},
+		{day, 0},
+		{day.AddDate(0, 0, 1), secondsPerDay},
+	}
+
+	trips := make([][]*raptorTrip, len(tt.Patterns))
+	for p, pattern := range tt.Patterns {
+		if len(modes) > 0 && !g.serves(tt.Routes[pattern.Route], modes) {
+			continue
+		}
+
+		for _, d := range days {
+			for i := range pattern.Trips {
+				t := &pattern.Trips[i]
+				if s, ok := tt.Services[t.Service]; !ok || !s.runs(d.date) {
+					continue
+				}
+				if last := len(t.Arrivals) - 1; t.Arrivals[last]+d.offset < 0 {
+					continue
+				} else if d.offset > 0 && t.Departures[0]+d.offset > start+int32(gtfsNextDayWindow/time.Second) {
+					continue
+				}
+
+				trips[p] = append(trips[p], &raptorTrip{trip: t, offset: d.offset})
+			}
+		}
+	}
+
+	return trips
+}
+
+// serves returns true if the route is travelled by one of the TransitModes.
+func (g *GTFS) serves(r gtfsRoute, modes []TransitMode) bool {
+	m, ok := gtfsTransitModes[r.Vehicle]
+	if !ok {
+		return false
+	}
+
+	for _, mode := range modes {
+		if mode == m || (mode == TransitRail && m != TransitBus) {
+			return true
+		}
+	}
+	return false
+}
+
+// earliestTrip returns the first trip to depart the stop at a position of the pattern
+// at or after the time provided, or nil if there isn't one.
+func earliestTrip(trips []*raptorTrip, i int, after int32) *raptorTrip {
+	var earliest *raptorTrip
+	for _, t := range trips {
+		if d := t.departure(i); d >= after && (earliest == nil || d < earliest.departure(i)) {
+			earliest = t
+		}
+	}
+
+	return earliest
+}
+
+// walkStep returns the Step of a walk to a stop.
+func (g *GTFS) walkStep(s int32, l raptorLabel) Step {
+	return Step{
+		Instructions: fmt.Sprintf("Walk to %v", g.timetable.Stops[s].Name),
+		Distance:     int(math.Floor(l.walkMeters + 0.5)),
+		Duration:     time.Duration(l.arrive-l.departed) * time.Second,
+	}
+}
+
+// transitStep returns the Step of a transit leg.
+func (g *GTFS) transitStep(l raptorLabel, at func(int32) time.Time) Step {
+	tt := g.timetable
+	pattern := tt.Patterns[l.pattern]
+	route := tt.Routes[pattern.Route]
+
+	var meters float64
+	for i := l.board; i < l.alight; i++ {
+		a, b := tt.Stops[pattern.Stops[i]], tt.Stops[pattern.Stops[i+1]]
+		meters += Haversine(a.Lat, a.Lng, b.Lat, b.Lng)
+	}
+
+	departure, arrival := l.trip.departure(l.board), l.trip.arrival(l.alight)
+	instructions := fmt.Sprintf("%v %v", route.Vehicle, route.Line)
+	if len(l.trip.trip.Headsign) > 0 {
+		instructions = fmt.Sprintf("%v towards %v", route.Vehicle, l.trip.trip.Headsign)
+	}
+
+	return Step{
+		Instructions: instructions,
+		Distance:     int(math.Floor(meters + 0.5)),
+		Duration:     time.Duration(arrival-departure) * time.Second,
+		Transit: &TransitDetails{
+			Line:          route.Line,
+			Vehicle:       route.Vehicle,
+			Headsign:      l.trip.trip.Headsign,
+			DepartureStop: tt.Stops[pattern.Stops[l.board]].Name,
+			DepartureTime: at(departure),
+			ArrivalStop:   tt.Stops[pattern.Stops[l.alight]].Name,
+			ArrivalTime:   at(arrival),
+			NumStops:      l.alight - l.board,
+		},
+	}
+}
+
+// reverseSteps reverses the order of the Steps in place.
+func reverseSteps(steps []Step) {
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+}
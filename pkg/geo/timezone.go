@@ -0,0 +1,30 @@
+package geo
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+// Timezone returns the time zone at a Latitude and Longitude, as of the time provided.
+//
+// The named time zone is used when it's available on the system, otherwise a fixed
+// zone with the offset in effect at the time provided is returned.
+func (r Router) Timezone(lat, lng float64, at time.Time) (*time.Location, error) {
+	res, err := r.client.Timezone(context.Background(), &maps.TimezoneRequest{
+		Location:  &maps.LatLng{Lat: lat, Lng: lng},
+		Timestamp: at,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(res.TimeZoneID) == 0 {
+		return nil, ErrUnavailable
+	}
+
+	if loc, err := time.LoadLocation(res.TimeZoneID); err == nil {
+		return loc, nil
+	}
+
+	return time.FixedZone(res.TimeZoneID, res.RawOffset+res.DstOffset), nil
+}
@@ -0,0 +1,74 @@
+package geo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"googlemaps.github.io/maps"
+)
+
+func TestRouter_Timezone(t *testing.T) {
+	var mc MockCommunicator
+	r := Router{
+		client: &mc,
+	}
+	at := time.Date(2017, time.May, 1, 8, 15, 0, 0, time.UTC)
+
+	// Positive Case
+	tests := []struct {
+		res          maps.TimezoneResult
+		expectOffset int
+	}{
+		{maps.TimezoneResult{TimeZoneID: "UTC"}, 0},
+		// Unknown zones fall back to a fixed offset.
+		{maps.TimezoneResult{TimeZoneID: "Commuter/Unknown", RawOffset: -28800, DstOffset: 3600}, -25200},
+	}
+
+	for idx, tt := range tests {
+		mc.timezoneFn = func(c context.Context, r *maps.TimezoneRequest) (*maps.TimezoneResult, error) {
+			if r.Location == nil || r.Location.Lat != 43.6 || r.Location.Lng != -79.3 {
+				t.Fatalf("[#%v] Unexpected Location, expected=43.6,-79.3, got=%v", idx, r.Location)
+			} else if !r.Timestamp.Equal(at) {
+				t.Fatalf("[#%v] Unexpected Timestamp, expected=%v, got=%v", idx, at, r.Timestamp)
+			}
+
+			return &tt.res, nil
+		}
+
+		loc, err := r.Timezone(43.6, -79.3, at)
+		if err != nil {
+			t.Fatalf("[#%v] Unexpected error: %v", idx, err)
+		}
+
+		if loc.String() != tt.res.TimeZoneID {
+			t.Fatalf("[#%v] Unexpected Location, expected=%v, got=%v", idx, tt.res.TimeZoneID, loc)
+		} else if _, offset := at.In(loc).Zone(); offset != tt.expectOffset {
+			t.Fatalf("[#%v] Unexpected offset, expected=%v, got=%v", idx, tt.expectOffset, offset)
+		}
+	}
+
+	// Missing zone
+	{
+		mc.timezoneFn = func(c context.Context, r *maps.TimezoneRequest) (*maps.TimezoneResult, error) {
+			return &maps.TimezoneResult{}, nil
+		}
+
+		if _, err := r.Timezone(0, 0, at); err != ErrUnavailable {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", ErrUnavailable, err)
+		}
+	}
+
+	// Error from Communicator
+	{
+		e := errors.New("test err")
+		mc.timezoneFn = func(c context.Context, r *maps.TimezoneRequest) (*maps.TimezoneResult, error) {
+			return nil, e
+		}
+
+		if _, err := r.Timezone(0, 0, at); err != e {
+			t.Fatalf("Unexpected error returned, expected=%v, got=%v", e, err)
+		}
+	}
+}
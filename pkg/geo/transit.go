@@ -0,0 +1,118 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+
+	"googlemaps.github.io/maps"
+)
+
+const (
+	// TransitModeAny is used to explicitly allow any mode of transit.
+	TransitModeAny = "any"
+	// TransitPreferenceNone is used to explicitly provide no transit routing preference.
+	TransitPreferenceNone = "none"
+)
+
+var (
+	// ErrInvalidTransitMode is returned when a list of transit modes cannot be parsed.
+	ErrInvalidTransitMode = errors.New("invalid transit mode, expected a comma separated list of 'bus', 'subway', 'train', 'tram' and 'rail', or 'any'")
+	// ErrInvalidTransitPreference is returned when a transit routing preference cannot be parsed.
+	ErrInvalidTransitPreference = errors.New("invalid transit preference, expected 'less_walking', 'fewer_transfers' or 'none'")
+)
+
+// TransitMode is a preferred mode of public transit.
+type TransitMode maps.TransitMode
+
+var (
+	// TransitBus prefers travel by bus.
+	TransitBus = TransitMode(maps.TransitModeBus)
+	// TransitSubway prefers travel by subway.
+	TransitSubway = TransitMode(maps.TransitModeSubway)
+	// TransitTrain prefers travel by train.
+	TransitTrain = TransitMode(maps.TransitModeTrain)
+	// TransitTram prefers travel by tram and light rail.
+	TransitTram = TransitMode(maps.TransitModeTram)
+	// TransitRail prefers travel by train, tram, light rail and subway.
+	TransitRail = TransitMode(maps.TransitModeRail)
+
+	transitModeValues = map[string]TransitMode{
+		string(TransitBus):    TransitBus,
+		string(TransitSubway): TransitSubway,
+		string(TransitTrain):  TransitTrain,
+		string(TransitTram):   TransitTram,
+		string(TransitRail):   TransitRail,
+	}
+)
+
+// TransitPreference biases which transit routes are preferred.
+type TransitPreference maps.TransitRoutingPreference
+
+var (
+	// LessWalking prefers transit routes with a limited amount of walking.
+	LessWalking = TransitPreference(maps.TransitRoutingPreferenceLessWalking)
+	// FewerTransfers prefers transit routes with a limited number of transfers.
+	FewerTransfers = TransitPreference(maps.TransitRoutingPreferenceFewerTransfers)
+)
+
+// ParseTransitModes parses a comma separated list of transit modes, such as
+// "subway,train". The value "any" returns an empty list.
+func ParseTransitModes(value string) ([]TransitMode, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) == 1 && strings.ToLower(strings.TrimSpace(parts[0])) == TransitModeAny {
+		return nil, nil
+	}
+
+	var modes []TransitMode
+	for _, v := range parts {
+		m, ok := transitModeValues[strings.ToLower(strings.TrimSpace(v))]
+		if !ok {
+			return nil, ErrInvalidTransitMode
+		}
+
+		if !containsTransitMode(modes, m) {
+			modes = append(modes, m)
+		}
+	}
+
+	return modes, nil
+}
+
+// FormatTransitModes returns the comma separated representation of a list of transit
+// modes, the inverse of ParseTransitModes.
+func FormatTransitModes(modes []TransitMode) string {
+	if len(modes) == 0 {
+		return TransitModeAny
+	}
+
+	s := make([]string, len(modes))
+	for i, m := range modes {
+		s[i] = string(m)
+	}
+
+	return strings.Join(s, ",")
+}
+
+// ParseTransitPreference parses a transit routing preference, either "less_walking"
+// or "fewer_transfers". The value "none" returns an empty preference.
+func ParseTransitPreference(value string) (TransitPreference, error) {
+	switch p := TransitPreference(strings.ToLower(strings.TrimSpace(value))); p {
+	case LessWalking, FewerTransfers:
+		return p, nil
+	case TransitPreferenceNone:
+		return "", nil
+	}
+
+	return "", ErrInvalidTransitPreference
+}
+
+// containsTransitMode returns true if the list of modes contains the TransitMode provided.
+func containsTransitMode(modes []TransitMode, m TransitMode) bool {
+	for _, v := range modes {
+		if v == m {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package geo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTransitModes(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect []TransitMode
+		err    error
+	}{
+		{"bus", []TransitMode{TransitBus}, nil},
+		{"Subway,TRAIN", []TransitMode{TransitSubway, TransitTrain}, nil},
+		{"bus, subway, train, tram, rail", []TransitMode{TransitBus, TransitSubway, TransitTrain, TransitTram, TransitRail}, nil},
+		{"rail,rail", []TransitMode{TransitRail}, nil},
+		{"any", nil, nil},
+		{" Any ", nil, nil},
+
+		{"", nil, ErrInvalidTransitMode},
+		{"ferry", nil, ErrInvalidTransitMode},
+		{"bus,", nil, ErrInvalidTransitMode},
+		{"any,bus", nil, ErrInvalidTransitMode},
+	}
+
+	for idx, tt := range tests {
+		modes, err := ParseTransitModes(tt.value)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if !reflect.DeepEqual(modes, tt.expect) {
+			t.Fatalf("[#%v] Unexpected TransitModes, expected=%v, got=%v", idx, tt.expect, modes)
+		}
+	}
+}
+
+func TestFormatTransitModes(t *testing.T) {
+	tests := []struct {
+		modes  []TransitMode
+		expect string
+	}{
+		{nil, "any"},
+		{[]TransitMode{TransitBus}, "bus"},
+		{[]TransitMode{TransitSubway, TransitTrain}, "subway,train"},
+	}
+
+	for idx, tt := range tests {
+		if out := FormatTransitModes(tt.modes); out != tt.expect {
+			t.Fatalf("[#%v] Unexpected output, expected=%v, got=%v", idx, tt.expect, out)
+		}
+	}
+}
+
+func TestParseTransitPreference(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect TransitPreference
+		err    error
+	}{
+		{"less_walking", LessWalking, nil},
+		{"Fewer_Transfers", FewerTransfers, nil},
+		{"none", "", nil},
+
+		{"", "", ErrInvalidTransitPreference},
+		{"fastest", "", ErrInvalidTransitPreference},
+	}
+
+	for idx, tt := range tests {
+		p, err := ParseTransitPreference(tt.value)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if p != tt.expect {
+			t.Fatalf("[#%v] Unexpected TransitPreference, expected=%v, got=%v", idx, tt.expect, p)
+		}
+	}
+}
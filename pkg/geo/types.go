@@ -9,4 +9,11 @@ import (
 // Google Maps API.
 type Communicator interface {
 	DistanceMatrix(context.Context, *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error)
+	Directions(context.Context, *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error)
+	Geocode(context.Context, *maps.GeocodingRequest) ([]maps.GeocodingResult, error)
+	ReverseGeocode(context.Context, *maps.GeocodingRequest) ([]maps.GeocodingResult, error)
+	Timezone(context.Context, *maps.TimezoneRequest) (*maps.TimezoneResult, error)
+	Elevation(context.Context, *maps.ElevationRequest) ([]maps.ElevationResult, error)
+	TextSearch(context.Context, *maps.TextSearchRequest) (maps.PlacesSearchResponse, error)
+	NearbySearch(context.Context, *maps.NearbySearchRequest) (maps.PlacesSearchResponse, error)
 }
@@ -0,0 +1,33 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+
+	"googlemaps.github.io/maps"
+)
+
+var (
+	// ErrInvalidUnits is returned when a unit system cannot be parsed.
+	ErrInvalidUnits = errors.New("invalid units, expected 'metric' or 'imperial'")
+)
+
+// Units is the unit system used to express distances.
+type Units maps.Units
+
+var (
+	// Metric expresses distances in kilometers and meters.
+	Metric = Units(maps.UnitsMetric)
+	// Imperial expresses distances in miles.
+	Imperial = Units(maps.UnitsImperial)
+)
+
+// ParseUnits parses a unit system, either "metric" or "imperial".
+func ParseUnits(value string) (Units, error) {
+	switch u := Units(strings.ToLower(strings.TrimSpace(value))); u {
+	case Metric, Imperial:
+		return u, nil
+	}
+
+	return "", ErrInvalidUnits
+}
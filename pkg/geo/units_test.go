@@ -0,0 +1,29 @@
+package geo
+
+import (
+	"testing"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect Units
+		err    error
+	}{
+		{"metric", Metric, nil},
+		{"Imperial", Imperial, nil},
+		{" METRIC ", Metric, nil},
+
+		{"", "", ErrInvalidUnits},
+		{"miles", "", ErrInvalidUnits},
+	}
+
+	for idx, tt := range tests {
+		u, err := ParseUnits(tt.value)
+		if err != tt.err {
+			t.Fatalf("[#%v] Unexpected error, expected=%v, got=%v", idx, tt.err, err)
+		} else if u != tt.expect {
+			t.Fatalf("[#%v] Unexpected Units, expected=%v, got=%v", idx, tt.expect, u)
+		}
+	}
+}